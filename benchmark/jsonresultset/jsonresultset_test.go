@@ -54,6 +54,10 @@ func getDSN() (dsn string, cfg *sf.Config, err error) {
 	s := env("SNOWFLAKE_TEST_CUSTOME_JSON_DECODER_ENABLED", true)
 	sf.CustomJSONDecoderEnabled = strings.EqualFold("true", s)
 
+	// Use the typed JSON Decoder unless disabled
+	s = env("SNOWFLAKE_TEST_TYPED_JSON_DECODER_ENABLED", false)
+	sf.TypedJSONDecoderEnabled = s == "" || strings.EqualFold("true", s)
+
 	// Set the maximum chunk download workers
 	n := env("SNOWFLAKE_TEST_MAX_CHUNK_DOWNLOAD_WORKERS", false)
 	if n != "" {
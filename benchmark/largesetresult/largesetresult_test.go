@@ -17,6 +17,7 @@ import (
 	"runtime/debug"
 
 	"strconv"
+	"strings"
 
 	sf "github.com/snowflakedb/gosnowflake"
 )
@@ -48,6 +49,10 @@ func getDSN() (dsn string, cfg *sf.Config, err error) {
 	port := env("SNOWFLAKE_TEST_PORT", false)
 	protocol := env("SNOWFLAKE_TEST_PROTOCOL", false)
 
+	// Use the typed JSON Decoder unless disabled
+	s := env("SNOWFLAKE_TEST_TYPED_JSON_DECODER_ENABLED", false)
+	sf.TypedJSONDecoderEnabled = s == "" || strings.EqualFold("true", s)
+
 	portStr, _ := strconv.Atoi(port)
 	cfg = &sf.Config{
 		Account:  account,
@@ -0,0 +1,382 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultBulkLoadFileSize is the size of the CSV data of a file BulkLoader uploads by default, before compression.
+	defaultBulkLoadFileSize = 64 << 20
+
+	bulkLoadTimestampFormat = "2006-01-02 15:04:05.000000000 -07:00"
+	bulkLoadDateFormat      = "2006-01-02"
+	bulkLoadTimeFormat      = "15:04:05.000000000"
+
+	// bulkLoadCleanupTimeout is the timeout of dropping the stage, which runs even if the context of Load is done.
+	bulkLoadCleanupTimeout = 30 * time.Second
+
+	// bulkLoadFileFormat is the file format of the files BulkLoader writes. Every value but NULL is enclosed by double
+	// quotes, so that empty fields are NULL and "" is the empty string.
+	bulkLoadFileFormat = "TYPE = CSV COMPRESSION = GZIP FIELD_OPTIONALLY_ENCLOSED_BY = '\"' " +
+		"EMPTY_FIELD_AS_NULL = TRUE BINARY_FORMAT = HEX DATE_FORMAT = 'YYYY-MM-DD' TIME_FORMAT = 'HH24:MI:SS.FF9' " +
+		"TIMESTAMP_FORMAT = 'YYYY-MM-DD HH24:MI:SS.FF9 TZH:TZM'"
+)
+
+// BulkLoadOptions configures BulkLoader.
+type BulkLoadOptions struct {
+	// FileSize is the size of the CSV data of each file before compression. The default is 64 MB.
+	FileSize int
+	// OnError is the ON_ERROR copy option, e.g., CONTINUE or SKIP_FILE. The default is ABORT_STATEMENT, with which a
+	// single bad row fails the load.
+	OnError string
+}
+
+// BulkLoadIterator provides the rows BulkLoader loads. Next returns the values of the next row in the order of
+// Columns, or io.EOF after the last row. The columns are identifiers as written in SQL, e.g., amount for AMOUNT and
+// "Amount" for Amount.
+type BulkLoadIterator interface {
+	Columns() []string
+	Next() ([]interface{}, error)
+}
+
+// BulkLoadReport is the result of a bulk load.
+type BulkLoadReport struct {
+	RowsWritten int64            // rows written to the files
+	RowsLoaded  int64            // rows loaded into the table
+	Files       []CopyFileResult // results of COPY INTO by file
+}
+
+// Err returns a *CopyError if any file is loaded partially or not at all, which happens with ON_ERROR other than
+// ABORT_STATEMENT, or nil.
+func (r *BulkLoadReport) Err() error {
+	return copyFilesError(r.Files)
+}
+
+// BulkLoader loads rows into a table by writing them to gzip compressed CSV files, uploading the files to a
+// temporary stage, and copying them into the table with COPY INTO. It loads large numbers of rows much faster than
+// binding them to INSERT statements. The temporary stage lives in the session, so BulkLoader runs on a single
+// connection.
+type BulkLoader struct {
+	conn  *sql.Conn
+	table string
+	opts  BulkLoadOptions
+}
+
+// NewBulkLoader returns a BulkLoader of the table on the connection. opts may be nil. The table is written into the
+// statements as is, so it is a name as written in SQL, e.g., mydb.public."My Table".
+func NewBulkLoader(conn *sql.Conn, table string, opts *BulkLoadOptions) *BulkLoader {
+	bl := &BulkLoader{conn: conn, table: table}
+	if opts != nil {
+		bl.opts = *opts
+	}
+	if bl.opts.FileSize <= 0 {
+		bl.opts.FileSize = defaultBulkLoadFileSize
+	}
+	return bl
+}
+
+// LoadStructs loads rows, a slice of structs or of pointers to structs, whose fields map to the columns as
+// InsertStructs maps them.
+func (bl *BulkLoader) LoadStructs(ctx context.Context, rows interface{}) (*BulkLoadReport, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("rows must be a slice: %T", rows)
+	}
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("rows must be a slice of structs or of pointers to structs: %T", rows)
+	}
+	names, fields := structInsertColumns(elemType)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%v has no field to load", elemType)
+	}
+	return bl.Load(ctx, &structIterator{rows: v, names: names, fields: fields})
+}
+
+// Load loads the rows of the iterator. The files are uploaded as they are written, and copied into the table once
+// all are uploaded, so either all rows are loaded or none with the default ON_ERROR. time.Time values are written in
+// the format of the type of their column, which DESCRIBE TABLE returns. The report has the results of the files even
+// if COPY INTO fails.
+func (bl *BulkLoader) Load(ctx context.Context, it BulkLoadIterator) (*BulkLoadReport, error) {
+	if bl.table == "" {
+		return nil, fmt.Errorf("table must not be empty")
+	}
+	columns := it.Columns()
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no column to load")
+	}
+	types, err := bl.columnTypes(ctx, columns)
+	if err != nil {
+		return nil, err
+	}
+	stage := "SNOWFLAKE_BULK_LOAD_" + strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", ""))
+	if _, err = bl.conn.ExecContext(ctx, "CREATE TEMPORARY STAGE "+stage); err != nil {
+		return nil, err
+	}
+	defer func() {
+		// the stage is dropped even if ctx is canceled, so the session doesn't keep the files until it ends
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), bulkLoadCleanupTimeout)
+		defer cancel()
+		if _, err := bl.conn.ExecContext(cleanupCtx, "DROP STAGE IF EXISTS "+stage); err != nil {
+			logger.WithContext(ctx).Warningf("failed to drop the stage %v. err: %v", stage, err)
+		}
+	}()
+
+	report := &BulkLoadReport{}
+	file := newBulkLoadFile(types)
+	var numFiles int
+	for {
+		values, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+		if len(values) != len(columns) {
+			return report, fmt.Errorf("row %v has %v values for %v columns", report.RowsWritten, len(values),
+				len(columns))
+		}
+		if err = file.writeRow(values); err != nil {
+			return report, fmt.Errorf("row %v: %v", report.RowsWritten, err)
+		}
+		report.RowsWritten++
+		if file.size >= bl.opts.FileSize {
+			numFiles++
+			if err = bl.upload(ctx, stage, numFiles, file); err != nil {
+				return report, err
+			}
+			file = newBulkLoadFile(types)
+		}
+	}
+	if file.rows > 0 {
+		numFiles++
+		if err := bl.upload(ctx, stage, numFiles, file); err != nil {
+			return report, err
+		}
+	}
+	if numFiles == 0 {
+		return report, nil
+	}
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteColumnName(column)
+	}
+	query := fmt.Sprintf("COPY INTO %v (%v) FROM @%v FILE_FORMAT = (%v)",
+		bl.table, strings.Join(quoted, ", "), stage, bulkLoadFileFormat)
+	if bl.opts.OnError != "" {
+		query += " ON_ERROR = " + bl.opts.OnError
+	}
+	rows, err := bl.conn.QueryContext(ctx, query)
+	if err != nil {
+		return report, err
+	}
+	result, err := scanCopyResult(rows)
+	if err != nil {
+		return report, err
+	}
+	report.Files = result.Files
+	report.RowsLoaded = result.RowsLoaded
+	return report, nil
+}
+
+// columnTypes returns the types of the columns of DESCRIBE TABLE, e.g., DATE or TIMESTAMP_NTZ(9), or empty for the
+// columns the table doesn't have, which COPY INTO reports.
+func (bl *BulkLoader) columnTypes(ctx context.Context, columns []string) ([]string, error) {
+	byName := make(map[string]string)
+	err := queryShow(ctx, bl.conn, "DESCRIBE TABLE "+bl.table, func(r *showRow) {
+		byName[r.text("name")] = r.text("type")
+	})
+	if err != nil {
+		return nil, err
+	}
+	types := make([]string, len(columns))
+	for i, column := range columns {
+		types[i] = byName[NormalizeIdentifier(column)]
+	}
+	return types, nil
+}
+
+// upload uploads the file as the nth file of the stage.
+func (bl *BulkLoader) upload(ctx context.Context, stage string, n int, file *bulkLoadFile) error {
+	data, err := file.close()
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("data_%05d.csv.gz", n)
+	rows, err := bl.conn.QueryContext(WithFileStream(ctx, bytes.NewReader(data)),
+		fmt.Sprintf("PUT file://%v @%v AUTO_COMPRESS = FALSE SOURCE_COMPRESSION = GZIP", name, stage))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var source, target, srcCompression, dstCompression, status, message string
+		var srcSize, dstSize int64
+		if err = rows.Scan(&source, &target, &srcSize, &dstSize, &srcCompression, &dstCompression, &status,
+			&message); err != nil {
+			return err
+		}
+		if status != fileTransferStatusUploaded {
+			return fmt.Errorf("failed to upload %v: %v %v", name, status, message)
+		}
+	}
+	return rows.Err()
+}
+
+// bulkLoadFile is a gzip compressed CSV file BulkLoader writes.
+type bulkLoadFile struct {
+	buf   bytes.Buffer
+	gw    *gzip.Writer
+	types []string // of the columns
+	line  []byte
+	size  int // bytes of CSV before compression
+	rows  int
+}
+
+func newBulkLoadFile(types []string) *bulkLoadFile {
+	f := &bulkLoadFile{types: types}
+	f.gw = gzip.NewWriter(&f.buf)
+	return f
+}
+
+func (f *bulkLoadFile) writeRow(values []interface{}) error {
+	f.line = f.line[:0]
+	for i, x := range values {
+		if i > 0 {
+			f.line = append(f.line, ',')
+		}
+		text, ok, err := bulkLoadText(x, f.types[i])
+		if err != nil {
+			return err
+		}
+		if ok {
+			f.line = append(f.line, '"')
+			f.line = append(f.line, strings.ReplaceAll(text, `"`, `""`)...)
+			f.line = append(f.line, '"')
+		}
+	}
+	f.line = append(f.line, '\n')
+	if _, err := f.gw.Write(f.line); err != nil {
+		return err
+	}
+	f.size += len(f.line)
+	f.rows++
+	return nil
+}
+
+func (f *bulkLoadFile) close() ([]byte, error) {
+	if err := f.gw.Close(); err != nil {
+		return nil, err
+	}
+	return f.buf.Bytes(), nil
+}
+
+// bulkLoadText returns the text of a value of a column of the type in the bulk load file format, or false if it is
+// NULL.
+func bulkLoadText(x interface{}, columnType string) (string, bool, error) {
+	switch v := x.(type) {
+	case Date:
+		return v.String(), true, nil
+	case TimeOfDay:
+		return v.On(Date{Year: 1, Month: time.January, Day: 1}, time.UTC).Format(bulkLoadTimeFormat), true, nil
+	case *big.Int:
+		if v == nil {
+			return "", false, nil
+		}
+		return v.String(), true, nil
+	case *big.Float:
+		if v == nil {
+			return "", false, nil
+		}
+		return v.Text('f', -1), true, nil
+	}
+	v, err := driver.DefaultParameterConverter.ConvertValue(x)
+	if err != nil {
+		return "", false, err
+	}
+	switch v := v.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		return v, true, nil
+	case []byte:
+		if v == nil {
+			return "", false, nil
+		}
+		return hex.EncodeToString(v), true, nil
+	case int64:
+		return strconv.FormatInt(v, 10), true, nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true, nil
+	case bool:
+		return strconv.FormatBool(v), true, nil
+	case time.Time:
+		switch {
+		case columnType == "DATE":
+			return v.Format(bulkLoadDateFormat), true, nil
+		case columnType == "TIME" || strings.HasPrefix(columnType, "TIME("):
+			return v.Format(bulkLoadTimeFormat), true, nil
+		case !strings.HasPrefix(columnType, "TIMESTAMP_TZ"):
+			// TIMESTAMP_NTZ and TIMESTAMP_LTZ take the time in UTC as the bindings of InsertStructs do. NTZ would
+			// store the wall clock of the location otherwise.
+			v = v.UTC()
+		}
+		return v.Format(bulkLoadTimestampFormat), true, nil
+	}
+	return "", false, fmt.Errorf("unsupported value: %T", x)
+}
+
+// structIterator iterates the rows of a slice of structs or of pointers to structs.
+type structIterator struct {
+	rows   reflect.Value
+	names  []string
+	fields [][]int
+	next   int
+}
+
+func (it *structIterator) Columns() []string {
+	return it.names
+}
+
+func (it *structIterator) Next() ([]interface{}, error) {
+	if it.next >= it.rows.Len() {
+		return nil, io.EOF
+	}
+	row := it.rows.Index(it.next)
+	if row.Kind() == reflect.Ptr {
+		if row.IsNil() {
+			return nil, fmt.Errorf("row %v is nil", it.next)
+		}
+		row = row.Elem()
+	}
+	it.next++
+	values := make([]interface{}, len(it.fields))
+	for i, index := range it.fields {
+		value, err := structInsertValue(row, index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load column %v: %v", it.names[i], err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
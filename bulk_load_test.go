@@ -0,0 +1,179 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// bulkLoadTestServer responds to the statements of BulkLoader with a LOCAL_FS stage in dir, copying the files into
+// lines.
+type bulkLoadTestServer struct {
+	dir     string
+	mu      sync.Mutex
+	queries []string
+	lines   []string
+}
+
+// respond answers a query of a fakeConnector.
+func (s *bulkLoadTestServer) respond(query string, _ map[string]execBindParameter) (*execResponseData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries = append(s.queries, query)
+	str := func(s string) *string {
+		return &s
+	}
+	switch {
+	case strings.HasPrefix(query, "PUT "):
+		return &execResponseData{
+			Command:           fileTransferCommandUpload,
+			SrcLocations:      []string{strings.Fields(query)[1]},
+			SourceCompression: "gzip",
+			StageInfo:         execResponseStageInfo{LocationType: "LOCAL_FS", Location: s.dir},
+		}, nil
+	case strings.HasPrefix(query, "DESCRIBE TABLE "):
+		return newFakeTextResult([]string{"name", "type"}, [][]*string{
+			{str("ID"), str("NUMBER(38,0)")}, {str("NAME"), str("VARCHAR(16777216)")},
+			{str("AMOUNT"), str("NUMBER(10,2)")}, {str("DAY"), str("DATE")}, {str("DATA"), str("BINARY(8388608)")},
+			{str("SHIPPED"), str("DATE")}, {str("UPDATED"), str("TIMESTAMP_TZ(9)")},
+		}), nil
+	case strings.HasPrefix(query, "COPY INTO "):
+		files, _ := filepath.Glob(filepath.Join(s.dir, "*.csv.gz"))
+		sort.Strings(files)
+		var rowSet [][]*string
+		for _, file := range files {
+			b, _ := ioutil.ReadFile(file)
+			r, err := gzip.NewReader(bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			content, _ := ioutil.ReadAll(r)
+			lines := strings.SplitAfter(string(content), "\n")
+			lines = lines[:len(lines)-1]
+			s.lines = append(s.lines, lines...)
+			n := strconv.Itoa(len(lines))
+			rowSet = append(rowSet, []*string{str(filepath.Base(file)), str("LOADED"), str(n), str(n), str("1"),
+				str("0"), str(""), str(""), str(""), str("")})
+		}
+		return newFakeTextResult([]string{"file", "status", "rows_parsed", "rows_loaded", "error_limit",
+			"errors_seen", "first_error", "first_error_line", "first_error_character", "first_error_column_name"},
+			rowSet), nil
+	}
+	return &execResponseData{}, nil
+}
+
+type bulkLoadTestRow struct {
+	ID      int64   `snowflake:"ID"`
+	Name    *string `snowflake:"NAME"`
+	Amount  *big.Float
+	Day     *Date
+	Data    []byte
+	Shipped *time.Time
+	Updated *time.Time `snowflake:"updated"`
+}
+
+func TestBulkLoadStructs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bulk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	server := &bulkLoadTestServer{dir: dir}
+	db := sql.OpenDB(&fakeConnector{handler: server.respond})
+	defer db.Close()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	name := `say "hi", bye`
+	empty := ""
+	shipped := time.Date(2020, time.March, 2, 23, 0, 0, 0, time.FixedZone("", -7*3600))
+	rows := []bulkLoadTestRow{
+		{ID: 1, Name: &name, Amount: big.NewFloat(1.5), Day: &Date{2020, time.March, 1}, Data: []byte{0xca, 0xfe}},
+		{ID: 2, Name: &empty, Shipped: &shipped, Updated: &shipped},
+		{ID: 3},
+	}
+	report, err := NewBulkLoader(conn, "orders", &BulkLoadOptions{FileSize: 100, OnError: "CONTINUE"}).
+		LoadStructs(context.Background(), rows)
+	if err != nil {
+		t.Fatalf("failed to load. err: %v", err)
+	}
+	if report.RowsWritten != 3 || report.RowsLoaded != 3 || len(report.Files) != 2 ||
+		report.Files[0].File != "data_00001.csv.gz" || report.Files[0].RowsLoaded != 2 ||
+		report.Files[1].RowsLoaded != 1 || report.Files[1].Status != "LOADED" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	expected := []string{
+		`"1","say ""hi"", bye","1.5","2020-03-01","cafe",,` + "\n",
+		`"2","",,,,"2020-03-02","2020-03-02 23:00:00.000000000 -07:00"` + "\n",
+		`"3",,,,,,` + "\n",
+	}
+	if strings.Join(server.lines, "") != strings.Join(expected, "") {
+		t.Fatalf("unexpected lines: %q", server.lines)
+	}
+	queries := server.queries
+	if len(queries) != 6 || queries[0] != "DESCRIBE TABLE orders" ||
+		!strings.HasPrefix(queries[1], "CREATE TEMPORARY STAGE SNOWFLAKE_BULK_LOAD_") ||
+		!strings.HasPrefix(queries[4], `COPY INTO orders ("ID", "NAME", "AMOUNT", "DAY", "DATA", "SHIPPED", "UPDATED") `+
+			"FROM @SNOWFLAKE_BULK_LOAD_") ||
+		!strings.HasSuffix(queries[4], " ON_ERROR = CONTINUE") ||
+		!strings.HasPrefix(queries[5], "DROP STAGE IF EXISTS SNOWFLAKE_BULK_LOAD_") {
+		t.Fatalf("unexpected queries: %q", queries)
+	}
+}
+
+func TestBulkLoadText(t *testing.T) {
+	testcases := []struct {
+		value      interface{}
+		columnType string
+		text       string
+		ok         bool
+	}{
+		{nil, "", "", false},
+		{(*big.Int)(nil), "", "", false},
+		{"a", "", "a", true},
+		{int32(-5), "", "-5", true},
+		{0.25, "", "0.25", true},
+		{true, "", "true", true},
+		{[]byte{1, 255}, "", "01ff", true},
+		{[]byte(nil), "", "", false},
+		{big.NewInt(12), "", "12", true},
+		{TimeOfDay{Hour: 1, Minute: 2, Second: 3, Nanosecond: 4}, "", "01:02:03.000000004", true},
+		{time.Date(2020, 1, 2, 3, 4, 5, 6, time.FixedZone("", -7*3600)), "TIMESTAMP_TZ(9)",
+			"2020-01-02 03:04:05.000000006 -07:00", true},
+		{time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC), "", "2020-01-02 03:04:05.000000006 +00:00", true},
+		{time.Date(2020, 1, 2, 3, 4, 5, 6, time.FixedZone("", -7*3600)), "TIMESTAMP_NTZ(9)",
+			"2020-01-02 10:04:05.000000006 +00:00", true},
+		{time.Date(2020, 1, 2, 3, 4, 5, 6, time.FixedZone("", 9*3600)), "TIMESTAMP_LTZ(9)",
+			"2020-01-01 18:04:05.000000006 +00:00", true},
+		{time.Date(2020, 1, 2, 3, 4, 5, 6, time.FixedZone("", 9*3600)), "",
+			"2020-01-01 18:04:05.000000006 +00:00", true},
+		{time.Date(2020, 1, 2, 23, 4, 5, 6, time.FixedZone("", -7*3600)), "DATE", "2020-01-02", true},
+		{time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC), "TIME(9)", "03:04:05.000000006", true},
+		{Date{2020, time.January, 2}, "DATE", "2020-01-02", true},
+	}
+	for _, test := range testcases {
+		text, ok, err := bulkLoadText(test.value, test.columnType)
+		if err != nil || text != test.text || ok != test.ok {
+			t.Errorf("unexpected text of %v: %q, %v, err: %v", test.value, text, ok, err)
+		}
+	}
+	if _, _, err := bulkLoadText(struct{}{}, ""); err == nil {
+		t.Error("should fail with an unsupported value")
+	}
+}
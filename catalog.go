@@ -0,0 +1,396 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ShowOptions filters and paginates the objects of SHOW. Objects are listed in the order of their names.
+type ShowOptions struct {
+	// Like is a case-insensitive pattern of the names with the SQL wildcards % and _.
+	Like string
+	// Limit is the maximum number of objects to list, or 0 for all.
+	Limit int
+	// From is the name of an object, after which the objects are listed. Pass the name of the last object of a page
+	// to get the next page. A page with fewer objects than Limit is the last.
+	From string
+}
+
+// DatabaseInfo is a database of SHOW DATABASES.
+type DatabaseInfo struct {
+	Name          string
+	CreatedOn     time.Time
+	IsDefault     bool
+	IsCurrent     bool
+	Origin        string // the share of a database created from a share
+	Owner         string
+	Comment       string
+	Options       string // TRANSIENT for a transient database
+	RetentionTime int64  // days of Time Travel
+}
+
+// SchemaInfo is a schema of SHOW SCHEMAS.
+type SchemaInfo struct {
+	Name          string
+	DatabaseName  string
+	CreatedOn     time.Time
+	IsDefault     bool
+	IsCurrent     bool
+	Owner         string
+	Comment       string
+	Options       string // TRANSIENT or MANAGED ACCESS
+	RetentionTime int64  // days of Time Travel
+}
+
+// TableInfo is a table of SHOW TABLES.
+type TableInfo struct {
+	Name                string
+	DatabaseName        string
+	SchemaName          string
+	Kind                string // TABLE, TEMPORARY or TRANSIENT
+	CreatedOn           time.Time
+	Owner               string
+	Comment             string
+	ClusterBy           string
+	Rows                int64
+	Bytes               int64
+	RetentionTime       int64 // days of Time Travel
+	AutomaticClustering bool
+	ChangeTracking      bool
+}
+
+// ViewInfo is a view of SHOW VIEWS.
+type ViewInfo struct {
+	Name           string
+	DatabaseName   string
+	SchemaName     string
+	CreatedOn      time.Time
+	Owner          string
+	Comment        string
+	IsSecure       bool
+	IsMaterialized bool
+	Text           string // the CREATE VIEW statement, empty for a secure view of another owner
+}
+
+// ColumnInfo is a column of DESCRIBE TABLE or DESCRIBE VIEW.
+type ColumnInfo struct {
+	Name         string
+	DatabaseName string
+	SchemaName   string
+	TableName    string
+	Type         string // e.g., NUMBER(38,0) or VARCHAR(16777216)
+	Kind         string // COLUMN, or VIRTUAL for a virtual column
+	Nullable     bool
+	Default      string // the default expression, empty for none
+	PrimaryKey   bool   // by DESCRIBE only
+	UniqueKey    bool   // by DESCRIBE only
+	Expression   string // of a virtual column
+	Comment      string
+}
+
+// QuoteIdentifier returns the name enclosed by double quotes, escaping double quotes, so that it refers to the
+// object of the exact name. Names of SHOW and DESCRIBE are exact, e.g., MYTABLE for mytable created unquoted.
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// NormalizeIdentifier returns the exact name of an identifier as written in SQL. An unquoted identifier is stored
+// in uppercase, and a quoted one as is, e.g., mytable is MYTABLE and "MyTable" is MyTable.
+func NormalizeIdentifier(identifier string) string {
+	if len(identifier) >= 2 && strings.HasPrefix(identifier, `"`) && strings.HasSuffix(identifier, `"`) {
+		return strings.ReplaceAll(identifier[1:len(identifier)-1], `""`, `"`)
+	}
+	return strings.ToUpper(identifier)
+}
+
+// quoteColumnName returns the quoted identifier of the column of the name as written in SQL, so that mytable and
+// "MyTable" refer to the same columns whether quoted or not.
+func quoteColumnName(name string) string {
+	return QuoteIdentifier(NormalizeIdentifier(name))
+}
+
+// ListDatabases lists the databases of the account.
+func ListDatabases(ctx context.Context, db SQLQueryer, opts *ShowOptions) ([]DatabaseInfo, error) {
+	var databases []DatabaseInfo
+	err := queryShow(ctx, db, showQuery("DATABASES", "", opts), func(r *showRow) {
+		databases = append(databases, DatabaseInfo{
+			Name:          r.text("name"),
+			CreatedOn:     r.time("created_on"),
+			IsDefault:     r.bool("is_default"),
+			IsCurrent:     r.bool("is_current"),
+			Origin:        r.text("origin"),
+			Owner:         r.text("owner"),
+			Comment:       r.text("comment"),
+			Options:       r.text("options"),
+			RetentionTime: r.int("retention_time"),
+		})
+	})
+	return databases, err
+}
+
+// ListSchemas lists the schemas of the database, or of the account if database is empty. Names are exact.
+func ListSchemas(ctx context.Context, db SQLQueryer, database string, opts *ShowOptions) ([]SchemaInfo, error) {
+	var schemas []SchemaInfo
+	err := queryShow(ctx, db, showQuery("SCHEMAS", showScope(database, ""), opts), func(r *showRow) {
+		schemas = append(schemas, SchemaInfo{
+			Name:          r.text("name"),
+			DatabaseName:  r.text("database_name"),
+			CreatedOn:     r.time("created_on"),
+			IsDefault:     r.bool("is_default"),
+			IsCurrent:     r.bool("is_current"),
+			Owner:         r.text("owner"),
+			Comment:       r.text("comment"),
+			Options:       r.text("options"),
+			RetentionTime: r.int("retention_time"),
+		})
+	})
+	return schemas, err
+}
+
+// ListTables lists the tables of the schema of the database, of the database if schema is empty, or of the account
+// if both are empty. Names are exact.
+func ListTables(ctx context.Context, db SQLQueryer, database string, schema string, opts *ShowOptions) (
+	[]TableInfo, error) {
+	var tables []TableInfo
+	err := queryShow(ctx, db, showQuery("TABLES", showScope(database, schema), opts), func(r *showRow) {
+		tables = append(tables, TableInfo{
+			Name:                r.text("name"),
+			DatabaseName:        r.text("database_name"),
+			SchemaName:          r.text("schema_name"),
+			Kind:                r.text("kind"),
+			CreatedOn:           r.time("created_on"),
+			Owner:               r.text("owner"),
+			Comment:             r.text("comment"),
+			ClusterBy:           r.text("cluster_by"),
+			Rows:                r.int("rows"),
+			Bytes:               r.int("bytes"),
+			RetentionTime:       r.int("retention_time"),
+			AutomaticClustering: r.bool("automatic_clustering"),
+			ChangeTracking:      r.bool("change_tracking"),
+		})
+	})
+	return tables, err
+}
+
+// ListViews lists the views of the schema of the database, of the database if schema is empty, or of the account
+// if both are empty. Names are exact.
+func ListViews(ctx context.Context, db SQLQueryer, database string, schema string, opts *ShowOptions) (
+	[]ViewInfo, error) {
+	var views []ViewInfo
+	err := queryShow(ctx, db, showQuery("VIEWS", showScope(database, schema), opts), func(r *showRow) {
+		views = append(views, ViewInfo{
+			Name:           r.text("name"),
+			DatabaseName:   r.text("database_name"),
+			SchemaName:     r.text("schema_name"),
+			CreatedOn:      r.time("created_on"),
+			Owner:          r.text("owner"),
+			Comment:        r.text("comment"),
+			IsSecure:       r.bool("is_secure"),
+			IsMaterialized: r.bool("is_materialized"),
+			Text:           r.text("text"),
+		})
+	})
+	return views, err
+}
+
+// ListColumns lists the columns of the table or view of the schema of the database in their order. The names are
+// exact, not identifiers as written in SQL: pass ORDERS for the table created as orders, or convert the identifier by
+// NormalizeIdentifier. A query per table is slow for many tables; ListColumnsIn lists the columns of a whole schema.
+func ListColumns(ctx context.Context, db SQLQueryer, database string, schema string, table string) (
+	[]ColumnInfo, error) {
+	query := "DESCRIBE TABLE " + QuoteIdentifier(database) + "." + QuoteIdentifier(schema) + "." +
+		QuoteIdentifier(table)
+	var columns []ColumnInfo
+	err := queryShow(ctx, db, query, func(r *showRow) {
+		columns = append(columns, ColumnInfo{
+			Name:         r.text("name"),
+			DatabaseName: database,
+			SchemaName:   schema,
+			TableName:    table,
+			Type:         r.text("type"),
+			Kind:         r.text("kind"),
+			Nullable:     r.bool("null?"),
+			Default:      r.text("default"),
+			PrimaryKey:   r.bool("primary key"),
+			UniqueKey:    r.bool("unique key"),
+			Expression:   r.text("expression"),
+			Comment:      r.text("comment"),
+		})
+	})
+	return columns, err
+}
+
+// ListColumnsIn lists the columns of the tables and views of the schema of the database, of the database if schema is
+// empty, or of the account if both are empty, by SHOW COLUMNS in a query. The names are exact as those of
+// ListColumns. SHOW COLUMNS has no keys, so PrimaryKey and UniqueKey are false. It filters by opts.Like but doesn't
+// paginate, so opts.Limit and opts.From must not be set.
+func ListColumnsIn(ctx context.Context, db SQLQueryer, database string, schema string, opts *ShowOptions) (
+	[]ColumnInfo, error) {
+	if opts != nil && (opts.Limit > 0 || opts.From != "") {
+		return nil, fmt.Errorf("SHOW COLUMNS doesn't support LIMIT and FROM")
+	}
+	var columns []ColumnInfo
+	err := queryShow(ctx, db, showQuery("COLUMNS", showScope(database, schema), opts), func(r *showRow) {
+		typ, nullable := r.dataType("data_type")
+		columns = append(columns, ColumnInfo{
+			Name:         r.text("column_name"),
+			DatabaseName: r.text("database_name"),
+			SchemaName:   r.text("schema_name"),
+			TableName:    r.text("table_name"),
+			Type:         typ,
+			Kind:         r.text("kind"),
+			Nullable:     nullable,
+			Default:      r.text("default"),
+			Expression:   r.text("expression"),
+			Comment:      r.text("comment"),
+		})
+	})
+	return columns, err
+}
+
+// showScope returns the IN clause of the schema of the database, of the database if schema is empty, or of the
+// account if both are empty.
+func showScope(database string, schema string) string {
+	switch {
+	case database == "":
+		return "IN ACCOUNT"
+	case schema == "":
+		return "IN DATABASE " + QuoteIdentifier(database)
+	}
+	return "IN SCHEMA " + QuoteIdentifier(database) + "." + QuoteIdentifier(schema)
+}
+
+// showQuery returns the SHOW statement of the objects in the scope with the options.
+func showQuery(objects string, scope string, opts *ShowOptions) string {
+	query := "SHOW " + objects
+	if opts != nil && opts.Like != "" {
+		query += " LIKE " + quoteStringLiteral(opts.Like)
+	}
+	if scope != "" {
+		query += " " + scope
+	}
+	if opts != nil && opts.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(opts.Limit)
+		if opts.From != "" {
+			query += " FROM " + quoteStringLiteral(opts.From)
+		}
+	}
+	return query
+}
+
+// showRow is a row of SHOW or DESCRIBE by lowercase column name. The values are parsed leniently as the columns vary
+// by release, and the first parse error is kept.
+type showRow struct {
+	values map[string]sql.NullString
+	err    error
+}
+
+func (r *showRow) text(column string) string {
+	return r.values[column].String
+}
+
+func (r *showRow) bool(column string) bool {
+	switch strings.ToUpper(r.text(column)) {
+	case "Y", "YES", "TRUE", "ON":
+		return true
+	}
+	return false
+}
+
+func (r *showRow) int(column string) int64 {
+	v := r.text(column)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("invalid %v: %v", column, err)
+	}
+	return n
+}
+
+func (r *showRow) time(column string) time.Time {
+	v := r.text(column)
+	if v == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("invalid %v: %v", column, err)
+	}
+	return t
+}
+
+// dataType returns the type of the data_type of SHOW COLUMNS, e.g., {"type":"FIXED","precision":38,"scale":0}, as
+// DESCRIBE shows it, e.g., NUMBER(38,0), and whether it is nullable.
+func (r *showRow) dataType(column string) (string, bool) {
+	v := r.text(column)
+	if v == "" {
+		return "", false
+	}
+	var t struct {
+		Type      string `json:"type"`
+		Precision int64  `json:"precision"`
+		Scale     int64  `json:"scale"`
+		Length    int64  `json:"length"`
+		Nullable  bool   `json:"nullable"`
+	}
+	if err := json.Unmarshal([]byte(v), &t); err != nil {
+		if r.err == nil {
+			r.err = fmt.Errorf("invalid %v: %v", column, err)
+		}
+		return "", false
+	}
+	switch t.Type {
+	case "FIXED":
+		return fmt.Sprintf("NUMBER(%v,%v)", t.Precision, t.Scale), t.Nullable
+	case "TEXT":
+		return fmt.Sprintf("VARCHAR(%v)", t.Length), t.Nullable
+	case "BINARY":
+		return fmt.Sprintf("BINARY(%v)", t.Length), t.Nullable
+	case "REAL":
+		return "FLOAT", t.Nullable
+	case "TIME", "TIMESTAMP_LTZ", "TIMESTAMP_NTZ", "TIMESTAMP_TZ":
+		return fmt.Sprintf("%v(%v)", t.Type, t.Scale), t.Nullable
+	}
+	return t.Type, t.Nullable
+}
+
+// queryShow runs the SHOW or DESCRIBE statement and calls f with each row.
+func queryShow(ctx context.Context, db SQLQueryer, query string, f func(r *showRow)) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return err
+		}
+		r := showRow{values: make(map[string]sql.NullString, len(columns))}
+		for i, column := range columns {
+			r.values[strings.ToLower(column)] = values[i]
+		}
+		f(&r)
+		if r.err != nil {
+			return r.err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestListTables(t *testing.T) {
+	str := func(s string) *string {
+		return &s
+	}
+	columns := []string{"created_on", "name", "database_name", "schema_name", "kind", "comment", "cluster_by", "rows",
+		"bytes", "owner", "retention_time", "automatic_clustering", "change_tracking"}
+	db := sql.OpenDB(newFakeRowsConnector(columns, [][]*string{
+		{str("2020-06-01T10:00:00.123-07:00"), str("ORDERS"), str("MYDB"), str("PUBLIC"), str("TABLE"), str(""),
+			str("LINEAR(DAY)"), str("1000"), str("65536"), str("SYSADMIN"), str("1"), str("ON"), str("OFF")},
+		{str("2020-06-02T10:00:00.000-07:00"), str("My Table"), str("MYDB"), str("PUBLIC"), str("TRANSIENT"),
+			str("note"), str(""), nil, nil, str("SYSADMIN"), str("0"), str("OFF"), str("ON")},
+	}))
+	defer db.Close()
+	tables, err := ListTables(context.Background(), db, "MYDB", "PUBLIC", &ShowOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("failed to list. err: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("unexpected tables: %+v", tables)
+	}
+	table := tables[0]
+	if table.Name != "ORDERS" || table.Kind != "TABLE" || table.ClusterBy != "LINEAR(DAY)" || table.Rows != 1000 ||
+		table.Bytes != 65536 || table.RetentionTime != 1 || !table.AutomaticClustering || table.ChangeTracking ||
+		!table.CreatedOn.Equal(time.Date(2020, 6, 1, 17, 0, 0, 123000000, time.UTC)) {
+		t.Fatalf("unexpected table: %+v", table)
+	}
+	table = tables[1]
+	if table.Name != "My Table" || table.Comment != "note" || table.Rows != 0 || !table.ChangeTracking {
+		t.Fatalf("unexpected table: %+v", table)
+	}
+
+	db = sql.OpenDB(newFakeRowsConnector([]string{"name", "rows"},
+		[][]*string{{str("ORDERS"), str("x")}}))
+	defer db.Close()
+	if _, err = ListTables(context.Background(), db, "MYDB", "PUBLIC", nil); err == nil {
+		t.Fatal("should fail with an invalid number of rows")
+	}
+}
+
+func TestListColumns(t *testing.T) {
+	str := func(s string) *string {
+		return &s
+	}
+	columns := []string{"name", "type", "kind", "null?", "default", "primary key", "unique key", "check", "expression",
+		"comment", "policy name"}
+	db := sql.OpenDB(newFakeRowsConnector(columns, [][]*string{
+		{str("ID"), str("NUMBER(38,0)"), str("COLUMN"), str("N"), str("MYDB.PUBLIC.SEQ.NEXTVAL"), str("Y"), str("N"),
+			nil, nil, str("key"), nil},
+		{str("name"), str("VARCHAR(16777216)"), str("COLUMN"), str("Y"), nil, str("N"), str("Y"), nil, nil, nil, nil},
+	}))
+	defer db.Close()
+	result, err := ListColumns(context.Background(), db, "MYDB", "PUBLIC", "ORDERS")
+	if err != nil {
+		t.Fatalf("failed to describe. err: %v", err)
+	}
+	if len(result) != 2 || result[0].Name != "ID" || result[0].Type != "NUMBER(38,0)" || result[0].Nullable ||
+		result[0].Default != "MYDB.PUBLIC.SEQ.NEXTVAL" || !result[0].PrimaryKey || result[0].Comment != "key" ||
+		result[1].Name != "name" || !result[1].Nullable || result[1].Default != "" || !result[1].UniqueKey {
+		t.Fatalf("unexpected columns: %+v", result)
+	}
+}
+
+func TestListColumnsIn(t *testing.T) {
+	str := func(s string) *string {
+		return &s
+	}
+	columns := []string{"table_name", "schema_name", "column_name", "data_type", "null?", "default", "kind",
+		"expression", "comment", "database_name", "autoincrement"}
+	db := sql.OpenDB(newFakeRowsConnector(columns, [][]*string{
+		{str("ORDERS"), str("PUBLIC"), str("ID"), str(`{"type":"FIXED","precision":38,"scale":0,"nullable":false}`),
+			str("NOT_NULL"), str(""), str("COLUMN"), nil, str("key"), str("MYDB"), str("")},
+		{str("ORDERS"), str("PUBLIC"), str("NAME"),
+			str(`{"type":"TEXT","length":16777216,"byteLength":16777216,"nullable":true,"fixed":false}`),
+			str("true"), nil, str("COLUMN"), nil, nil, str("MYDB"), nil},
+		{str("ORDERS"), str("PUBLIC"), str("SHIPPED"), str(`{"type":"TIMESTAMP_NTZ","precision":0,"scale":9,
+			"nullable":true}`), str("true"), nil, str("COLUMN"), nil, nil, str("MYDB"), nil},
+		{str("ORDERS"), str("PUBLIC"), str("DAY"), str(`{"type":"DATE","nullable":true}`), str("true"), nil,
+			str("COLUMN"), nil, nil, str("MYDB"), nil},
+	}))
+	defer db.Close()
+	result, err := ListColumnsIn(context.Background(), db, "MYDB", "PUBLIC", &ShowOptions{Like: "%"})
+	if err != nil {
+		t.Fatalf("failed to show. err: %v", err)
+	}
+	if len(result) != 4 || result[0].Name != "ID" || result[0].TableName != "ORDERS" ||
+		result[0].SchemaName != "PUBLIC" || result[0].DatabaseName != "MYDB" || result[0].Type != "NUMBER(38,0)" ||
+		result[0].Nullable || result[0].Comment != "key" || result[1].Type != "VARCHAR(16777216)" ||
+		!result[1].Nullable || result[2].Type != "TIMESTAMP_NTZ(9)" || result[3].Type != "DATE" {
+		t.Fatalf("unexpected columns: %+v", result)
+	}
+
+	db = sql.OpenDB(newFakeRowsConnector(columns, [][]*string{
+		{str("ORDERS"), str("PUBLIC"), str("ID"), str("FIXED"), nil, nil, nil, nil, nil, str("MYDB"), nil},
+	}))
+	defer db.Close()
+	if _, err = ListColumnsIn(context.Background(), db, "MYDB", "", nil); err == nil {
+		t.Fatal("should fail with an invalid data type")
+	}
+	for _, opts := range []*ShowOptions{{Limit: 10}, {From: "ID"}} {
+		if _, err = ListColumnsIn(context.Background(), db, "MYDB", "", opts); err == nil {
+			t.Fatalf("should fail with the pagination of SHOW COLUMNS: %+v", opts)
+		}
+	}
+}
+
+func TestShowQuery(t *testing.T) {
+	testcases := []struct {
+		objects string
+		scope   string
+		opts    *ShowOptions
+		query   string
+	}{
+		{"DATABASES", "", nil, "SHOW DATABASES"},
+		{"SCHEMAS", showScope("", ""), &ShowOptions{Like: "PROD%"}, "SHOW SCHEMAS LIKE 'PROD%' IN ACCOUNT"},
+		{"TABLES", showScope("my\"db", ""), &ShowOptions{Limit: 100},
+			`SHOW TABLES IN DATABASE "my""db" LIMIT 100`},
+		{"VIEWS", showScope("MYDB", "PUBLIC"), &ShowOptions{Like: "V_%", Limit: 10, From: "V_O'NEIL"},
+			`SHOW VIEWS LIKE 'V_%' IN SCHEMA "MYDB"."PUBLIC" LIMIT 10 FROM 'V_O\'NEIL'`},
+		{"COLUMNS", showScope("MYDB", ""), &ShowOptions{Like: "ID%"}, `SHOW COLUMNS LIKE 'ID%' IN DATABASE "MYDB"`},
+	}
+	for _, test := range testcases {
+		if query := showQuery(test.objects, test.scope, test.opts); query != test.query {
+			t.Errorf("unexpected query: %v, expected: %v", query, test.query)
+		}
+	}
+}
+
+func TestNormalizeIdentifier(t *testing.T) {
+	testcases := []struct {
+		identifier string
+		name       string
+	}{
+		{"mytable", "MYTABLE"},
+		{"My_Table$1", "MY_TABLE$1"},
+		{`"MyTable"`, "MyTable"},
+		{`"my ""quoted"" table"`, `my "quoted" table`},
+		{`"`, `"`},
+	}
+	for _, test := range testcases {
+		name := NormalizeIdentifier(test.identifier)
+		if name != test.name {
+			t.Errorf("unexpected name of %v: %v, expected: %v", test.identifier, name, test.name)
+		}
+		if test.identifier[0] == '"' && len(test.identifier) > 1 && QuoteIdentifier(name) != test.identifier {
+			t.Errorf("unexpected quoted identifier of %v: %v", name, QuoteIdentifier(name))
+		}
+	}
+}
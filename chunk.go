@@ -136,6 +136,15 @@ func (lcd *largeChunkDecoder) decodeCell() (*string, error) {
 // doesn't contain any escaped characters, we can construct the
 // return string directly without writing to the sbuf
 func (lcd *largeChunkDecoder) decodeString() (string, error) {
+	if err := lcd.decodeStringBytes(); err != nil {
+		return "", err
+	}
+	return lcd.sbuf.String(), nil
+}
+
+// decodeStringBytes unescapes the next string into sbuf without
+// materializing it, so callers can parse the bytes in place
+func (lcd *largeChunkDecoder) decodeStringBytes() error {
 	lcd.sbuf.Reset()
 	for {
 		// NOTE if you make changes here, ensure this
@@ -145,10 +154,10 @@ func (lcd *largeChunkDecoder) decodeString() (string, error) {
 			break
 		} else if c == '\\' {
 			if err := lcd.decodeEscaped(); err != nil {
-				return "", err
+				return err
 			}
 		} else if c < ' ' {
-			return "", lcd.mkError("unexpected control character")
+			return lcd.mkError("unexpected control character")
 		} else if c < utf8.RuneSelf {
 			lcd.sbuf.WriteByte(c)
 		} else {
@@ -156,7 +165,7 @@ func (lcd *largeChunkDecoder) decodeString() (string, error) {
 			lcd.sbuf.WriteRune(lcd.readRune())
 		}
 	}
-	return lcd.sbuf.String(), nil
+	return nil
 }
 
 func (lcd *largeChunkDecoder) decodeEscaped() error {
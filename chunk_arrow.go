@@ -8,6 +8,7 @@ import (
 	"github.com/apache/arrow/go/arrow/ipc"
 	"github.com/apache/arrow/go/arrow/memory"
 	"io"
+	"time"
 )
 
 type arrowResultChunk struct {
@@ -17,7 +18,7 @@ type arrowResultChunk struct {
 	allocator        memory.Allocator
 }
 
-func (arc *arrowResultChunk) decodeArrowChunk(rowType []execResponseRowType) ([]chunkRowType, error) {
+func (arc *arrowResultChunk) decodeArrowChunk(rowType []execResponseRowType, loc *time.Location, civil bool) ([]chunkRowType, error) {
 	logger.Debug("Arrow Decoder")
 
 	var chunkRows []chunkRowType
@@ -36,7 +37,7 @@ func (arc *arrowResultChunk) decodeArrowChunk(rowType []execResponseRowType) ([]
 
 		for colIdx, col := range columns {
 			destcol := make([]snowflakeValue, numRows)
-			err := arrowToValue(&destcol, rowType[colIdx], col)
+			err := arrowToValue(&destcol, rowType[colIdx], col, loc, civil)
 			if err != nil {
 				return nil, err
 			}
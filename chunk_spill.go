@@ -0,0 +1,157 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+const (
+	spilledChunkFilePattern = "gosnowflake-chunk-"
+	spillDirPrefix          = "gosnowflake-spill-" // followed by the process ID
+)
+
+var (
+	spillDirMu    sync.Mutex
+	sweptSpillDir = make(map[string]bool) // ChunkSpillDir swept by this process
+)
+
+// spillChunk writes the chunk body to a temporary file in the spill directory of the process, compressing it unless
+// it is already, and hands the file over to the consumer.
+func spillChunk(scd *snowflakeChunkDownloader, idx int, bufStream *bufio.Reader) error {
+	dir, err := spillDir()
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, spilledChunkFilePattern)
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS != "windows" {
+		// the file stays readable through f while the system reclaims it however the process exits.
+		if err = os.Remove(f.Name()); err != nil {
+			logger.Warningf("failed to unlink spilled chunk file. file: %v, err: %v", f.Name(), err)
+		}
+	}
+	if err = writeSpilledChunk(f, bufStream); err != nil {
+		removeSpilledChunk(f)
+		return err
+	}
+	logger.Debugf("spilled chunk: %v, file: %v", idx+1, f.Name())
+
+	scd.ChunksMutex.Lock()
+	defer scd.ChunksMutex.Unlock()
+	if scd.closed {
+		removeSpilledChunk(f)
+		return nil
+	}
+	scd.SpilledChunks[idx] = f
+	scd.ChunksDownloaded[idx] = true
+	return nil
+}
+
+// spillDir returns the directory of the process in ChunkSpillDir, creating it. The files of a process that crashed or
+// was killed are left behind, on Windows in particular where they can't be unlinked while open, so the first call for
+// a ChunkSpillDir removes the directories of the processes that no longer run.
+func spillDir() (string, error) {
+	parent := ChunkSpillDir
+	if parent == "" {
+		parent = os.TempDir()
+	}
+	spillDirMu.Lock()
+	defer spillDirMu.Unlock()
+	if !sweptSpillDir[parent] {
+		sweepSpillDirs(parent)
+		sweptSpillDir[parent] = true
+	}
+	dir := filepath.Join(parent, spillDirPrefix+strconv.Itoa(os.Getpid()))
+	// created on every call in case a cleaner of the temporary directory has removed it
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// sweepSpillDirs removes the spill directories in parent of the processes that no longer run.
+func sweepSpillDirs(parent string) {
+	entries, err := ioutil.ReadDir(parent)
+	if err != nil {
+		logger.Warningf("failed to read the chunk spill directory. dir: %v, err: %v", parent, err)
+		return
+	}
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), spillDirPrefix) {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimPrefix(e.Name(), spillDirPrefix))
+		if err != nil || pid == os.Getpid() || processRunning(pid) {
+			continue
+		}
+		dir := filepath.Join(parent, e.Name())
+		if err = os.RemoveAll(dir); err != nil {
+			logger.Warningf("failed to remove stale spilled chunks. dir: %v, err: %v", dir, err)
+			continue
+		}
+		logger.Debugf("removed stale spilled chunks. dir: %v", dir)
+	}
+}
+
+// processRunning returns whether the process of pid runs. A process of another user is running. A reused pid keeps
+// the directory until the process exits, and on Windows the files a running process holds open can't be removed.
+func processRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false // Windows opens the process, which fails if it doesn't exist
+	}
+	defer p.Release()
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
+
+func writeSpilledChunk(f *os.File, bufStream *bufio.Reader) error {
+	gzipMagic, err := bufStream.Peek(2)
+	if err != nil {
+		return err
+	}
+	if gzipMagic[0] == 0x1f && gzipMagic[1] == 0x8b {
+		_, err = io.Copy(f, bufStream)
+		return err
+	}
+	w := gzip.NewWriter(f)
+	if _, err = io.Copy(w, bufStream); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// decodeSpilledChunk decodes the chunk idx written to f by spillChunk.
+func decodeSpilledChunk(scd *snowflakeChunkDownloader, idx int, f *os.File) ([]chunkRowType, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return decodeChunkRows(scd, idx, bufio.NewReader(f))
+}
+
+func removeSpilledChunk(f *os.File) {
+	if err := f.Close(); err != nil {
+		logger.Warningf("failed to close spilled chunk file. file: %v, err: %v", f.Name(), err)
+	}
+	if runtime.GOOS == "windows" {
+		if err := os.Remove(f.Name()); err != nil {
+			logger.Warningf("failed to remove spilled chunk file. file: %v, err: %v", f.Name(), err)
+		}
+	}
+}
@@ -2,7 +2,11 @@ package gosnowflake
 
 import (
 	"bytes"
+	"database/sql/driver"
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -163,3 +167,179 @@ func testDecodeErr(t *testing.T, s string) {
 		t.Fatalf("expected decode to fail for input: %s", s)
 	}
 }
+
+var typedChunkRowType = []execResponseRowType{
+	{Name: "c1", Type: "fixed"},
+	{Name: "c2", Type: "text"},
+	{Name: "c3", Type: "date"},
+	{Name: "c4", Type: "time", Scale: 9},
+	{Name: "c5", Type: "timestamp_ntz", Scale: 9},
+	{Name: "c6", Type: "timestamp_ltz", Scale: 9},
+	{Name: "c7", Type: "timestamp_tz", Scale: 9},
+	{Name: "c8", Type: "binary"},
+	{Name: "c9", Type: "boolean"},
+	{Name: "c10", Type: "variant"},
+}
+
+func TestTypedChunkDecoderMatchesStringToValue(t *testing.T) {
+	chunk := `[
+	  ["42", "hello \"world\"", "18262", "3723.123456789", "1549491451.123", "1549491451.123456789", "1549491451.5 960", "DEADBEEF", "true", "{\"k\": [1, 2]}"],
+	  [null, null, null, null, null, null, null, null, null, null],
+	  ["-1", "❄❄", "-1", "0", "-86400", "0.000000001", "0 1440", "", "false", "[]"]
+	]`
+	var expected [][]*string
+	if err := json.Unmarshal([]byte(chunk), &expected); err != nil {
+		t.Fatalf("test case is not valid json: %v", err)
+	}
+	rows, err := decodeTypedChunk(strings.NewReader(chunk), typedChunkRowType, len(expected), nil, false, false)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("number of rows didn't match. expected: %v, got: %v", len(expected), len(rows))
+	}
+	for i, row := range rows {
+		for j, meta := range typedChunkRowType {
+			var want driver.Value
+			if err = stringToValue(&want, meta, expected[i][j], nil, false, false); err != nil {
+				t.Fatalf("failed to convert %v: %v", expected[i][j], err)
+			}
+			if !reflect.DeepEqual(want, driver.Value(row.TypedRow[j])) {
+				t.Errorf("value mismatch. row: %v, column: %v, expected: %#v, got: %#v",
+					i, meta.Name, want, row.TypedRow[j])
+			}
+		}
+	}
+}
+
+func TestTypedChunkDecoderBadData(t *testing.T) {
+	rowType := typedChunkRowType[:3]
+	for _, s := range []string{
+		"",
+		"[[]]",
+		`[["1", "a"]]`,
+		`[["1", "a", "2", "3"]]`,
+		`[["1", "a", "x"]]`,
+		`[["1", "a", 3]]`,
+		`[["1", "a", "3"]`,
+	} {
+		if _, err := decodeTypedChunk(strings.NewReader(s), rowType, 0, nil, false, false); err == nil {
+			t.Errorf("expected decode to fail for input: %s", s)
+		}
+	}
+	for _, tc := range []struct {
+		typ string
+		in  string
+	}{
+		{"timestamp_tz", "1549491451"},
+		{"timestamp_tz", "1549491451 x"},
+		{"binary", "XY"},
+	} {
+		s := `[["` + tc.in + `"]]`
+		_, err := decodeTypedChunk(strings.NewReader(s), []execResponseRowType{{Type: tc.typ}}, 0, nil, false, false)
+		if _, ok := err.(*SnowflakeError); !ok {
+			t.Errorf("expected a SnowflakeError for %v value %v. got: %v", tc.typ, tc.in, err)
+		}
+	}
+}
+
+func TestTypedChunkDecoderRowHint(t *testing.T) {
+	// more rows than the hint forces additional value buffers
+	var b strings.Builder
+	b.WriteString("[")
+	numRows := typedChunkRowBlock*2 + 3
+	for i := 0; i < numRows; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `["%v", "row%v"]`, i, i)
+	}
+	b.WriteString("]")
+	rows, err := decodeTypedChunk(strings.NewReader(b.String()), typedChunkRowType[:2], 10, nil, false, false)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if len(rows) != numRows {
+		t.Fatalf("number of rows didn't match. expected: %v, got: %v", numRows, len(rows))
+	}
+	for i, row := range rows {
+		if row.TypedRow[0] != strconv.Itoa(i) || row.TypedRow[1] != fmt.Sprintf("row%v", i) {
+			t.Fatalf("unexpected row %v: %v", i, row.TypedRow)
+		}
+	}
+}
+
+// benchmarkChunk generates a JSON chunk with a mix of the common column types
+func benchmarkChunk(numRows int) []byte {
+	var b bytes.Buffer
+	b.WriteString("[")
+	for i := 0; i < numRows; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `["%v","some text \"%v\" ❄","%v","%v.123456789","%v.123","%v.123456789","%v.5 1440","0A0B0C0D0E0F","true","{\"k\": %v}"]`,
+			i, i, 18000+i%1000, i%86400, 1549491451+i, 1549491451+i, 1549491451+i, i)
+	}
+	b.WriteString("]")
+	return b.Bytes()
+}
+
+const benchmarkChunkRows = 10000
+
+func BenchmarkDecodeChunkEncodingJSON(b *testing.B) {
+	chunk := benchmarkChunk(benchmarkChunkRows)
+	dest := make([]driver.Value, len(typedChunkRowType))
+	b.SetBytes(int64(len(chunk)))
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		var rows [][]*string
+		if err := json.NewDecoder(bytes.NewReader(chunk)).Decode(&rows); err != nil {
+			b.Fatal(err)
+		}
+		for _, row := range rows {
+			for i := range row {
+				if err := stringToValue(&dest[i], typedChunkRowType[i], row[i], nil, false, false); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkDecodeChunkLarge(b *testing.B) {
+	chunk := benchmarkChunk(benchmarkChunkRows)
+	dest := make([]driver.Value, len(typedChunkRowType))
+	b.SetBytes(int64(len(chunk)))
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		rows, err := decodeLargeChunk(bytes.NewReader(chunk), benchmarkChunkRows, len(typedChunkRowType))
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, row := range rows {
+			for i := range row {
+				if err := stringToValue(&dest[i], typedChunkRowType[i], row[i], nil, false, false); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkDecodeChunkTyped(b *testing.B) {
+	chunk := benchmarkChunk(benchmarkChunkRows)
+	dest := make([]driver.Value, len(typedChunkRowType))
+	b.SetBytes(int64(len(chunk)))
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		rows, err := decodeTypedChunk(bytes.NewReader(chunk), typedChunkRowType, benchmarkChunkRows, nil, false, false)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, row := range rows {
+			for i := range row.TypedRow {
+				dest[i] = row.TypedRow[i]
+			}
+		}
+	}
+}
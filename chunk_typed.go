@@ -0,0 +1,154 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+// typedChunkRowBlock is the number of rows allocated at once when a chunk
+// turns out to have more rows than its metadata announced
+const typedChunkRowBlock = 1024
+
+// typedChunkDecoder scans a JSON chunk like largeChunkDecoder, but converts
+// every cell to its Go value while streaming, using the column metadata of
+// the result set. The values of a chunk are stored in a few large buffers
+// that the rows slice into, so a row doesn't cost any allocation of its own.
+type typedChunkDecoder struct {
+	largeChunkDecoder
+
+	rowType []execResponseRowType
+	loc     *time.Location   // location of TIMESTAMP_LTZ values
+	civil   bool             // DATE and TIME values are Date and TimeOfDay
+	base64  bool             // BINARY values are in base64 instead of hex
+	values  []snowflakeValue // unused part of the current value buffer
+}
+
+func decodeTypedChunk(r io.Reader, rowType []execResponseRowType, rowCount int, loc *time.Location, civil bool,
+	binaryBase64 bool) ([]chunkRowType, error) {
+	logger.Info("typed JSON Decoder")
+	tcd := typedChunkDecoder{
+		largeChunkDecoder: largeChunkDecoder{
+			r, rowCount, len(rowType),
+			0, 0,
+			make([]byte, defaultChunkBufferSize),
+			bytes.NewBuffer(make([]byte, defaultStringBufferSize)),
+			nil,
+		},
+		rowType: rowType,
+		loc:     loc,
+		civil:   civil,
+		base64:  binaryBase64,
+		values:  make([]snowflakeValue, rowCount*len(rowType)),
+	}
+
+	rows, err := tcd.decode()
+	if tcd.ioError != nil && tcd.ioError != io.EOF {
+		return nil, tcd.ioError
+	} else if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (tcd *typedChunkDecoder) decode() ([]chunkRowType, error) {
+	if tcd.nextByteNonWhitespace() != '[' {
+		return nil, tcd.mkError("expected chunk to begin with '['")
+	}
+
+	rows := make([]chunkRowType, 0, tcd.rows)
+	if tcd.nextByteNonWhitespace() == ']' {
+		return rows, nil // special case of an empty chunk
+	}
+	tcd.rewind(1)
+
+OuterLoop:
+	for {
+		row, err := tcd.decodeRow()
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, chunkRowType{TypedRow: row})
+
+		switch c := tcd.nextByteNonWhitespace(); {
+		case c == ',':
+			continue // more elements in the array
+		case c == ']':
+			return rows, nil // we've scanned the whole chunk
+		default:
+			break OuterLoop
+		}
+	}
+	return nil, tcd.mkError("invalid row boundary")
+}
+
+func (tcd *typedChunkDecoder) decodeRow() ([]snowflakeValue, error) {
+	if tcd.nextByteNonWhitespace() != '[' {
+		return nil, tcd.mkError("expected row to begin with '['")
+	}
+
+	row := tcd.allocRow()
+	if tcd.nextByteNonWhitespace() == ']' {
+		if len(row) != 0 {
+			return nil, tcd.mkError("row has fewer cells than columns")
+		}
+		return row, nil // special case of an empty row
+	}
+	tcd.rewind(1)
+
+OuterLoop:
+	for i := 0; ; i++ {
+		if i == len(row) {
+			return nil, tcd.mkError("row has more cells than columns")
+		}
+		cell, err := tcd.decodeCell(&tcd.rowType[i])
+		if err != nil {
+			return nil, err
+		}
+		row[i] = cell
+
+		switch c := tcd.nextByteNonWhitespace(); {
+		case c == ',':
+			continue // more elements in the array
+		case c == ']':
+			if i+1 != len(row) {
+				return nil, tcd.mkError("row has fewer cells than columns")
+			}
+			return row, nil // we've scanned the whole row
+		default:
+			break OuterLoop
+		}
+	}
+	return nil, tcd.mkError("invalid cell boundary")
+}
+
+func (tcd *typedChunkDecoder) decodeCell(meta *execResponseRowType) (snowflakeValue, error) {
+	c := tcd.nextByteNonWhitespace()
+	if c == '"' {
+		if err := tcd.decodeStringBytes(); err != nil {
+			return nil, err
+		}
+		return bytesToValue(meta, tcd.sbuf.Bytes(), tcd.loc, tcd.civil, tcd.base64)
+	} else if c == 'n' {
+		if tcd.nextByte() == 'u' &&
+			tcd.nextByte() == 'l' &&
+			tcd.nextByte() == 'l' {
+			return nil, nil
+		}
+	}
+	return nil, tcd.mkError("cell begins with unexpected byte")
+}
+
+// allocRow hands out the next row of the value buffer
+func (tcd *typedChunkDecoder) allocRow() []snowflakeValue {
+	n := len(tcd.rowType)
+	if len(tcd.values) < n {
+		tcd.values = make([]snowflakeValue, n*typedChunkRowBlock)
+	}
+	row := tcd.values[:n:n]
+	tcd.values = tcd.values[n:]
+	return row
+}
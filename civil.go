@@ -0,0 +1,171 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	civilDateFormat      = "2006-01-02"
+	civilTimeOfDayFormat = "15:04:05.999999999"
+)
+
+// Date is a calendar date of a DATE column, independent of any time zone.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date of t in the location of t.
+func DateOf(t time.Time) Date {
+	var d Date
+	d.Year, d.Month, d.Day = t.Date()
+	return d
+}
+
+// In returns midnight of the date in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// String returns the date in the YYYY-MM-DD format.
+func (d Date) String() string {
+	return d.In(time.UTC).Format(civilDateFormat)
+}
+
+// Before returns true if d is before e.
+func (d Date) Before(e Date) bool {
+	return d.In(time.UTC).Before(e.In(time.UTC))
+}
+
+// After returns true if d is after e.
+func (d Date) After(e Date) bool {
+	return e.Before(d)
+}
+
+// Scan implements the sql.Scanner interface. It takes the date of a time.Time as is, e.g., of a DATE column fetched
+// without CivilDateTime, or parses the YYYY-MM-DD format.
+func (d *Date) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case Date:
+		*d = v
+	case time.Time:
+		*d = DateOf(v)
+	case string:
+		t, err := time.Parse(civilDateFormat, v)
+		if err != nil {
+			return err
+		}
+		*d = DateOf(t)
+	default:
+		return fmt.Errorf("cannot convert %T to Date", src)
+	}
+	return nil
+}
+
+// NullDate is a Date that may be NULL, as sql.NullTime is a time.Time that may be. It scans NULL, which Date fails to,
+// and binds NULL if it isn't valid.
+type NullDate struct {
+	Date  Date
+	Valid bool // Valid is true if Date is not NULL
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullDate) Scan(src interface{}) error {
+	if src == nil {
+		n.Date, n.Valid = Date{}, false
+		return nil
+	}
+	err := n.Date.Scan(src)
+	n.Valid = err == nil
+	return err
+}
+
+// TimeOfDay is a time of a TIME column, independent of any date and time zone.
+type TimeOfDay struct {
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+// TimeOfDayOf returns the time of day of t in the location of t.
+func TimeOfDayOf(t time.Time) TimeOfDay {
+	return TimeOfDay{Hour: t.Hour(), Minute: t.Minute(), Second: t.Second(), Nanosecond: t.Nanosecond()}
+}
+
+// timeOfDayOfNanos returns the time of day of the nanoseconds since midnight.
+func timeOfDayOfNanos(nanos int64) TimeOfDay {
+	return TimeOfDay{
+		Hour:       int(nanos / int64(time.Hour)),
+		Minute:     int(nanos / int64(time.Minute) % 60),
+		Second:     int(nanos / int64(time.Second) % 60),
+		Nanosecond: int(nanos % int64(time.Second)),
+	}
+}
+
+// Nanos returns the number of nanoseconds since midnight.
+func (t TimeOfDay) Nanos() int64 {
+	return int64(t.Hour)*int64(time.Hour) + int64(t.Minute)*int64(time.Minute) + int64(t.Second)*int64(time.Second) +
+		int64(t.Nanosecond)
+}
+
+// On returns the time of day on the date in loc.
+func (t TimeOfDay) On(d Date, loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, t.Hour, t.Minute, t.Second, t.Nanosecond, loc)
+}
+
+// String returns the time of day in the HH:MM:SS.fffffffff format without trailing zeros of the fraction.
+func (t TimeOfDay) String() string {
+	return t.On(Date{Year: 1, Month: time.January, Day: 1}, time.UTC).Format(civilTimeOfDayFormat)
+}
+
+// Before returns true if t is before u.
+func (t TimeOfDay) Before(u TimeOfDay) bool {
+	return t.Nanos() < u.Nanos()
+}
+
+// After returns true if t is after u.
+func (t TimeOfDay) After(u TimeOfDay) bool {
+	return t.Nanos() > u.Nanos()
+}
+
+// Scan implements the sql.Scanner interface. It takes the time of day of a time.Time as is, e.g., of a TIME column
+// fetched without CivilDateTime, or parses the HH:MM:SS format with an optional fraction.
+func (t *TimeOfDay) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case TimeOfDay:
+		*t = v
+	case time.Time:
+		*t = TimeOfDayOf(v)
+	case string:
+		tm, err := time.Parse(civilTimeOfDayFormat, v)
+		if err != nil {
+			return err
+		}
+		*t = TimeOfDayOf(tm)
+	default:
+		return fmt.Errorf("cannot convert %T to TimeOfDay", src)
+	}
+	return nil
+}
+
+// NullTimeOfDay is a TimeOfDay that may be NULL, as NullDate is a Date that may be.
+type NullTimeOfDay struct {
+	TimeOfDay TimeOfDay
+	Valid     bool // Valid is true if TimeOfDay is not NULL
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullTimeOfDay) Scan(src interface{}) error {
+	if src == nil {
+		n.TimeOfDay, n.Valid = TimeOfDay{}, false
+		return nil
+	}
+	err := n.TimeOfDay.Scan(src)
+	n.Valid = err == nil
+	return err
+}
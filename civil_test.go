@@ -0,0 +1,223 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDate(t *testing.T) {
+	d := DateOf(time.Date(2020, 2, 29, 23, 30, 0, 0, time.FixedZone("-08:00", -8*3600)))
+	if d != (Date{2020, time.February, 29}) {
+		t.Fatalf("unexpected date: %+v", d)
+	}
+	if d.String() != "2020-02-29" {
+		t.Fatalf("unexpected string: %v", d)
+	}
+	if !d.Before(Date{2020, time.March, 1}) || d.After(Date{2020, time.March, 1}) {
+		t.Fatal("unexpected order")
+	}
+	for _, src := range []interface{}{d, d.In(time.UTC), "2020-02-29"} {
+		var e Date
+		if err := e.Scan(src); err != nil {
+			t.Fatalf("failed to scan %v. err: %v", src, err)
+		}
+		if e != d {
+			t.Errorf("unexpected date of %v: %v", src, e)
+		}
+	}
+	var e Date
+	for _, src := range []interface{}{nil, "2020/02/29", int64(1)} {
+		if err := e.Scan(src); err == nil {
+			t.Errorf("should fail to scan %v", src)
+		}
+	}
+}
+
+func TestTimeOfDay(t *testing.T) {
+	tod := TimeOfDay{Hour: 23, Minute: 59, Second: 58, Nanosecond: 123000000}
+	if tod.String() != "23:59:58.123" {
+		t.Fatalf("unexpected string: %v", tod)
+	}
+	if timeOfDayOfNanos(tod.Nanos()) != tod {
+		t.Fatalf("unexpected time of day: %+v", timeOfDayOfNanos(tod.Nanos()))
+	}
+	if !tod.After(TimeOfDay{Hour: 1}) || tod.Before(TimeOfDay{Hour: 1}) {
+		t.Fatal("unexpected order")
+	}
+	for _, src := range []interface{}{tod, tod.On(Date{2020, time.January, 1}, time.Local), "23:59:58.123"} {
+		var u TimeOfDay
+		if err := u.Scan(src); err != nil {
+			t.Fatalf("failed to scan %v. err: %v", src, err)
+		}
+		if u != tod {
+			t.Errorf("unexpected time of day of %v: %+v", src, u)
+		}
+	}
+	var u TimeOfDay
+	if err := u.Scan(int64(1)); err == nil {
+		t.Error("should fail to scan int64")
+	}
+}
+
+func TestCivilDateTimeToValue(t *testing.T) {
+	date := Date{1969, time.December, 31}
+	tod := TimeOfDay{Hour: 12, Minute: 34, Second: 56, Nanosecond: 789000000}
+	for _, tc := range []struct {
+		meta execResponseRowType
+		in   string
+		out  snowflakeValue
+	}{
+		{meta: execResponseRowType{Type: "date"}, in: "-1", out: date},
+		{meta: execResponseRowType{Type: "time", Scale: 3}, in: "45296.789", out: tod},
+	} {
+		var dest driver.Value
+		src := tc.in
+		if err := stringToValue(&dest, tc.meta, &src, nil, true, false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dest != tc.out {
+			t.Errorf("stringToValue: %v. expected: %v, got: %v", tc.meta.Type, tc.out, dest)
+		}
+		v, err := bytesToValue(&tc.meta, []byte(tc.in), nil, true, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v != tc.out {
+			t.Errorf("bytesToValue: %v. expected: %v, got: %v", tc.meta.Type, tc.out, v)
+		}
+	}
+
+	pool := memory.NewGoAllocator()
+	db := array.NewDate32Builder(pool)
+	db.Append(-1)
+	dateArr := db.NewArray()
+	defer dateArr.Release()
+	tb := array.NewInt32Builder(pool)
+	tb.Append(45296789)
+	timeArr := tb.NewArray()
+	defer timeArr.Release()
+	dest := make([]snowflakeValue, 1)
+	if err := arrowToValue(&dest, execResponseRowType{Type: "date"}, dateArr, nil, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest[0] != date {
+		t.Errorf("arrowToValue: date. expected: %v, got: %v", date, dest[0])
+	}
+	if err := arrowToValue(&dest, execResponseRowType{Type: "time", Scale: 3}, timeArr, nil, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest[0] != tod {
+		t.Errorf("arrowToValue: time. expected: %v, got: %v", tod, dest[0])
+	}
+}
+
+func TestCivilDateTimeBinding(t *testing.T) {
+	for _, tc := range []struct {
+		in  driver.Value
+		typ string
+		out string
+	}{
+		{in: Date{1969, time.December, 31}, typ: "DATE", out: "-86400000"},
+		{in: Date{9999, time.December, 31}, typ: "DATE", out: "253402214400000"},
+		{in: TimeOfDay{Hour: 12, Minute: 34, Second: 56, Nanosecond: 789}, typ: "TIME", out: "45296000000789"},
+	} {
+		if typ := goTypeToSnowflake(tc.in, "TIMESTAMP_NTZ"); typ != tc.typ {
+			t.Errorf("unexpected type of %v. expected: %v, got: %v", tc.in, tc.typ, typ)
+		}
+		s, err := valueToString(tc.in, tc.typ)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *s != tc.out {
+			t.Errorf("unexpected value of %v. expected: %v, got: %v", tc.in, tc.out, *s)
+		}
+	}
+	sc := &snowflakeConn{}
+	for _, v := range []driver.Value{Date{}, TimeOfDay{}} {
+		if err := sc.CheckNamedValue(&driver.NamedValue{Value: v}); err != nil {
+			t.Errorf("should accept %T. err: %v", v, err)
+		}
+	}
+}
+
+func TestColumnTypeScanTypeCivilDateTime(t *testing.T) {
+	rows := &snowflakeRows{
+		RowType:         []execResponseRowType{{Type: "date"}, {Type: "time"}},
+		ChunkDownloader: &snowflakeChunkDownloader{CivilDateTime: true},
+	}
+	if st := rows.ColumnTypeScanType(0); st != reflect.TypeOf(Date{}) {
+		t.Errorf("unexpected scan type of DATE: %v", st)
+	}
+	if st := rows.ColumnTypeScanType(1); st != reflect.TypeOf(TimeOfDay{}) {
+		t.Errorf("unexpected scan type of TIME: %v", st)
+	}
+	rows.ChunkDownloader.CivilDateTime = false
+	if st := rows.ColumnTypeScanType(0); st != reflect.TypeOf(time.Time{}) {
+		t.Errorf("unexpected scan type of DATE: %v", st)
+	}
+}
+
+func TestNullDateTimeOfDay(t *testing.T) {
+	str := func(s string) *string {
+		return &s
+	}
+	db := sql.OpenDB(newFakeRowsConnector([]string{"D", "T"}, [][]*string{
+		{str("2020-02-29"), str("23:59:58.123")},
+		{nil, nil},
+	}))
+	defer db.Close()
+	rows, err := db.Query("SELECT D, T FROM T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var dates []NullDate
+	var times []NullTimeOfDay
+	for rows.Next() {
+		var d NullDate
+		var tod NullTimeOfDay
+		if err = rows.Scan(&d, &tod); err != nil {
+			t.Fatalf("failed to scan. err: %v", err)
+		}
+		dates = append(dates, d)
+		times = append(times, tod)
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(dates) != 2 || dates[0] != (NullDate{Date{2020, time.February, 29}, true}) || dates[1] != (NullDate{}) {
+		t.Fatalf("unexpected dates: %+v", dates)
+	}
+	if len(times) != 2 || times[0] != (NullTimeOfDay{TimeOfDay{23, 59, 58, 123000000}, true}) ||
+		times[1] != (NullTimeOfDay{}) {
+		t.Fatalf("unexpected times of day: %+v", times)
+	}
+
+	d := NullDate{Date{2020, time.March, 1}, true}
+	if err = d.Scan("2020/03/01"); err == nil || d.Valid {
+		t.Fatalf("should fail to scan an invalid date: %+v, err: %v", d, err)
+	}
+
+	sc := &snowflakeConn{}
+	for _, tc := range []struct {
+		in  interface{}
+		out driver.Value
+	}{
+		{NullDate{Date{2020, time.March, 1}, true}, Date{2020, time.March, 1}},
+		{NullDate{}, nil},
+		{NullTimeOfDay{TimeOfDay{Hour: 1}, true}, TimeOfDay{Hour: 1}},
+		{NullTimeOfDay{}, nil},
+	} {
+		nv := &driver.NamedValue{Value: tc.in}
+		if err = sc.CheckNamedValue(nv); err != nil || nv.Value != tc.out {
+			t.Errorf("unexpected value of %+v: %v, err: %v", tc.in, nv.Value, err)
+		}
+	}
+}
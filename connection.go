@@ -13,12 +13,13 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
 const (
-	statementTypeIDMulti = int64(0x1000)
+	statementTypeIDSelect = int64(0x1000)
 
 	statementTypeIDDml              = int64(0x3000)
 	statementTypeIDInsert           = statementTypeIDDml + int64(0x100)
@@ -26,6 +27,12 @@ const (
 	statementTypeIDDelete           = statementTypeIDDml + int64(0x300)
 	statementTypeIDMerge            = statementTypeIDDml + int64(0x400)
 	statementTypeIDMultiTableInsert = statementTypeIDDml + int64(0x500)
+	statementTypeIDCopy             = statementTypeIDDml + int64(0x600)
+	statementTypeIDUnload           = statementTypeIDDml + int64(0x700)
+
+	statementTypeIDScl = int64(0x4000)
+	statementTypeIDTcl = int64(0x5000)
+	statementTypeIDDdl = int64(0x6000)
 )
 
 const (
@@ -41,6 +48,11 @@ type snowflakeConn struct {
 	SequenceCounter uint64
 	QueryID         string
 	SQLState        string
+	queryStats      QueryStats     // stats of the last query
+	sessionLocation *time.Location // location of the session TIMEZONE, guarded by stateMu
+	serverVersion   string
+	// stateMu guards the session state of cfg: Database, Schema, Role, Warehouse and Params, and sessionLocation
+	stateMu sync.RWMutex
 }
 
 // isDml returns true if the statement type code is in the range of DML.
@@ -55,9 +67,9 @@ func (sc *snowflakeConn) isDml(v int64) bool {
 }
 
 // isMultiStmt returns true if the statement type code is of type multistatement
-// Note that the statement type code is also equivalent to type INSERT, so an additional check of the name is required
+// Note that the statement type code is that of SELECT, so an additional check of the name is required
 func (sc *snowflakeConn) isMultiStmt(data execResponseData) bool {
-	return data.StatementTypeID == statementTypeIDMulti && data.RowType[0].Name == "multiple statement execution"
+	return data.StatementTypeID == statementTypeIDSelect && data.RowType[0].Name == "multiple statement execution"
 }
 
 func (sc *snowflakeConn) exec(
@@ -77,23 +89,33 @@ func (sc *snowflakeConn) exec(
 	}
 	req.IsInternal = isInternal
 	tsmode := "TIMESTAMP_NTZ"
+	bytesMode := "" // TEXT or BINARY of the next []byte value by a flag
 	idx := 1
 	if len(bindings) > 0 {
 		req.Bindings = make(map[string]execBindParameter, len(bindings))
 		for i, n := 0, len(bindings); i < n; i++ {
-			t := goTypeToSnowflake(bindings[i].Value, tsmode)
+			mode := tsmode
+			if _, ok := bindings[i].Value.([]byte); ok && bytesMode != "" {
+				mode, bytesMode = bytesMode, ""
+			}
+			t := goTypeToSnowflake(bindings[i].Value, mode)
 			logger.WithContext(ctx).Debugf("tmode: %v\n", t)
 			if t == "CHANGE_TYPE" {
-				tsmode, err = dataTypeMode(bindings[i].Value)
+				mode, err = dataTypeMode(bindings[i].Value)
 				if err != nil {
 					return nil, err
 				}
+				if mode == "TEXT" || mode == "BINARY" {
+					bytesMode = mode
+				} else {
+					tsmode = mode
+				}
 			} else {
 				var v1 interface{}
 				if t == "ARRAY" {
 					t, v1 = arrayToString(bindings[i].Value)
 				} else {
-					v1, err = valueToString(bindings[i].Value, tsmode)
+					v1, err = valueToString(bindings[i].Value, t)
 				}
 				if err != nil {
 					return nil, err
@@ -117,8 +139,8 @@ func (sc *snowflakeConn) exec(
 	headers["Content-Type"] = headerContentTypeApplicationJSON
 	headers["accept"] = headerAcceptTypeApplicationSnowflake // TODO v1.1: change to JSON in case of PUT/GET
 	headers["User-Agent"] = userAgent
-	if serviceName, ok := sc.cfg.Params[serviceName]; ok {
-		headers["X-Snowflake-Service"] = *serviceName
+	if serviceName, ok := sc.sessionParameter(serviceName); ok {
+		headers["X-Snowflake-Service"] = serviceName
 	}
 
 	jsonBody, err := json.Marshal(req)
@@ -129,6 +151,7 @@ func (sc *snowflakeConn) exec(
 	var data *execResponse
 
 	requestID := getOrGenerateRequestIDFromContext(ctx)
+	start := time.Now()
 	data, err = sc.rest.FuncPostQuery(ctx, sc.rest, &url.Values{}, headers, jsonBody, sc.rest.RequestTimeout, requestID)
 	if err != nil {
 		return data, err
@@ -153,13 +176,21 @@ func (sc *snowflakeConn) exec(
 		}
 	}
 	logger.WithContext(ctx).Info("Exec/Query SUCCESS")
+	sc.stateMu.Lock()
 	sc.cfg.Database = data.Data.FinalDatabaseName
 	sc.cfg.Schema = data.Data.FinalSchemaName
 	sc.cfg.Role = data.Data.FinalRoleName
 	sc.cfg.Warehouse = data.Data.FinalWarehouseName
+	sc.stateMu.Unlock()
 	sc.QueryID = data.Data.QueryID
 	sc.SQLState = data.Data.SQLState
+	sc.queryStats = newQueryStats(&data.Data, start, time.Since(start))
 	sc.populateSessionParameters(data.Data.Parameters)
+	if data.Data.Command == fileTransferCommandUpload || data.Data.Command == fileTransferCommandDownload {
+		if err = sc.processFileTransfer(ctx, &data.Data); err != nil {
+			return nil, err
+		}
+	}
 	return data, err
 }
 
@@ -262,6 +293,7 @@ func (sc *snowflakeConn) ExecContext(ctx context.Context, query string, args []d
 			affectedRows: updatedRows,
 			insertID:     -1,
 			queryID:      sc.QueryID,
+			stats:        sc.queryStats,
 		}, nil // last insert id is not supported by Snowflake
 	} else if sc.isMultiStmt(data.Data) {
 		childResults := getChildResults(data.Data.ResultIDs, data.Data.ResultTypes)
@@ -308,6 +340,7 @@ func (sc *snowflakeConn) ExecContext(ctx context.Context, query string, args []d
 			affectedRows: updatedRows,
 			insertID:     -1,
 			queryID:      sc.QueryID,
+			stats:        sc.queryStats,
 		}, nil
 	}
 	logger.Debug("DDL")
@@ -354,12 +387,18 @@ func (sc *snowflakeConn) QueryContext(ctx context.Context, query string, args []
 		FuncDownload:       downloadChunk,
 		FuncDownloadHelper: downloadChunkHelper,
 		FuncGet:            getChunk,
+		FuncGetResult:      getChunkResult,
+		QueryID:            data.Data.QueryID,
+		Location:           sc.location(),
+		CivilDateTime:      sc.cfg.CivilDateTime,
+		BinaryBase64:       sc.isBinaryOutputBase64(),
 		RowSet: rowSetType{RowType: data.Data.RowType,
 			JSON:         data.Data.RowSet,
 			RowSetBase64: data.Data.RowSetBase64,
 		},
 	}
 	rows.queryID = sc.QueryID
+	rows.stats = sc.queryStats
 
 	if sc.isMultiStmt(data.Data) {
 		childResults := getChildResults(data.Data.ResultIDs, data.Data.ResultTypes)
@@ -425,9 +464,24 @@ func (sc *snowflakeConn) Ping(ctx context.Context) error {
 }
 
 func (sc *snowflakeConn) CheckNamedValue(nv *driver.NamedValue) error {
+	switch v := nv.Value.(type) {
+	case NullDate:
+		nv.Value = nil
+		if v.Valid {
+			nv.Value = v.Date
+		}
+		return nil
+	case NullTimeOfDay:
+		nv.Value = nil
+		if v.Valid {
+			nv.Value = v.TimeOfDay
+		}
+		return nil
+	}
 	switch reflect.TypeOf(nv.Value) {
 	case reflect.TypeOf([]int{0}), reflect.TypeOf([]int64{0}), reflect.TypeOf([]float64{0}),
-		reflect.TypeOf([]bool{false}), reflect.TypeOf([]string{""}):
+		reflect.TypeOf([]bool{false}), reflect.TypeOf([]string{""}), reflect.TypeOf(&columnArray{}),
+		reflect.TypeOf(Date{}), reflect.TypeOf(TimeOfDay{}):
 		return nil
 	default:
 		return driver.ErrSkip
@@ -437,6 +491,8 @@ func (sc *snowflakeConn) CheckNamedValue(nv *driver.NamedValue) error {
 func (sc *snowflakeConn) populateSessionParameters(parameters []nameValueParameter) {
 	// other session parameters (not all)
 	logger.WithContext(sc.ctx).Infof("params: %#v", parameters)
+	sc.stateMu.Lock()
+	defer sc.stateMu.Unlock()
 	for _, param := range parameters {
 		v := ""
 		switch param.Value.(type) {
@@ -462,12 +518,56 @@ func (sc *snowflakeConn) populateSessionParameters(parameters []nameValueParamet
 	}
 }
 
+// sessionParameter returns the value of the session parameter of the lowercase name, and false if it isn't set.
+func (sc *snowflakeConn) sessionParameter(name string) (string, bool) {
+	sc.stateMu.RLock()
+	defer sc.stateMu.RUnlock()
+	v, ok := sc.cfg.Params[name]
+	if !ok || v == nil {
+		return "", false
+	}
+	return *v, true
+}
+
+// location returns the location TIMESTAMP_LTZ values are converted to, the one of the session TIMEZONE parameter, or
+// nil for time.Local if KeepLocalTimezone is set or the time zone is unknown.
+func (sc *snowflakeConn) location() *time.Location {
+	if sc.cfg.KeepLocalTimezone {
+		return nil
+	}
+	tz, ok := sc.sessionParameter("timezone")
+	if !ok || tz == "" {
+		return nil
+	}
+	sc.stateMu.RLock()
+	loc := sc.sessionLocation
+	sc.stateMu.RUnlock()
+	if loc != nil && loc.String() == tz {
+		return loc
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		logger.WithContext(sc.ctx).Warningf("failed to load the session time zone. timezone: %v, err: %v", tz, err)
+		return nil
+	}
+	sc.stateMu.Lock()
+	sc.sessionLocation = loc
+	sc.stateMu.Unlock()
+	return loc
+}
+
+// isBinaryOutputBase64 returns true if the BINARY_OUTPUT_FORMAT of the session is BASE64 rather than HEX.
+func (sc *snowflakeConn) isBinaryOutputBase64() bool {
+	v, ok := sc.sessionParameter("binary_output_format")
+	return ok && strings.EqualFold(v, "BASE64")
+}
+
 func (sc *snowflakeConn) isClientSessionKeepAliveEnabled() bool {
-	v, ok := sc.cfg.Params[sessionClientSessionKeepAlive]
+	v, ok := sc.sessionParameter(sessionClientSessionKeepAlive)
 	if !ok {
 		return false
 	}
-	return strings.Compare(*v, "true") == 0
+	return strings.Compare(v, "true") == 0
 }
 
 func (sc *snowflakeConn) startHeartBeat() {
@@ -522,8 +622,8 @@ func (sc *snowflakeConn) getQueryResult(ctx context.Context, resultPath string)
 	headers["Content-Type"] = headerContentTypeApplicationJSON
 	headers["accept"] = headerAcceptTypeApplicationSnowflake
 	headers["User-Agent"] = userAgent
-	if serviceName, ok := sc.cfg.Params[serviceName]; ok {
-		headers["X-Snowflake-Service"] = *serviceName
+	if serviceName, ok := sc.sessionParameter(serviceName); ok {
+		headers["X-Snowflake-Service"] = serviceName
 	}
 	param := make(url.Values)
 	param.Add(requestIDKey, getOrGenerateRequestIDFromContext(ctx))
@@ -562,6 +662,11 @@ func populateChunkDownloader(ctx context.Context, sc *snowflakeConn, data execRe
 		FuncDownload:       downloadChunk,
 		FuncDownloadHelper: downloadChunkHelper,
 		FuncGet:            getChunk,
+		FuncGetResult:      getChunkResult,
+		QueryID:            data.QueryID,
+		Location:           sc.location(),
+		CivilDateTime:      sc.cfg.CivilDateTime,
+		BinaryBase64:       sc.isBinaryOutputBase64(),
 		RowSet: rowSetType{RowType: data.RowType,
 			JSON:         data.RowSet,
 			RowSetBase64: data.RowSetBase64,
@@ -1,8 +1,16 @@
 package gosnowflake
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -141,3 +149,189 @@ func TestCloseIgnoreSessionGone(t *testing.T) {
 		t.Error("Close should let go session gone error")
 	}
 }
+
+func TestLocation(t *testing.T) {
+	tz := "America/Los_Angeles"
+	sc := &snowflakeConn{
+		cfg: &Config{Params: map[string]*string{}},
+		ctx: context.Background(),
+	}
+	if loc := sc.location(); loc != nil {
+		t.Fatalf("expected no location without the TIMEZONE parameter, got: %v", loc)
+	}
+	sc.cfg.Params["timezone"] = &tz
+	loc := sc.location()
+	if loc == nil || loc.String() != tz {
+		t.Fatalf("expected location: %v, got: %v", tz, loc)
+	}
+	if sc.location() != loc {
+		t.Fatal("expected the cached location")
+	}
+	invalid := "Nowhere/Invalid"
+	sc.cfg.Params["timezone"] = &invalid
+	if loc = sc.location(); loc != nil {
+		t.Fatalf("expected no location for an invalid time zone, got: %v", loc)
+	}
+	sc.cfg.Params["timezone"] = &tz
+
+	// the location is read while a query updates the time zone
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if loc := sc.location(); loc == nil {
+					t.Error("expected a location")
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		for _, zone := range []string{"UTC", "Asia/Tokyo", tz} {
+			sc.populateSessionParameters([]nameValueParameter{{Name: "TIMEZONE", Value: zone}})
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	sc.cfg.KeepLocalTimezone = true
+	if loc = sc.location(); loc != nil {
+		t.Fatalf("expected no location with KeepLocalTimezone, got: %v", loc)
+	}
+}
+
+func TestExecBindBytes(t *testing.T) {
+	var req execRequest
+	sr := &snowflakeRestful{
+		FuncPostQuery: func(_ context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string, body []byte, _ time.Duration, _ string) (*execResponse, error) {
+			if err := json.Unmarshal(body, &req); err != nil {
+				return nil, err
+			}
+			return &execResponse{Code: "0", Success: true}, nil
+		},
+	}
+	sc := &snowflakeConn{
+		cfg:  &Config{Params: map[string]*string{}},
+		rest: sr,
+	}
+	bindings := []driver.NamedValue{
+		{Ordinal: 1, Value: []byte("abc")},
+		{Ordinal: 2, Value: DataTypeText},
+		{Ordinal: 3, Value: []byte("abc")},
+	}
+	if _, err := sc.exec(context.Background(), "INSERT INTO t VALUES (?, ?)", false, false, bindings); err != nil {
+		t.Fatalf("failed to exec. err: %v", err)
+	}
+	expected := map[string]execBindParameter{
+		"1": {Type: "BINARY", Value: "616263"},
+		"2": {Type: "TEXT", Value: "abc"},
+	}
+	if !reflect.DeepEqual(req.Bindings, expected) {
+		t.Fatalf("unexpected bindings. expected: %v, got: %v", expected, req.Bindings)
+	}
+}
+
+func TestExecBindFlagScope(t *testing.T) {
+	var req execRequest
+	sr := &snowflakeRestful{
+		FuncPostQuery: func(_ context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string, body []byte, _ time.Duration, _ string) (*execResponse, error) {
+			if err := json.Unmarshal(body, &req); err != nil {
+				return nil, err
+			}
+			return &execResponse{Code: "0", Success: true}, nil
+		},
+	}
+	sc := &snowflakeConn{
+		cfg:  &Config{Params: map[string]*string{}},
+		rest: sr,
+	}
+	tm := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	copied := append([]byte{}, DataTypeText...)
+	bindings := []driver.NamedValue{
+		{Ordinal: 1, Value: DataTypeText},
+		{Ordinal: 2, Value: []byte("abc")},
+		{Ordinal: 3, Value: tm},
+		{Ordinal: 4, Value: []byte("abc")},
+		{Ordinal: 5, Value: DataTypeTimestampLtz},
+		{Ordinal: 6, Value: DataTypeBinary},
+		{Ordinal: 7, Value: []byte("abc")},
+		{Ordinal: 8, Value: tm},
+		{Ordinal: 9, Value: copied},
+		{Ordinal: 10, Value: []byte("abc")},
+	}
+	if _, err := sc.exec(context.Background(), "INSERT INTO t VALUES (?, ?, ?, ?, ?, ?, ?)", false, false,
+		bindings); err != nil {
+		t.Fatalf("failed to exec. err: %v", err)
+	}
+	nanos := strconv.FormatInt(tm.UnixNano(), 10)
+	expected := map[string]execBindParameter{
+		"1": {Type: "TEXT", Value: "abc"},
+		"2": {Type: "TIMESTAMP_NTZ", Value: nanos},
+		"3": {Type: "BINARY", Value: "616263"},
+		"4": {Type: "BINARY", Value: "616263"},
+		"5": {Type: "TIMESTAMP_LTZ", Value: nanos},
+		"6": {Type: "BINARY", Value: "02"},
+		"7": {Type: "BINARY", Value: "616263"},
+	}
+	if !reflect.DeepEqual(req.Bindings, expected) {
+		t.Fatalf("unexpected bindings. expected: %v, got: %v", expected, req.Bindings)
+	}
+}
+
+func TestBindOneByteBinary(t *testing.T) {
+	var bindings map[string]execBindParameter
+	// echo the bound values back as the row
+	echo := func(_ string, b map[string]execBindParameter) (*execResponseData, error) {
+		bindings = b
+		var rowType []execResponseRowType
+		var row []*string
+		for i := 1; i <= len(b); i++ {
+			p := b[strconv.Itoa(i)]
+			v, _ := p.Value.(string)
+			rowType = append(rowType, execResponseRowType{Name: "C" + strconv.Itoa(i), Type: strings.ToLower(p.Type)})
+			row = append(row, &v)
+		}
+		return newFakeResult(rowType, [][]*string{row}), nil
+	}
+	db := sql.OpenDB(&fakeConnector{handler: echo})
+	defer db.Close()
+
+	var b1, b2 []byte
+	var text string
+	if err := db.QueryRow("SELECT ?, ?, ?", []byte{1}, "x", []byte{binaryType}).Scan(&b1, &text, &b2); err != nil {
+		t.Fatalf("failed to query. err: %v", err)
+	}
+	if !bytes.Equal(b1, []byte{1}) || text != "x" || !bytes.Equal(b2, []byte{binaryType}) {
+		t.Fatalf("unexpected values: %v, %v, %v", b1, text, b2)
+	}
+	expected := map[string]execBindParameter{
+		"1": {Type: "BINARY", Value: "01"},
+		"2": {Type: "TEXT", Value: "x"},
+		"3": {Type: "BINARY", Value: "0a"},
+	}
+	if !reflect.DeepEqual(bindings, expected) {
+		t.Fatalf("unexpected bindings. expected: %v, got: %v", expected, bindings)
+	}
+}
+
+func TestIsBinaryOutputBase64(t *testing.T) {
+	sc := &snowflakeConn{cfg: &Config{Params: map[string]*string{}}}
+	if sc.isBinaryOutputBase64() {
+		t.Fatal("HEX should be the default")
+	}
+	for _, format := range []string{"HEX", "BASE64", "base64"} {
+		f := format
+		sc.cfg.Params["binary_output_format"] = &f
+		if sc.isBinaryOutputBase64() != strings.EqualFold(format, "BASE64") {
+			t.Errorf("unexpected result for %v", format)
+		}
+	}
+}
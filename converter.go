@@ -3,7 +3,9 @@
 package gosnowflake
 
 import (
+	"bytes"
 	"database/sql/driver"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"github.com/apache/arrow/go/arrow"
@@ -29,21 +31,21 @@ func goTypeToSnowflake(v driver.Value, tsmode string) string {
 	case string:
 		return "TEXT"
 	case []byte:
-		if tsmode == "BINARY" {
-			return "BINARY" // may be redundant but ensures BINARY type
+		if tsmode == "BINARY" || tsmode == "TEXT" {
+			return tsmode
 		}
-		if v == nil || len(v) != 1 {
-			return "TEXT" // invalid byte array. won't take as BINARY
+		if _, err := dataTypeMode(v); err == nil {
+			return "CHANGE_TYPE"
 		}
-		_, err := dataTypeMode(v)
-		if err != nil {
-			return "TEXT" // not supported dataType
-		}
-		return "CHANGE_TYPE"
-	case []int, []int64, []float64, []bool, []string:
+		return "BINARY"
+	case []int, []int64, []float64, []bool, []string, *columnArray:
 		return "ARRAY"
 	case time.Time:
 		return tsmode
+	case Date:
+		return "DATE"
+	case TimeOfDay:
+		return "TIME"
 	}
 	return "TEXT"
 }
@@ -58,7 +60,7 @@ func snowflakeTypeToGo(dbtype string, scale int64) reflect.Type {
 		return reflect.TypeOf(float64(0))
 	case "real":
 		return reflect.TypeOf(float64(0))
-	case "text", "variant", "object", "array":
+	case "text", "variant", "object", "array", "geography", "geometry":
 		return reflect.TypeOf("")
 	case "date", "time", "timestamp_ltz", "timestamp_ntz", "timestamp_tz":
 		return reflect.TypeOf(time.Now())
@@ -101,11 +103,21 @@ func valueToString(v driver.Value, tsmode string) (*string, error) {
 				s := hex.EncodeToString(bd)
 				return &s, nil
 			}
+			s := string(bd)
+			return &s, nil
 		}
 		// TODO: is this good enough?
 		s := v1.String()
 		return &s, nil
 	case reflect.Struct:
+		switch cv := v.(type) {
+		case Date:
+			s := strconv.FormatInt(cv.In(time.UTC).Unix()*1000, 10)
+			return &s, nil
+		case TimeOfDay:
+			s := strconv.FormatInt(cv.Nanos(), 10)
+			return &s, nil
+		}
 		if tm, ok := v.(time.Time); ok {
 			switch tsmode {
 			case "DATE":
@@ -118,11 +130,11 @@ func valueToString(v driver.Value, tsmode string) (*string, error) {
 					(tm.Hour()*3600+tm.Minute()*60+tm.Second())*1e9+tm.Nanosecond())
 				return &s, nil
 			case "TIMESTAMP_NTZ", "TIMESTAMP_LTZ":
-				s := fmt.Sprintf("%d", tm.UnixNano())
+				s := unixNanoString(tm)
 				return &s, nil
 			case "TIMESTAMP_TZ":
 				_, offset := tm.Zone()
-				s := fmt.Sprintf("%v %v", tm.UnixNano(), offset/60+1440)
+				s := fmt.Sprintf("%v %v", unixNanoString(tm), offset/60+1440)
 				return &s, nil
 			}
 		}
@@ -130,6 +142,45 @@ func valueToString(v driver.Value, tsmode string) (*string, error) {
 	return nil, fmt.Errorf("unsupported type: %v", v1.Kind())
 }
 
+// unixNanoString returns the number of nanoseconds since the epoch of tm, which exceeds int64 before 1678 and after
+// 2262.
+func unixNanoString(tm time.Time) string {
+	n := new(big.Int).Mul(big.NewInt(tm.Unix()), big.NewInt(1e9))
+	return n.Add(n, big.NewInt(int64(tm.Nanosecond()))).String()
+}
+
+// extractScaledTimestamp splits value, the number of 10^-scale seconds since the epoch, into seconds and nanoseconds.
+func extractScaledTimestamp(value int64, scale int64) (sec int64, nsec int64) {
+	p := int64(math.Pow10(int(scale)))
+	return value / p, value % p * int64(math.Pow10(9-int(scale)))
+}
+
+// dateToValue returns the DATE value of the days since the epoch, a Date if civil is true or midnight UTC otherwise.
+func dateToValue(days int64, civil bool) snowflakeValue {
+	t := time.Unix(days*86400, 0).UTC()
+	if civil {
+		return DateOf(t)
+	}
+	return t
+}
+
+// timeToValue returns the TIME value of the nanoseconds since midnight, a TimeOfDay if civil is true or the time on
+// the zero date otherwise.
+func timeToValue(nanos int64, civil bool) snowflakeValue {
+	if civil {
+		return timeOfDayOfNanos(nanos)
+	}
+	return time.Time{}.Add(time.Duration(nanos))
+}
+
+// ltzTime returns the TIMESTAMP_LTZ value in loc, or in time.Local if loc is nil.
+func ltzTime(sec int64, nsec int64, loc *time.Location) time.Time {
+	if loc == nil {
+		return time.Unix(sec, nsec)
+	}
+	return time.Unix(sec, nsec).In(loc)
+}
+
 // extractTimestamp extracts the internal timestamp data to epoch time in seconds and milliseconds
 func extractTimestamp(srcValue *string) (sec int64, nsec int64, err error) {
 	logger.Debugf("SRC: %v", srcValue)
@@ -156,14 +207,20 @@ func extractTimestamp(srcValue *string) (sec int64, nsec int64, err error) {
 		if err != nil {
 			return 0, 0, err
 		}
+		if (*srcValue)[0] == '-' {
+			// the fraction of a negative value is negative as well, e.g., -1.5 is -1 second and -0.5 seconds.
+			nsec = -nsec
+		}
 	}
 	logger.Infof("sec: %v, nsec: %v", sec, nsec)
 	return sec, nsec, nil
 }
 
 // stringToValue converts a pointer of string data to an arbitrary golang variable. This is mainly used in fetching
-// data.
-func stringToValue(dest *driver.Value, srcColumnMeta execResponseRowType, srcValue *string) error {
+// data. TIMESTAMP_LTZ values are converted to loc, or to time.Local if loc is nil, and DATE and TIME values to Date and
+// TimeOfDay if civil is true. BINARY values are decoded from base64 if binaryBase64 is true or from hex otherwise.
+func stringToValue(dest *driver.Value, srcColumnMeta execResponseRowType, srcValue *string, loc *time.Location, civil bool,
+	binaryBase64 bool) error {
 	if srcValue == nil {
 		logger.Debugf("snowflake data type: %v, raw value: nil", srcColumnMeta.Type)
 		*dest = nil
@@ -179,15 +236,14 @@ func stringToValue(dest *driver.Value, srcColumnMeta execResponseRowType, srcVal
 		if err != nil {
 			return err
 		}
-		*dest = time.Unix(v*86400, 0).UTC()
+		*dest = dateToValue(v, civil)
 		return nil
 	case "time":
 		sec, nsec, err := extractTimestamp(srcValue)
 		if err != nil {
 			return err
 		}
-		t0 := time.Time{}
-		*dest = t0.Add(time.Duration(sec*1e9 + nsec))
+		*dest = timeToValue(sec*1e9+nsec, civil)
 		return nil
 	case "timestamp_ntz":
 		sec, nsec, err := extractTimestamp(srcValue)
@@ -201,7 +257,7 @@ func stringToValue(dest *driver.Value, srcColumnMeta execResponseRowType, srcVal
 		if err != nil {
 			return err
 		}
-		*dest = time.Unix(sec, nsec)
+		*dest = ltzTime(sec, nsec, loc)
 		return nil
 	case "timestamp_tz":
 		logger.Debugf("tz: %v", *srcValue)
@@ -231,48 +287,195 @@ func stringToValue(dest *driver.Value, srcColumnMeta execResponseRowType, srcVal
 		*dest = tt.In(loc)
 		return nil
 	case "binary":
-		b, err := hex.DecodeString(*srcValue)
+		b, err := binaryToValue([]byte(*srcValue), binaryBase64)
 		if err != nil {
-			return &SnowflakeError{
-				Number:   ErrInvalidBinaryHexForm,
-				SQLState: SQLStateNumericValueOutOfRange,
-				Message:  err.Error(),
-			}
+			return err
 		}
 		*dest = b
 		return nil
+	case "geography", "geometry":
+		*dest = geoToValue(*srcValue)
+		return nil
 	}
 	*dest = *srcValue
 	return nil
 }
 
-func arrayToString(v driver.Value) (string, []string) {
+// bytesToValue converts the unescaped bytes of a JSON result cell to the same value stringToValue returns for it,
+// without intermediate strings or per value logging. This is used by the typed chunk decoder.
+func bytesToValue(srcColumnMeta *execResponseRowType, srcValue []byte, loc *time.Location, civil bool,
+	binaryBase64 bool) (snowflakeValue, error) {
+	switch srcColumnMeta.Type {
+	case "date":
+		v, ok := parseIntBytes(srcValue)
+		if !ok {
+			return nil, fmt.Errorf("invalid date value: %q", srcValue)
+		}
+		return dateToValue(v, civil), nil
+	case "time":
+		sec, nsec, ok := parseTimestampBytes(srcValue)
+		if !ok {
+			return nil, fmt.Errorf("invalid time value: %q", srcValue)
+		}
+		return timeToValue(sec*1e9+nsec, civil), nil
+	case "timestamp_ntz":
+		sec, nsec, ok := parseTimestampBytes(srcValue)
+		if !ok {
+			return nil, fmt.Errorf("invalid timestamp_ntz value: %q", srcValue)
+		}
+		return time.Unix(sec, nsec).UTC(), nil
+	case "timestamp_ltz":
+		sec, nsec, ok := parseTimestampBytes(srcValue)
+		if !ok {
+			return nil, fmt.Errorf("invalid timestamp_ltz value: %q", srcValue)
+		}
+		return ltzTime(sec, nsec, loc), nil
+	case "timestamp_tz":
+		i := bytes.IndexByte(srcValue, ' ')
+		if i < 0 || bytes.IndexByte(srcValue[i+1:], ' ') >= 0 {
+			return nil, &SnowflakeError{
+				Number:   ErrInvalidTimestampTz,
+				SQLState: SQLStateInvalidDataTimeFormat,
+				Message:  fmt.Sprintf("invalid TIMESTAMP_TZ data. The value doesn't consist of two numeric values separated by a space: %s", srcValue),
+			}
+		}
+		sec, nsec, ok := parseTimestampBytes(srcValue[:i])
+		if !ok {
+			return nil, fmt.Errorf("invalid timestamp_tz value: %q", srcValue)
+		}
+		offset, ok := parseIntBytes(srcValue[i+1:])
+		if !ok {
+			return nil, &SnowflakeError{
+				Number:   ErrInvalidTimestampTz,
+				SQLState: SQLStateInvalidDataTimeFormat,
+				Message:  fmt.Sprintf("invalid TIMESTAMP_TZ data. The offset value is not integer: %s", srcValue[i+1:]),
+			}
+		}
+		return time.Unix(sec, nsec).In(Location(int(offset) - 1440)), nil
+	case "binary":
+		return binaryToValue(srcValue, binaryBase64)
+	case "geography", "geometry":
+		return geoToValue(string(srcValue)), nil
+	}
+	return string(srcValue), nil
+}
+
+// binaryToValue decodes a BINARY value of a JSON result set in the BINARY_OUTPUT_FORMAT of the session, base64 if
+// binaryBase64 is true or hex otherwise.
+func binaryToValue(src []byte, binaryBase64 bool) ([]byte, error) {
+	if binaryBase64 {
+		b := make([]byte, base64.StdEncoding.DecodedLen(len(src)))
+		n, err := base64.StdEncoding.Decode(b, src)
+		if err != nil {
+			return nil, &SnowflakeError{
+				Number:   ErrInvalidBinaryBase64Form,
+				SQLState: SQLStateNumericValueOutOfRange,
+				Message:  err.Error(),
+			}
+		}
+		return b[:n], nil
+	}
+	b := make([]byte, hex.DecodedLen(len(src)))
+	if _, err := hex.Decode(b, src); err != nil {
+		return nil, &SnowflakeError{
+			Number:   ErrInvalidBinaryHexForm,
+			SQLState: SQLStateNumericValueOutOfRange,
+			Message:  err.Error(),
+		}
+	}
+	return b, nil
+}
+
+// parseIntBytes parses a base 10 integer with an optional sign. Values of more than 18 digits are rejected, which
+// is far beyond any epoch day or second the server sends.
+func parseIntBytes(src []byte) (int64, bool) {
+	neg := false
+	if len(src) > 0 && (src[0] == '-' || src[0] == '+') {
+		neg = src[0] == '-'
+		src = src[1:]
+	}
+	if len(src) == 0 || len(src) > 18 {
+		return 0, false
+	}
+	var v int64
+	for _, c := range src {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		v = v*10 + int64(c-'0')
+	}
+	if neg {
+		v = -v
+	}
+	return v, true
+}
+
+// parseTimestampBytes is the allocation free counterpart of extractTimestamp.
+func parseTimestampBytes(src []byte) (sec int64, nsec int64, ok bool) {
+	i := bytes.IndexByte(src, '.')
+	if i < 0 {
+		sec, ok = parseIntBytes(src)
+		return sec, 0, ok
+	}
+	if sec, ok = parseIntBytes(src[:i]); !ok {
+		return 0, 0, false
+	}
+	fraction := src[i+1:]
+	if len(fraction) > 9 {
+		return 0, 0, false
+	}
+	for _, c := range fraction {
+		if c < '0' || c > '9' {
+			return 0, 0, false
+		}
+		nsec = nsec*10 + int64(c-'0')
+	}
+	for n := len(fraction); n < 9; n++ {
+		nsec *= 10
+	}
+	if src[0] == '-' {
+		nsec = -nsec
+	}
+	return sec, nsec, true
+}
+
+// arrayToString returns the type and the values of an array binding. nil values bind NULL.
+func arrayToString(v driver.Value) (string, []*string) {
 	var t string
-	var arr []string
+	var arr []*string
 	switch a := v.(type) {
 	case []int:
 		t = "FIXED"
 		for _, x := range a {
-			arr = append(arr, strconv.Itoa(x))
+			s := strconv.Itoa(x)
+			arr = append(arr, &s)
 		}
 	case []int64:
 		t = "FIXED"
 		for _, x := range a {
-			arr = append(arr, strconv.Itoa(int(x)))
+			s := strconv.FormatInt(x, 10)
+			arr = append(arr, &s)
 		}
 	case []float64:
 		t = "REAL"
 		for _, x := range a {
-			arr = append(arr, fmt.Sprintf("%g", x))
+			s := fmt.Sprintf("%g", x)
+			arr = append(arr, &s)
 		}
 	case []bool:
 		t = "BOOLEAN"
 		for _, x := range a {
-			arr = append(arr, strconv.FormatBool(x))
+			s := strconv.FormatBool(x)
+			arr = append(arr, &s)
 		}
 	case []string:
 		t = "TEXT"
-		arr = a
+		for i := range a {
+			arr = append(arr, &a[i])
+		}
+	case *columnArray:
+		t = a.typ
+		arr = a.values
 	}
 	return t, arr
 }
@@ -324,8 +527,9 @@ func stringFloatToDecimal(src string, scale int64) (decimal128.Num, bool) {
 }
 
 // Arrow Interface (Column) converter. This is called when Arrow chunks are downloaded to convert to the corresponding
-// row type.
-func arrowToValue(destcol *[]snowflakeValue, srcColumnMeta execResponseRowType, srcValue array.Interface) error {
+// row type. TIMESTAMP_LTZ values are converted to loc, or to time.Local if loc is nil, and DATE and TIME values to Date
+// and TimeOfDay if civil is true.
+func arrowToValue(destcol *[]snowflakeValue, srcColumnMeta execResponseRowType, srcValue array.Interface, loc *time.Location, civil bool) error {
 	data := srcValue.Data()
 	var err error
 	if len(*destcol) != srcValue.Data().Len() {
@@ -423,11 +627,27 @@ func arrowToValue(destcol *[]snowflakeValue, srcColumnMeta execResponseRowType,
 			}
 		}
 		return err
+	case "GEOGRAPHY", "GEOMETRY":
+		if srcValue.DataType().ID() == arrow.BINARY {
+			binaryData := array.NewBinaryData(data)
+			for i := range *destcol {
+				if !srcValue.IsNull(i) {
+					(*destcol)[i] = binaryData.Value(i)
+				}
+			}
+		} else {
+			strings := array.NewStringData(data)
+			for i := range *destcol {
+				if !srcValue.IsNull(i) {
+					(*destcol)[i] = geoToValue(strings.Value(i))
+				}
+			}
+		}
+		return err
 	case "DATE":
 		for i, date32 := range array.NewDate32Data(data).Date32Values() {
 			if !srcValue.IsNull(i) {
-				t0 := time.Unix(int64(date32)*86400, 0).UTC()
-				(*destcol)[i] = t0
+				(*destcol)[i] = dateToValue(int64(date32), civil)
 			}
 		}
 		return err
@@ -435,15 +655,13 @@ func arrowToValue(destcol *[]snowflakeValue, srcColumnMeta execResponseRowType,
 		if srcValue.DataType().ID() == arrow.INT64 {
 			for i, int64 := range array.NewInt64Data(data).Int64Values() {
 				if !srcValue.IsNull(i) {
-					t0 := time.Time{}
-					(*destcol)[i] = t0.Add(time.Duration(int64))
+					(*destcol)[i] = timeToValue(int64, civil)
 				}
 			}
 		} else {
 			for i, int32 := range array.NewInt32Data(data).Int32Values() {
 				if !srcValue.IsNull(i) {
-					t0 := time.Time{}
-					(*destcol)[i] = t0.Add(time.Duration(int64(int32) * int64(math.Pow10(9-int(srcColumnMeta.Scale)))))
+					(*destcol)[i] = timeToValue(int64(int32)*int64(math.Pow10(9-int(srcColumnMeta.Scale))), civil)
 				}
 			}
 		}
@@ -461,7 +679,7 @@ func arrowToValue(destcol *[]snowflakeValue, srcColumnMeta execResponseRowType,
 		} else {
 			for i, t := range array.NewInt64Data(data).Int64Values() {
 				if !srcValue.IsNull(i) {
-					(*destcol)[i] = time.Unix(0, t*int64(math.Pow10(9-int(srcColumnMeta.Scale)))).UTC()
+					(*destcol)[i] = time.Unix(extractScaledTimestamp(t, srcColumnMeta.Scale)).UTC()
 				}
 			}
 		}
@@ -473,15 +691,14 @@ func arrowToValue(destcol *[]snowflakeValue, srcColumnMeta execResponseRowType,
 			fraction := array.NewInt32Data(structData.Field(1).Data()).Int32Values()
 			for i := range *destcol {
 				if !srcValue.IsNull(i) {
-					(*destcol)[i] = time.Unix(epoch[i], int64(fraction[i]))
+					(*destcol)[i] = ltzTime(epoch[i], int64(fraction[i]), loc)
 				}
 			}
 		} else {
 			for i, t := range array.NewInt64Data(data).Int64Values() {
 				if !srcValue.IsNull(i) {
-					q := t / int64(math.Pow10(int(srcColumnMeta.Scale)))
-					r := t % int64(math.Pow10(int(srcColumnMeta.Scale)))
-					(*destcol)[i] = time.Unix(q, r)
+					sec, nsec := extractScaledTimestamp(t, srcColumnMeta.Scale)
+					(*destcol)[i] = ltzTime(sec, nsec, loc)
 				}
 			}
 		}
@@ -494,7 +711,7 @@ func arrowToValue(destcol *[]snowflakeValue, srcColumnMeta execResponseRowType,
 			for i := range *destcol {
 				if !srcValue.IsNull(i) {
 					loc := Location(int(timezone[i]) - 1440)
-					tt := time.Unix(epoch[i], 0)
+					tt := time.Unix(extractScaledTimestamp(epoch[i], srcColumnMeta.Scale))
 					(*destcol)[i] = tt.In(loc)
 				}
 			}
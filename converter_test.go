@@ -3,11 +3,13 @@
 package gosnowflake
 
 import (
+	"bytes"
 	"database/sql/driver"
 	"fmt"
 	"github.com/apache/arrow/go/arrow"
 	"github.com/apache/arrow/go/arrow/array"
 	"github.com/apache/arrow/go/arrow/memory"
+	"math"
 	"math/big"
 	"math/cmplx"
 	"reflect"
@@ -37,6 +39,13 @@ func TestGoTypeToSnowflake(t *testing.T) {
 		{in: time.Now(), tmode: "TIMESTAMP_TZ", out: "TIMESTAMP_TZ"},
 		{in: time.Now(), tmode: "TIMESTAMP_LTZ", out: "TIMESTAMP_LTZ"},
 		{in: []byte{1, 2, 3}, tmode: "BINARY", out: "BINARY"},
+		{in: []byte{1, 2, 3}, tmode: "TIMESTAMP_NTZ", out: "BINARY"},
+		{in: []byte{100}, tmode: "TIMESTAMP_NTZ", out: "BINARY"},
+		{in: []byte{1}, tmode: "TIMESTAMP_NTZ", out: "BINARY"},
+		{in: []byte{binaryType}, tmode: "", out: "BINARY"},
+		{in: []byte{}, tmode: "TIMESTAMP_NTZ", out: "BINARY"},
+		{in: []byte{1, 2, 3}, tmode: "TEXT", out: "TEXT"},
+		{in: DataTypeText, tmode: "", out: "CHANGE_TYPE"},
 		// negative
 		{in: 123, tmode: "", out: "TEXT"},
 		{in: int8(12), tmode: "", out: "TEXT"},
@@ -44,7 +53,6 @@ func TestGoTypeToSnowflake(t *testing.T) {
 		{in: uint(456), tmode: "", out: "TEXT"},
 		{in: uint8(12), tmode: "", out: "TEXT"},
 		{in: uint64(456), tmode: "", out: "TEXT"},
-		{in: []byte{100}, tmode: "", out: "TEXT"},
 	}
 	for _, test := range testcases {
 		a := goTypeToSnowflake(test.in, test.tmode)
@@ -76,6 +84,8 @@ func TestSnowflakeTypeToGo(t *testing.T) {
 		{in: "array", scale: 0, out: reflect.TypeOf("")},
 		{in: "binary", scale: 0, out: reflect.TypeOf([]byte{})},
 		{in: "boolean", scale: 0, out: reflect.TypeOf(true)},
+		{in: "geography", scale: 0, out: reflect.TypeOf("")},
+		{in: "geometry", scale: 0, out: reflect.TypeOf("")},
 	}
 	for _, test := range testcases {
 		a := snowflakeTypeToGo(test.in, test.scale)
@@ -113,6 +123,53 @@ func TestValueToString(t *testing.T) {
 	} else if *s != expectedUnixTime {
 		t.Errorf("expected '%v', got '%v'", expectedUnixTime, *s)
 	}
+
+	if s, err := valueToString([]byte("abc"), "BINARY"); err != nil {
+		t.Error("unexpected error")
+	} else if *s != "616263" {
+		t.Errorf("expected '%v', got '%v'", "616263", *s)
+	}
+	if s, err := valueToString([]byte("abc"), "TEXT"); err != nil {
+		t.Error("unexpected error")
+	} else if *s != "abc" {
+		t.Errorf("expected '%v', got '%v'", "abc", *s)
+	}
+}
+
+func TestBinaryToValue(t *testing.T) {
+	for _, tc := range []struct {
+		in           string
+		binaryBase64 bool
+		out          []byte
+		errNumber    int
+	}{
+		{in: "616263", out: []byte("abc")},
+		{in: "YWJj", binaryBase64: true, out: []byte("abc")},
+		{in: "YQ==", binaryBase64: true, out: []byte("a")},
+		{in: "", binaryBase64: true, out: []byte{}},
+		{in: "YWJj", errNumber: ErrInvalidBinaryHexForm},
+		{in: "616263!", binaryBase64: true, errNumber: ErrInvalidBinaryBase64Form},
+	} {
+		meta := execResponseRowType{Type: "binary"}
+		var dest driver.Value
+		src := tc.in
+		err := stringToValue(&dest, meta, &src, nil, false, tc.binaryBase64)
+		v, err2 := bytesToValue(&meta, []byte(tc.in), nil, false, tc.binaryBase64)
+		if tc.errNumber != 0 {
+			for _, e := range []error{err, err2} {
+				if se, ok := e.(*SnowflakeError); !ok || se.Number != tc.errNumber {
+					t.Errorf("%v. expected error: %v, got: %v", tc.in, tc.errNumber, e)
+				}
+			}
+			continue
+		}
+		if err != nil || err2 != nil {
+			t.Fatalf("%v. unexpected error: %v, %v", tc.in, err, err2)
+		}
+		if !bytes.Equal(dest.([]byte), tc.out) || !bytes.Equal(v.([]byte), tc.out) {
+			t.Errorf("%v. expected: %v, got: %v, %v", tc.in, tc.out, dest, v)
+		}
+	}
 }
 
 func TestExtractTimestamp(t *testing.T) {
@@ -148,7 +205,7 @@ func TestStringToValue(t *testing.T) {
 		rowType = &execResponseRowType{
 			Type: tt,
 		}
-		err = stringToValue(&dest, *rowType, &source)
+		err = stringToValue(&dest, *rowType, &source, nil, false, false)
 		if err == nil {
 			t.Errorf("should raise error. type: %v, value:%v", tt, source)
 		}
@@ -168,7 +225,7 @@ func TestStringToValue(t *testing.T) {
 			rowType = &execResponseRowType{
 				Type: tt,
 			}
-			err = stringToValue(&dest, *rowType, &ss)
+			err = stringToValue(&dest, *rowType, &ss, nil, false, false)
 			if err == nil {
 				t.Errorf("should raise error. type: %v, value:%v", tt, source)
 			}
@@ -176,7 +233,7 @@ func TestStringToValue(t *testing.T) {
 	}
 
 	src := "1549491451.123456789"
-	if err = stringToValue(&dest, execResponseRowType{Type: "timestamp_ltz"}, &src); err != nil {
+	if err = stringToValue(&dest, execResponseRowType{Type: "timestamp_ltz"}, &src, nil, false, false); err != nil {
 		t.Errorf("unexpected error: %v", err)
 	} else if ts, ok := dest.(time.Time); !ok {
 		t.Errorf("expected type: 'time.Time', got '%v'", reflect.TypeOf(dest))
@@ -185,6 +242,190 @@ func TestStringToValue(t *testing.T) {
 	}
 }
 
+func TestTimestampLtzLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load location. err: %v", err)
+	}
+	expected := time.Unix(1549491451, 123456789)
+	meta := execResponseRowType{Type: "timestamp_ltz", Scale: 9}
+	check := func(name string, v interface{}) {
+		ts, ok := v.(time.Time)
+		if !ok {
+			t.Fatalf("%v: expected type: 'time.Time', got '%v'", name, reflect.TypeOf(v))
+		}
+		if !ts.Equal(expected) || ts.Location() != loc {
+			t.Fatalf("%v: expected: %v, got: %v", name, expected.In(loc), ts)
+		}
+	}
+
+	var dest driver.Value
+	src := "1549491451.123456789"
+	if err = stringToValue(&dest, meta, &src, loc, false, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check("stringToValue", dest)
+
+	v, err := bytesToValue(&meta, []byte(src), loc, false, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check("bytesToValue", v)
+
+	b := array.NewInt64Builder(memory.NewGoAllocator())
+	b.Append(expected.UnixNano())
+	arr := b.NewArray()
+	defer arr.Release()
+	values := make([]snowflakeValue, 1)
+	if err = arrowToValue(&values, meta, arr, loc, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check("arrowToValue", values[0])
+
+	if err = stringToValue(&dest, meta, &src, nil, false, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.(time.Time).Location() != time.Local {
+		t.Fatalf("expected time.Local without location, got: %v", dest.(time.Time).Location())
+	}
+}
+
+var (
+	maxTimestamp = time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC)
+	minTimestamp = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestStringToValueTimestampExtremes(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		out time.Time
+	}{
+		{in: "253402300799.999999999", out: maxTimestamp},
+		{in: "-62135596800", out: minTimestamp},
+		{in: "-62135596799.999999999", out: minTimestamp.Add(time.Nanosecond)},
+		{in: "-1.5", out: time.Unix(-2, 500000000)},
+		{in: "-0.000000001", out: time.Unix(-1, 999999999)},
+		{in: "9223372036.854775808", out: time.Unix(9223372036, 854775808)},
+	} {
+		for _, typ := range []string{"timestamp_ntz", "timestamp_ltz"} {
+			meta := execResponseRowType{Type: typ, Scale: 9}
+			var dest driver.Value
+			src := tc.in
+			if err := stringToValue(&dest, meta, &src, time.UTC, false, false); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !dest.(time.Time).Equal(tc.out) {
+				t.Errorf("stringToValue: %v, %v. expected: %v, got: %v", typ, tc.in, tc.out, dest)
+			}
+			v, err := bytesToValue(&meta, []byte(tc.in), time.UTC, false, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !v.(time.Time).Equal(tc.out) {
+				t.Errorf("bytesToValue: %v, %v. expected: %v, got: %v", typ, tc.in, tc.out, v)
+			}
+		}
+		var dest driver.Value
+		src := tc.in + " 1440"
+		if err := stringToValue(&dest, execResponseRowType{Type: "timestamp_tz"}, &src, nil, false, false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !dest.(time.Time).Equal(tc.out) {
+			t.Errorf("stringToValue: timestamp_tz, %v. expected: %v, got: %v", tc.in, tc.out, dest)
+		}
+	}
+}
+
+func TestArrowToValueTimestampExtremes(t *testing.T) {
+	pool := memory.NewGoAllocator()
+	// the server sends a single int64 up to scale 7 and an epoch and fraction struct beyond
+	for scale := int64(0); scale <= 7; scale++ {
+		p := int64(math.Pow10(int(scale)))
+		outs := []time.Time{minTimestamp, time.Unix(-2, 500000000), maxTimestamp.Truncate(time.Duration(1e9 / p))}
+		values := []int64{minTimestamp.Unix() * p, -15 * p / 10, maxTimestamp.Unix()*p + p - 1}
+		if scale == 0 {
+			outs[1], values[1] = time.Unix(-2, 0), -2
+		}
+		for _, typ := range []string{"timestamp_ntz", "timestamp_ltz"} {
+			b := array.NewInt64Builder(pool)
+			b.AppendValues(values, nil)
+			arr := b.NewArray()
+			dest := make([]snowflakeValue, len(values))
+			if err := arrowToValue(&dest, execResponseRowType{Type: typ, Scale: scale}, arr, time.UTC, false); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, out := range outs {
+				if !dest[i].(time.Time).Equal(out) {
+					t.Errorf("%v, scale: %v, value: %v. expected: %v, got: %v", typ, scale, values[i], out, dest[i])
+				}
+			}
+			arr.Release()
+			b.Release()
+		}
+	}
+
+	ntzStruct := arrow.StructOf(arrow.Field{Name: "epoch", Type: &arrow.Int64Type{}},
+		arrow.Field{Name: "fraction", Type: &arrow.Int32Type{}})
+	nb := array.NewStructBuilder(pool, ntzStruct)
+	defer nb.Release()
+	nb.AppendValues([]bool{true, true})
+	nb.FieldBuilder(0).(*array.Int64Builder).AppendValues([]int64{minTimestamp.Unix(), maxTimestamp.Unix()}, nil)
+	nb.FieldBuilder(1).(*array.Int32Builder).AppendValues([]int32{0, 999999999}, nil)
+	ntzArr := nb.NewArray()
+	defer ntzArr.Release()
+	for _, typ := range []string{"timestamp_ntz", "timestamp_ltz"} {
+		dest := make([]snowflakeValue, 2)
+		if err := arrowToValue(&dest, execResponseRowType{Type: typ, Scale: 9}, ntzArr, time.UTC, false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for i, out := range []time.Time{minTimestamp, maxTimestamp} {
+			if !dest[i].(time.Time).Equal(out) {
+				t.Errorf("%v. expected: %v, got: %v", typ, out, dest[i])
+			}
+		}
+	}
+
+	tzStruct := arrow.StructOf(arrow.Field{Name: "epoch", Type: &arrow.Int64Type{}},
+		arrow.Field{Name: "timezone", Type: &arrow.Int32Type{}})
+	sb := array.NewStructBuilder(pool, tzStruct)
+	defer sb.Release()
+	sb.AppendValues([]bool{true, true})
+	sb.FieldBuilder(0).(*array.Int64Builder).AppendValues([]int64{minTimestamp.Unix() * 1000, maxTimestamp.Unix()*1000 + 999}, nil)
+	sb.FieldBuilder(1).(*array.Int32Builder).AppendValues([]int32{1440, 1440}, nil)
+	arr := sb.NewArray()
+	defer arr.Release()
+	dest := make([]snowflakeValue, 2)
+	if err := arrowToValue(&dest, execResponseRowType{Type: "timestamp_tz", Scale: 3}, arr, nil, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, out := range []time.Time{minTimestamp, maxTimestamp.Truncate(time.Millisecond)} {
+		if !dest[i].(time.Time).Equal(out) {
+			t.Errorf("timestamp_tz. expected: %v, got: %v", out, dest[i])
+		}
+	}
+}
+
+func TestValueToStringTimestampExtremes(t *testing.T) {
+	for _, tc := range []struct {
+		in     time.Time
+		tsmode string
+		out    string
+	}{
+		{in: maxTimestamp, tsmode: "TIMESTAMP_NTZ", out: "253402300799999999999"},
+		{in: minTimestamp, tsmode: "TIMESTAMP_LTZ", out: "-62135596800000000000"},
+		{in: time.Unix(-2, 500000000), tsmode: "TIMESTAMP_NTZ", out: "-1500000000"},
+		{in: maxTimestamp.In(time.FixedZone("", -8*3600)), tsmode: "TIMESTAMP_TZ", out: "253402300799999999999 960"},
+	} {
+		s, err := valueToString(tc.in, tc.tsmode)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *s != tc.out {
+			t.Errorf("%v, %v. expected: %v, got: %v", tc.in, tc.tsmode, tc.out, *s)
+		}
+	}
+}
+
 type tcArrayToString struct {
 	in  interface{}
 	typ string
@@ -205,7 +446,7 @@ func TestArrayToString(t *testing.T) {
 			t.Errorf("failed. in: %v, expected: %v, got: %v", test.in, test.typ, s)
 		}
 		for i, v := range a {
-			if v != test.out[i] {
+			if *v != test.out[i] {
 				t.Errorf("failed. in: %v, expected: %v, got: %v", test.in, test.out[i], a)
 			}
 		}
@@ -532,7 +773,7 @@ func TestArrowToValue(t *testing.T) {
 			meta := tc.rowType
 			meta.Type = tc.logical
 
-			err := arrowToValue(&dest, meta, arr)
+			err := arrowToValue(&dest, meta, arr, nil, false)
 			if err != nil {
 				t.Fatalf("error: %s", err)
 			}
@@ -0,0 +1,186 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Statuses of the files of COPY INTO <table>.
+const (
+	CopyStatusLoaded          = "LOADED"
+	CopyStatusLoadFailed      = "LOAD_FAILED"
+	CopyStatusPartiallyLoaded = "PARTIALLY_LOADED"
+	CopyStatusLoadSkipped     = "LOAD_SKIPPED"
+)
+
+// SQLQueryer runs a query, e.g., *sql.DB, *sql.Conn or *sql.Tx.
+type SQLQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// CopyFileResult is the result of a file of COPY INTO <table>, or of COPY INTO <location> with DETAILED_OUTPUT = TRUE.
+type CopyFileResult struct {
+	File                 string
+	Status               string // of a loaded file. one of the CopyStatus constants
+	RowsParsed           int64
+	RowsLoaded           int64
+	ErrorLimit           int64
+	ErrorsSeen           int64
+	FirstError           string // empty if no error
+	FirstErrorLine       int64
+	FirstErrorCharacter  int64
+	FirstErrorColumnName string
+	FileSize             int64 // of an unloaded file
+	RowsUnloaded         int64 // of an unloaded file
+}
+
+// failed returns true if the file is loaded partially or not at all.
+func (r *CopyFileResult) failed() bool {
+	return r.Status == CopyStatusLoadFailed || r.Status == CopyStatusPartiallyLoaded ||
+		r.Status == CopyStatusLoadSkipped
+}
+
+// CopyResult is the result of COPY INTO <table> or COPY INTO <location>.
+type CopyResult struct {
+	Files        []CopyFileResult
+	RowsLoaded   int64 // total of the files of COPY INTO <table>
+	RowsUnloaded int64 // of COPY INTO <location>
+	InputBytes   int64 // of COPY INTO <location>
+	OutputBytes  int64 // of COPY INTO <location>
+}
+
+// Err returns a *CopyError if any file is loaded partially or not at all, or nil.
+func (r *CopyResult) Err() error {
+	return copyFilesError(r.Files)
+}
+
+// CopyError is the error of COPY INTO <table> loading files partially or not at all, which COPY reports in its result
+// rather than failing with ON_ERROR other than ABORT_STATEMENT.
+type CopyError struct {
+	Files []CopyFileResult // files loaded partially or not at all
+	Total int              // number of the files of COPY
+}
+
+func (e *CopyError) Error() string {
+	msgs := make([]string, len(e.Files))
+	for i, f := range e.Files {
+		msgs[i] = fmt.Sprintf("%v: %v, %v rows loaded of %v", f.File, f.Status, f.RowsLoaded, f.RowsParsed)
+		if f.FirstError != "" {
+			msgs[i] += fmt.Sprintf(", %v errors, first at line %v", f.ErrorsSeen, f.FirstErrorLine)
+			if f.FirstErrorColumnName != "" {
+				msgs[i] += fmt.Sprintf(" column %v", f.FirstErrorColumnName)
+			}
+			msgs[i] += ": " + f.FirstError
+		}
+	}
+	return fmt.Sprintf("%v of %v files failed to load. %v", len(e.Files), e.Total, strings.Join(msgs, "; "))
+}
+
+// copyFilesError returns a *CopyError of the failed files, or nil.
+func copyFilesError(files []CopyFileResult) error {
+	var failed []CopyFileResult
+	for _, f := range files {
+		if f.failed() {
+			failed = append(failed, f)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &CopyError{Files: failed, Total: len(files)}
+}
+
+// ExecCopy runs a COPY INTO <table> or COPY INTO <location> statement and returns its result. The error is a
+// *CopyError, along with the result, if any file is loaded partially or not at all.
+func ExecCopy(ctx context.Context, db SQLQueryer, query string, args ...interface{}) (*CopyResult, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := scanCopyResult(rows)
+	if err != nil {
+		return nil, err
+	}
+	return result, result.Err()
+}
+
+// scanCopyResult returns the result of the rows of COPY, closing the rows. COPY INTO <table> returns a row per file,
+// or a single status column if no file is loaded. COPY INTO <location> returns a summary row, or a row per file with
+// DETAILED_OUTPUT = TRUE.
+func scanCopyResult(rows *sql.Rows) (*CopyResult, error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	result := &CopyResult{}
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		var r CopyFileResult
+		var hasFile bool
+		for i, column := range columns {
+			v := values[i].String
+			switch strings.ToLower(column) {
+			case "file", "file_name":
+				r.File, hasFile = v, true
+			case "status":
+				r.Status = v
+			case "rows_parsed":
+				r.RowsParsed, err = parseCopyCount(v)
+			case "rows_loaded":
+				r.RowsLoaded, err = parseCopyCount(v)
+			case "error_limit":
+				r.ErrorLimit, err = parseCopyCount(v)
+			case "errors_seen":
+				r.ErrorsSeen, err = parseCopyCount(v)
+			case "first_error":
+				r.FirstError = v
+			case "first_error_line":
+				r.FirstErrorLine, err = parseCopyCount(v)
+			case "first_error_character":
+				r.FirstErrorCharacter, err = parseCopyCount(v)
+			case "first_error_column_name":
+				r.FirstErrorColumnName = v
+			case "file_size":
+				r.FileSize, err = parseCopyCount(v)
+			case "row_count":
+				r.RowsUnloaded, err = parseCopyCount(v)
+			case "rows_unloaded":
+				result.RowsUnloaded, err = parseCopyCount(v)
+			case "input_bytes":
+				result.InputBytes, err = parseCopyCount(v)
+			case "output_bytes":
+				result.OutputBytes, err = parseCopyCount(v)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid %v of COPY: %v", column, err)
+			}
+		}
+		if hasFile {
+			result.Files = append(result.Files, r)
+			result.RowsLoaded += r.RowsLoaded
+			result.RowsUnloaded += r.RowsUnloaded
+		}
+	}
+	return result, rows.Err()
+}
+
+// parseCopyCount parses a count of the COPY results, which is NULL if not applicable.
+func parseCopyCount(v string) (int64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func execCopyTest(t *testing.T, columns []string, rowSet [][]*string) (*CopyResult, error) {
+	t.Helper()
+	db := sql.OpenDB(newFakeRowsConnector(columns, rowSet))
+	defer db.Close()
+	return ExecCopy(context.Background(), db, "COPY INTO t")
+}
+
+func TestExecCopyLoad(t *testing.T) {
+	str := func(s string) *string {
+		return &s
+	}
+	columns := []string{"file", "status", "rows_parsed", "rows_loaded", "error_limit", "errors_seen", "first_error",
+		"first_error_line", "first_error_character", "first_error_column_name"}
+	rowSet := [][]*string{
+		{str("s3://b/a.csv.gz"), str("LOADED"), str("10"), str("10"), str("1"), str("0"), nil, nil, nil, nil},
+		{str("s3://b/b.csv.gz"), str("PARTIALLY_LOADED"), str("10"), str("8"), str("10"), str("2"),
+			str("Numeric value 'x' is not recognized"), str("3"), str("5"), str(`"T"["ID":1]`)},
+	}
+	result, err := execCopyTest(t, columns, rowSet)
+	if result == nil || len(result.Files) != 2 || result.RowsLoaded != 18 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	f := result.Files[1]
+	if f.File != "s3://b/b.csv.gz" || f.Status != CopyStatusPartiallyLoaded || f.RowsParsed != 10 ||
+		f.ErrorLimit != 10 || f.ErrorsSeen != 2 || f.FirstErrorLine != 3 || f.FirstErrorCharacter != 5 ||
+		f.FirstErrorColumnName != `"T"["ID":1]` {
+		t.Fatalf("unexpected file result: %+v", f)
+	}
+	copyErr, ok := err.(*CopyError)
+	if !ok || copyErr.Total != 2 || len(copyErr.Files) != 1 || copyErr.Files[0].File != f.File {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `1 of 2 files failed to load. s3://b/b.csv.gz: PARTIALLY_LOADED, 8 rows loaded of 10, 2 errors, ` +
+		`first at line 3 column "T"["ID":1]: Numeric value 'x' is not recognized`
+	if copyErr.Error() != expected {
+		t.Fatalf("unexpected error message: %v", copyErr.Error())
+	}
+
+	// no file to load
+	result, err = execCopyTest(t, []string{"status"}, [][]*string{{str("Copy executed with 0 files processed.")}})
+	if err != nil || len(result.Files) != 0 || result.RowsLoaded != 0 {
+		t.Fatalf("unexpected result: %+v, err: %v", result, err)
+	}
+}
+
+func TestExecCopyUnload(t *testing.T) {
+	str := func(s string) *string {
+		return &s
+	}
+	result, err := execCopyTest(t, []string{"rows_unloaded", "input_bytes", "output_bytes"},
+		[][]*string{{str("100"), str("2000"), str("500")}})
+	if err != nil || len(result.Files) != 0 || result.RowsUnloaded != 100 || result.InputBytes != 2000 ||
+		result.OutputBytes != 500 {
+		t.Fatalf("unexpected result: %+v, err: %v", result, err)
+	}
+
+	result, err = execCopyTest(t, []string{"FILE_NAME", "FILE_SIZE", "ROW_COUNT"},
+		[][]*string{{str("data_0_0_0.csv.gz"), str("300"), str("60")}, {str("data_0_0_1.csv.gz"), str("200"), str("40")}})
+	if err != nil || len(result.Files) != 2 || result.RowsUnloaded != 100 || result.Files[1].FileSize != 200 ||
+		result.Files[1].RowsUnloaded != 40 {
+		t.Fatalf("unexpected result: %+v, err: %v", result, err)
+	}
+
+	_, err = execCopyTest(t, []string{"rows_unloaded"}, [][]*string{{str("x")}})
+	if err == nil {
+		t.Fatal("should fail with an invalid count")
+	}
+}
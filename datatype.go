@@ -3,7 +3,6 @@
 package gosnowflake
 
 import (
-	"bytes"
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
@@ -25,6 +24,10 @@ const (
 	booleanType
 )
 
+// The DataType variables are the binding parameter flags. A flag is matched by identity, not by content: pass the
+// variable itself, as a copy of it, e.g., append([]byte{}, DataTypeText...), is bound as BINARY data of one byte.
+// The timestamp flags apply to the time.Time values after them, and DataTypeText and DataTypeBinary to the next
+// []byte value only.
 var (
 	// DataTypeFixed is a FIXED datatype.
 	DataTypeFixed = []byte{fixedType}
@@ -54,22 +57,25 @@ var (
 	DataTypeBoolean = []byte{booleanType}
 )
 
-// dataTypeMode returns the subsequent data type in a string representation.
+// dataTypeMode returns the subsequent data type in a string representation. Only the DataType variables are
+// flags, so a BINARY value of the same single byte as a flag is not taken for it.
 func dataTypeMode(v driver.Value) (tsmode string, err error) {
 	if bd, ok := v.([]byte); ok {
 		switch {
-		case bytes.Equal(bd, DataTypeDate):
+		case isDataType(bd, DataTypeDate):
 			tsmode = "DATE"
-		case bytes.Equal(bd, DataTypeTime):
+		case isDataType(bd, DataTypeTime):
 			tsmode = "TIME"
-		case bytes.Equal(bd, DataTypeTimestampLtz):
+		case isDataType(bd, DataTypeTimestampLtz):
 			tsmode = "TIMESTAMP_LTZ"
-		case bytes.Equal(bd, DataTypeTimestampNtz):
+		case isDataType(bd, DataTypeTimestampNtz):
 			tsmode = "TIMESTAMP_NTZ"
-		case bytes.Equal(bd, DataTypeTimestampTz):
+		case isDataType(bd, DataTypeTimestampTz):
 			tsmode = "TIMESTAMP_TZ"
-		case bytes.Equal(bd, DataTypeBinary):
+		case isDataType(bd, DataTypeBinary):
 			tsmode = "BINARY"
+		case isDataType(bd, DataTypeText):
+			tsmode = "TEXT"
 		default:
 			return "", fmt.Errorf(errMsgInvalidByteArray, v)
 		}
@@ -79,6 +85,11 @@ func dataTypeMode(v driver.Value) (tsmode string, err error) {
 	return tsmode, nil
 }
 
+// isDataType returns true if bd is the DataType variable dt itself rather than a value of the same content.
+func isDataType(bd []byte, dt []byte) bool {
+	return len(bd) == 1 && len(dt) == 1 && &bd[0] == &dt[0]
+}
+
 // SnowflakeParameter includes the columns output from SHOW PARAMETER command.
 type SnowflakeParameter struct {
 	Key                       string
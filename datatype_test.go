@@ -22,6 +22,7 @@ func TestDataTypeMode(t *testing.T) {
 		{tp: DataTypeDate, tmode: "DATE", err: nil},
 		{tp: DataTypeTime, tmode: "TIME", err: nil},
 		{tp: DataTypeBinary, tmode: "BINARY", err: nil},
+		{tp: DataTypeText, tmode: "TEXT", err: nil},
 		{tp: DataTypeFixed, tmode: "FIXED",
 			err: fmt.Errorf(errMsgInvalidByteArray, DataTypeFixed)},
 		{tp: DataTypeReal, tmode: "REAL",
@@ -226,6 +226,8 @@ This table shows the data types:
   ARRAY          | ARRAY                              | string      | string     |
   OBJECT         | OBJECT                             | string      | string     |
   VARIANT        | VARIANT                            | string      | string     |
+  GEOGRAPHY      | GEOGRAPHY                          | string      | string     | [4]
+  GEOMETRY       | GEOMETRY                           | string      | string     | [4]
 
 Footnotes:
 
@@ -235,6 +237,8 @@ Footnotes:
 
   [3] If the value in Snowflake is too large to fit into the corresponding Golang data type, then conversion can return either an int64 with the high bits truncated or an error.
 
+  [4] The values are in the GEOGRAPHY_OUTPUT_FORMAT or GEOMETRY_OUTPUT_FORMAT of the session. Values of the WKB and EWKB formats are []byte. See Geospatial Data.
+
 Note: SQL NULL values are converted to Golang nil values, and vice-versa.
 
 Binding Parameters to Array Variables For Batch Inserts
@@ -257,6 +261,23 @@ binds arrays to the parameters in the INSERT statement.
 	// Insert the data from the arrays into the table.
 	_, err = db.Exec("insert into my_table values (?, ?, ?, ?)", intArray, fltArray, boolArray, strArray)
 
+InsertStructs builds the INSERT statement from a slice of structs, or of pointers to structs, and binds each field as
+an array in the same way. The fields map to the columns as they do for ScanStructs, and nil pointers insert NULL.
+Slices larger than BatchSize rows, 10000 by default, are inserted by several requests, so use a transaction to insert
+all rows or none. In the following example, sf is an alias for the gosnowflake package:
+
+	type Order struct {
+		ID     int64 `snowflake:"ORDER_ID"`
+		Amount *float64
+		Day    sf.Date
+	}
+	...
+	n, err := sf.InsertStructs(ctx, db, "orders", orders, nil) // n is the total number of rows inserted
+
+The table is written into the INSERT statement as is, so pass it as written in SQL, qualified and quoted as needed,
+e.g., mydb.public."My Table". The columns are quoted, with the names of the fields and tags normalized as SQL does,
+so Amount refers to the column AMOUNT, and a tag of "Amount" in double quotes refers to the column Amount.
+
 Note: For alternative ways to load data into the Snowflake database (including bulk loading using the COPY command), see
 Loading Data Into Snowflake (https://docs.snowflake.com/en/user-guide-data-load.html).
 
@@ -303,14 +324,74 @@ Currently, Snowflake doesn't support the name-based Location types, e.g.,
 
 For more information about Location types, see the Go documentation for https://golang.org/pkg/time/#Location.
 
+TIMESTAMP_LTZ (timestamp with local time zone) data is fetched in the time zone
+of the session, i.e., the TIMEZONE parameter, which is updated whenever the
+session changes it with ALTER SESSION SET TIMEZONE. If the session has no valid
+TIMEZONE, the values are in the local time zone of the client, time.Local. To
+keep the values in time.Local regardless of the session, set KeepLocalTimezone
+in Config or keepLocalTimezone=true in the DSN. DATE values are not affected
+and are always at midnight UTC of the date.
+
+Dates and Times of Day
+
+By default, DATE values are fetched as time.Time at midnight UTC of the date, and TIME values as
+time.Time on the zero date, January 1, year 1, in UTC. To fetch them as a calendar date, Date,
+and a time of day with nanosecond precision, TimeOfDay, which don't depend on any time zone, set
+CivilDateTime in Config or civilDateTime=true in the DSN. ColumnTypeScanType reports the types
+accordingly.
+
+Date and TimeOfDay scan DATE and TIME columns either way, taking the date or time of day of a
+time.Time as is. Both bind to the corresponding type without the binding parameter flag. In the
+following example, sf is an alias for the gosnowflake package:
+
+	var d sf.Date
+	var t sf.TimeOfDay
+	err := db.QueryRow("SELECT d, t FROM events WHERE id = ?", id).Scan(&d, &t)
+	...
+	_, err = db.Exec("INSERT INTO events (id, d, t) VALUES (?, ?, ?)", id,
+		sf.Date{Year: 2020, Month: time.May, Day: 1}, sf.TimeOfDay{Hour: 9, Minute: 30})
+
+Date and TimeOfDay fail to scan NULL. Scan nullable columns into NullDate and NullTimeOfDay, which
+work as sql.NullTime does, with Valid false for NULL. They bind NULL if they aren't valid.
+
+Geospatial Data
+
+GEOGRAPHY and GEOMETRY values are fetched in the output format of the session, i.e., the
+GEOGRAPHY_OUTPUT_FORMAT and GEOMETRY_OUTPUT_FORMAT parameters. GeoJSON, WKT and EWKT values are
+strings, and WKB and EWKB values are []byte.
+
+GeoValue scans a value of any format and keeps the format with the data. UnmarshalGeoJSON parses a
+GeoJSON value. In the following example, sf is an alias for the gosnowflake package:
+
+	var shape sf.GeoValue
+	err := db.QueryRow("SELECT shape FROM regions WHERE id = ?", id).Scan(&shape)
+	...
+	if shape.Format == sf.GeoFormatGeoJSON {
+		var feature map[string]interface{}
+		err = shape.UnmarshalGeoJSON(&feature)
+	}
+
+GeoValue binds to a GEOGRAPHY or GEOMETRY column as text, which Snowflake converts from GeoJSON, WKT,
+EWKT, or the hex of WKB and EWKB. NewGeoJSON, NewWKT and NewWKB return the values to bind:
+
+	_, err = db.Exec("INSERT INTO regions (id, shape) VALUES (?, ?)", id, sf.NewWKB(wkb))
+
 Binary Data
 
-Internally, this feature leverages the []byte data type. As a result, BINARY
-data cannot be bound without the binding parameter flag. In the following
-example, sf is an alias for the gosnowflake package:
+[]byte values are bound as BINARY. The binding parameter flags are the DataType variables themselves, so a BINARY
+value of a single byte is bound as is even if it has the same content as a flag. In the following example, sf is an
+alias for the gosnowflake package:
 
 	var b = []byte{0x01, 0x02, 0x03}
-	_, err = stmt.Exec(sf.DataTypeBinary, b)
+	_, err = stmt.Exec(b)
+	_, err = stmt.Exec([]byte{0x0a})
+
+To bind a []byte value as TEXT instead, put the DataTypeText flag before it. The flag applies to the next []byte value
+only, and must be the variable itself, not a copy of it:
+
+	_, err = stmt.Exec(sf.DataTypeText, []byte("text"))
+
+BINARY values of result sets are decoded in the BINARY_OUTPUT_FORMAT of the session, HEX or BASE64.
 
 Maximum number of Result Set Chunk Downloader
 
@@ -328,9 +409,157 @@ memory footprint by itself. Consider Custom JSON Decoder.
 	sf.MaxChunkDownloadWorkers = 2
 
 
+Spilling Result Set Chunks to Disk
+
+If the application consumes rows slower than the chunks are downloaded, the downloaded chunks are held in memory until
+the rows reach them. The application may have the driver write the downloaded chunks, compressed, to a local directory
+instead and decode each of them only when the rows reach it:
+
+	import (
+		sf "github.com/snowflakedb/gosnowflake"
+	)
+	sf.ChunkSpillEnabled = true
+	sf.ChunkSpillDir = "/mnt/scratch" // the default directory for temporary files if empty
+
+The files are removed once their chunks are decoded or the rows are closed. Except on Windows, the files are unlinked
+as soon as they are created so that the space is reclaimed however the process exits. The files of a process are
+written to its own subdirectory, gosnowflake-spill-<pid>, and the next process to spill removes the subdirectories of
+the processes no longer running, so that the files a crashed or killed process leaves behind don't accumulate.
+
+Moving within Result Set
+
+Rows are read in order by default. The application may jump to a row of a large result set, or skip rows, without
+reading the rows in between through SnowflakeRowsSeeker. The rows start downloading the chunks as the query returns,
+so a query that seeks before reading should be run with the context of WithDeferredChunkDownloads, which has the
+downloads wait for the first call to Next, SeekRow or SkipRows. Then only the chunks from the one holding the row on
+are downloaded. The driver rows are reachable with sql.Conn.Raw as follows:
+
+	import (
+		"database/sql/driver"
+
+		sf "github.com/snowflakedb/gosnowflake"
+	)
+	...
+	conn, err := db.Conn(ctx)
+	...
+	err = conn.Raw(func(x interface{}) error {
+		stmt, err := x.(driver.ConnPrepareContext).PrepareContext(ctx, "SELECT * FROM T1 ORDER BY C1")
+		if err != nil {
+			return err
+		}
+		rows, err := stmt.(driver.StmtQueryContext).QueryContext(sf.WithDeferredChunkDownloads(ctx), nil)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		// the next call to Next returns the 50001st row
+		if err = rows.(sf.SnowflakeRowsSeeker).SeekRow(50000); err != nil {
+			return err
+		}
+		dest := make([]driver.Value, len(rows.Columns()))
+		for i := 0; i < 100; i++ {
+			if err = rows.Next(dest); err != nil {
+				return err
+			}
+			...
+		}
+		return nil
+	})
+
+SeekRow fails with io.EOF once Next has reached the end of the result set.
+
+Likewise, the driver rows implement SnowflakeRowsProgress, which reports the number of rows of the current result set,
+the number of rows consumed so far, and how many chunks are downloaded, pending or failed to download, without a
+separate COUNT(*) query:
+
+	progress := rows.(sf.SnowflakeRowsProgress)
+	fmt.Printf("%v/%v rows, %+v\n", progress.ConsumedRows(), progress.TotalRows(), progress.ChunkDownloadProgress())
+
+The ColumnType methods of database/sql expose part of the column metadata; ColumnTypeLength reports the length of
+text and binary columns and the precision of FIXED. SnowflakeRowsColumnMetadata returns all of it, including the
+collation and the database, schema and table a column comes from, and DeclaredType gives the type as declared in DDL:
+
+	for _, c := range rows.(sf.SnowflakeRowsColumnMetadata).ColumnMetadata() {
+		fmt.Printf("%v.%v.%v.%v %v\n", c.Database, c.Schema, c.Table, c.Name, c.DeclaredType())
+	}
+
+Query Statistics
+
+The driver results and rows implement SnowflakeQueryStats, which reports the execution metadata the server returns
+with the result: the statement type, the number of rows returned or changed by DML, the result format, the number of
+bindings, the database, schema, role and warehouse of the session after the query, and the time the server sent the
+result. The server doesn't return the compilation and execution times of the query, which QUERY_HISTORY has by
+QueryID. ClientStartTime and ClientElapsedTime are measured by the driver from the submission of the query to its
+response. Like the rows, the results are reachable through sql.Conn.Raw:
+
+	err = conn.Raw(func(x interface{}) error {
+		result, err := x.(driver.ExecerContext).ExecContext(ctx, "INSERT INTO T1 SELECT * FROM T2", nil)
+		if err != nil {
+			return err
+		}
+		stats := result.(sf.SnowflakeQueryStats).QueryStats()
+		log.Printf("%v %v: %v rows in %v", stats.QueryID, stats.StatementType, stats.RowsInserted,
+			stats.ClientElapsedTime)
+		return nil
+	})
+
+Scanning Rows into Structs
+
+ScanStructs scans all rows of a sql.Rows into a slice of structs, or of pointers to structs, and StructScanner scans
+the current row into a struct. A column maps to the field tagged with its name, or else to the field of the same
+name, both case-insensitively. The tag "-" skips a field, and the fields of embedded structs are promoted. In the
+following example, sf is an alias for the gosnowflake package:
+
+	type Order struct {
+		ID     int64      `snowflake:"ORDER_ID"`
+		Amount *big.Float // NULL scans to nil
+		Day    sf.Date
+		Items  []Item     // VARIANT, OBJECT or ARRAY is decoded by encoding/json
+		Note   string     `snowflake:"-"`
+	}
+	...
+	rows, err := db.QueryContext(ctx, "SELECT order_id, amount, day, items FROM orders")
+	...
+	defer rows.Close()
+	var orders []Order
+	err = sf.ScanStructs(rows, &orders, &sf.StructScanOptions{Strict: true})
+
+Values are converted with the column metadata, and a value that doesn't fit the field, e.g., a fraction into an int
+or NULL into a non-pointer field, fails the scan with the name of the column. Fields implementing sql.Scanner, e.g.,
+GeoValue, scan the values themselves. Columns without a field are ignored unless Strict is set.
+
+Exporting Result Set
+
+The application may write a result set to an io.Writer, e.g., a file or an HTTP response, as CSV, newline-delimited
+JSON or an Arrow IPC stream without scanning the rows. The chunks are downloaded in parallel as rows.Next does.
+
+	n, err := sf.ExportQuery(ctx, conn, w, &sf.ExportOptions{
+		Format: sf.ExportFormatCSV,
+		CSV:    sf.CSVDialect{Delimiter: '|', Header: true, Null: `\N`},
+	}, "SELECT * FROM T1")
+
+ExportQuery runs the query on a sql.Conn. The driver rows implement SnowflakeRowsExporter too, so that the rows
+obtained through sql.Conn.Raw can be exported from the current row on. Numbers and semi-structured data are written
+as they are in JSON, and Arrow columns take the types of the Snowflake columns. Timestamps are exported to Arrow in
+nanoseconds, so exporting a timestamp before 1677-09-21 or after 2262-04-11 fails instead of overflowing.
+
+Typed JSON Decoder for parsing Result Set
+
+By default, the driver parses JSON result set chunks with a decoder that converts each value to its Go type while
+the chunk is streamed, based on the column metadata of the result set. It allocates far less than parsing the chunk
+into strings first; the BenchmarkDecodeChunk benchmarks of chunk_test.go compare the decoders. The previous decoders
+can be restored as follows:
+
+	import (
+		sf "github.com/snowflakedb/gosnowflake"
+	)
+	sf.TypedJSONDecoderEnabled = false
+	...
+
 Experimental: Custom JSON Decoder for parsing Result Set
 
-The application may have the driver use a custom JSON decoder that incrementally parses the result set as follows.
+When the typed JSON decoder is disabled, the application may have the driver use a custom JSON decoder that
+incrementally parses the result set as follows.
 
 	import (
 		sf "github.com/snowflakedb/gosnowflake"
@@ -510,8 +739,191 @@ example:
 Preparing statements and using bind variables are also not supported for multi-statement queries.
 
 
-Limitations
+Uploading Files to Stages
+
+PUT uploads local files to a stage. The files matching the wildcards of the path are compressed by gzip unless they
+already are or AUTO_COMPRESS = FALSE, encrypted on the client side if the stage requires it, and uploaded in parallel
+on PARALLEL goroutines to the S3, Azure or GCS storage of the stage. The query returns a row per file with the source
+and target file names, sizes and compressions, and the status, UPLOADED, SKIPPED or ERROR, with a message:
+
+	rows, err := db.QueryContext(ctx, "PUT file:///tmp/data/orders_*.csv @%orders PARALLEL = 8")
+	...
+	defer rows.Close()
+	for rows.Next() {
+		var source, target, sourceCompression, targetCompression, status, message string
+		var sourceSize, targetSize int64
+		err = rows.Scan(&source, &target, &sourceSize, &targetSize, &sourceCompression, &targetCompression,
+			&status, &message)
+		...
+	}
+
+Files that already exist on the stage are skipped unless OVERWRITE = TRUE. A file that fails to upload is reported
+with the ERROR status and doesn't fail the others. A file of up to 8 MB is uploaded from memory, and a larger one is
+read, compressed and encrypted as a stream and uploaded in parts of 8 MB, so it isn't held in memory as a whole.
+
+To upload data that isn't in a local file, set the context with WithFileStream. PUT then uploads what the io.Reader
+reads as the file named in the command, which doesn't have to exist:
+
+	ctx := gosnowflake.WithFileStream(ctx, csvReader)
+	_, err := db.ExecContext(ctx, "PUT file://orders.csv @%orders OVERWRITE = TRUE")
+
+The stream is compressed and encrypted as it is read, and uploaded by parts of 8 MB, so at most a couple of parts are
+held in memory. Parts are retried on S3 and Azure, but the stream is uploaded in a single request that can't be
+retried on GCS.
+
+Downloading Files from Stages
+
+GET downloads the files of a stage in parallel into a local directory, which is created if it doesn't exist. The files
+encrypted on the client side are decrypted, and each file is written to a temporary file renamed once complete, so
+the directory never has a partial file. The query returns a row per file with the name, the size and the status,
+DOWNLOADED or ERROR, with a message:
+
+	rows, err := db.QueryContext(ctx, "GET @%orders file:///tmp/unload/ PATTERN = '.*[.]csv[.]gz'")
+	...
+	defer rows.Close()
+	for rows.Next() {
+		var file, status, message string
+		var size int64
+		err = rows.Scan(&file, &size, &status, &message)
+		...
+	}
+
+The files are downloaded as they are on the stage, gzip files included. To decompress the gzip files and remove the
+.gz extension from their names, set the context with WithGetDecompress:
+
+	rows, err := db.QueryContext(gosnowflake.WithGetDecompress(ctx), "GET @%orders file:///tmp/unload/")
+
+Managing Staged Files
+
+ListStage lists the files of a stage with their sizes, MD5 and last modified times, and RemoveStageFiles removes
+files. Both take an optional regular expression pattern and run on a *sql.DB, *sql.Conn or *sql.Tx:
+
+	files, err := sf.ListStage(ctx, db, "@orders_stage/2020/", ".*[.]csv[.]gz")
+	...
+	for _, f := range files {
+		fmt.Println(f.Name, f.Size, f.MD5, f.LastModified)
+	}
+	removed, err := sf.RemoveStageFiles(ctx, db, "@orders_stage/2019/", "")
+
+The MD5 of a file is of the data in the storage. It matches the MD5 of the uploaded file for stages without
+encryption or with server side encryption, but not for internal stages encrypted by the client, for which PUT skips
+unchanged files by itself unless OVERWRITE = TRUE.
+
+Bulk Loading
+
+BulkLoader loads large numbers of rows faster than INSERT statements. It writes the rows to gzip compressed CSV
+files of BulkLoadOptions.FileSize bytes each, 64 MB by default, uploads them to a temporary stage, and copies them into
+the table with COPY INTO. The temporary stage lives in the session, so BulkLoader runs on a sql.Conn:
+
+	conn, err := db.Conn(ctx)
+	...
+	defer conn.Close()
+	report, err := sf.NewBulkLoader(conn, "orders", nil).LoadStructs(ctx, orders)
+	...
+	fmt.Println(report.RowsLoaded)
+
+LoadStructs maps the fields of a slice of structs to the columns as InsertStructs does. Load takes a
+BulkLoadIterator of rows instead. time.Time values are written as dates, times or timestamps by the types of their
+columns, which BulkLoader looks up with DESCRIBE TABLE. Timestamps are written in UTC to TIMESTAMP_NTZ and
+TIMESTAMP_LTZ columns, as InsertStructs binds them, and with their offsets to TIMESTAMP_TZ columns. The report has
+the number of rows written and loaded, and the COPY INTO result of each file. With BulkLoadOptions.OnError set to
+CONTINUE or SKIP_FILE, the files with bad rows are reported with their first errors instead of failing the load, and
+report.Err() returns a *CopyError of them.
+
+COPY Results
+
+COPY INTO <table> returns a row per file with the load status, the numbers of rows parsed and loaded, and the first
+error. ExecCopy runs a COPY statement and returns the rows as a CopyResult. Its error is a *CopyError, along with the
+result, if any file is loaded partially or not at all, so jobs can alert on it:
+
+	result, err := sf.ExecCopy(ctx, db, "COPY INTO orders FROM @orders_stage ON_ERROR = CONTINUE")
+	if copyErr, ok := err.(*sf.CopyError); ok {
+		for _, f := range copyErr.Files {
+			log.Printf("%v: %v, first error at line %v: %v", f.File, f.Status, f.FirstErrorLine, f.FirstError)
+		}
+	} else if err != nil {
+		...
+	}
+	log.Printf("%v rows loaded", result.RowsLoaded)
+
+For COPY INTO <location>, the result has the numbers of rows unloaded and of bytes, and the files with
+DETAILED_OUTPUT = TRUE.
+
+Snowpipe
+
+The snowpipe package calls the Snowpipe REST API of a pipe to ingest staged files and to report their load status.
+It builds on RESTClient, which sends requests to the REST APIs of Snowflake outside of a session with the key pair
+authentication of a Config and the retries of the driver. NewJWTToken signs a JWT, and RESTRequest.Prepare is
+called for every attempt of a request, so that each attempt can be signed with a new JWT.
+
+Object Introspection
+
+ListDatabases, ListSchemas, ListTables and ListViews list the objects of a scope from the output of SHOW, and
+ListColumns lists the columns of a table or view with their types, nullability, defaults and comments from DESCRIBE.
+ListColumnsIn lists the columns of all the tables and views of a schema, database or account by SHOW COLUMNS, one
+query rather than one per table. SHOW COLUMNS can't be paginated, so ListColumnsIn takes a LIKE pattern only.
+The names they take and return are exact names, as SHOW returns them: an unquoted identifier is stored in uppercase,
+so the table created as orders is ORDERS. NormalizeIdentifier converts an identifier as written in SQL to its exact
+name, and QuoteIdentifier quotes an exact name for SQL.
+
+ShowOptions filters the objects by a LIKE pattern and paginates them by name for large accounts:
+
+	opts := &sf.ShowOptions{Limit: 1000}
+	for {
+		tables, err := sf.ListTables(ctx, db, "MYDB", "", opts)
+		...
+		for _, t := range tables {
+			columns, err := sf.ListColumns(ctx, db, t.DatabaseName, t.SchemaName, t.Name)
+			...
+		}
+		if len(tables) < opts.Limit {
+			break
+		}
+		opts.From = tables[len(tables)-1].Name
+	}
+
+Warehouses
+
+ResumeWarehouse, SuspendWarehouse and ResizeWarehouse run ALTER WAREHOUSE, and WaitWarehouse polls SHOW WAREHOUSES
+until a warehouse reaches a state, reporting each state it goes through. A job can start its warehouse and know it
+is ready rather than rely on the latency of auto-resume:
+
+	if err := sf.ResumeWarehouse(ctx, db, "BATCH_WH"); err != nil {
+		if driverErr, ok := err.(*sf.SnowflakeError); ok && driverErr.Number == sf.ErrInsufficientPrivileges {
+			// the role has no OPERATE privilege on the warehouse
+		}
+		...
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	wh, err := sf.WaitWarehouse(ctx, db, "BATCH_WH", sf.WarehouseStateStarted, func(wh *sf.WarehouseInfo) {
+		log.Printf("warehouse %v is %v", wh.Name, wh.State)
+	})
+
+Warehouse names are exact. The errors of ALTER WAREHOUSE are *SnowflakeError with ErrInsufficientPrivileges if the
+role lacks the OPERATE or MODIFY privilege, or ErrObjectNotExistOrNotAuthorized if the warehouse doesn't exist or the
+role has no privilege on it. GetWarehouse and WaitWarehouse return ErrWarehouseNotFound if SHOW WAREHOUSES doesn't
+show the warehouse for either reason.
+
+Session State
+
+The driver connection implements SnowflakeSession, whose SessionState returns a snapshot of the session as of the
+last query: the current database, schema, role and warehouse, the session ID, the server version and the session
+parameters. GetSessionState gets it through sql.Conn.Raw without running a query:
+
+	conn, err := db.Conn(ctx)
+	...
+	state, err := sf.GetSessionState(conn)
+	...
+	log.Printf("session %v on %v.%v as %v", state.SessionID, state.Database, state.Schema, state.Role)
+	if tz, ok := state.Parameters.String("TIMEZONE"); ok {
+		...
+	}
+	threads, ok, err := state.Parameters.Int("CLIENT_RESULT_PREFETCH_THREADS")
 
-GET and PUT operations are unsupported.
+The snapshot is a copy, which doesn't change with later queries. The database, schema, role, warehouse and parameters
+are read under the lock with which a query updates them when its response arrives, so SessionState may be called
+while a query runs on the connection and returns the state as of the last query that completed. The session ID and
+the server version are set at the login and are not guarded by the lock.
 */
 package gosnowflake
@@ -1866,16 +1866,27 @@ func TestArrayBind(t *testing.T) {
 }
 
 func TestLargeSetResult(t *testing.T) {
+	TypedJSONDecoderEnabled = false
 	CustomJSONDecoderEnabled = false
+	defer func() { TypedJSONDecoderEnabled = true }()
 	testLargeSetResult(t, 100000, false)
 }
 
 func TestLargeSetResultWithCustomJSONDecoder(t *testing.T) {
+	TypedJSONDecoderEnabled = false
 	CustomJSONDecoderEnabled = true
+	defer func() {
+		TypedJSONDecoderEnabled = true
+		CustomJSONDecoderEnabled = false
+	}()
 	// less number of rows to avoid Travis timeout
 	testLargeSetResult(t, 20000, false)
 }
 
+func TestLargeSetResultWithTypedJSONDecoder(t *testing.T) {
+	testLargeSetResult(t, 100000, false)
+}
+
 func TestLargeSetResultWithArrowDecoder(t *testing.T) {
 	testLargeSetResult(t, 10000, true)
 }
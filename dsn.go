@@ -68,6 +68,9 @@ type Config struct {
 	InsecureMode bool             // driver doesn't check certificate revocation status
 	OCSPFailOpen OCSPFailOpenMode // OCSP Fail Open
 
+	KeepLocalTimezone bool // TIMESTAMP_LTZ values are in time.Local instead of the session TIMEZONE
+	CivilDateTime     bool // DATE and TIME values are Date and TimeOfDay instead of time.Time
+
 	Token string // Token to use for OAuth other forms of token based auth
 
 	PrivateKey *rsa.PrivateKey // Private key used to sign JWT
@@ -180,6 +183,12 @@ func DSN(cfg *Config) (dsn string, err error) {
 	if cfg.InsecureMode {
 		params.Add("insecureMode", strconv.FormatBool(cfg.InsecureMode))
 	}
+	if cfg.KeepLocalTimezone {
+		params.Add("keepLocalTimezone", strconv.FormatBool(cfg.KeepLocalTimezone))
+	}
+	if cfg.CivilDateTime {
+		params.Add("civilDateTime", strconv.FormatBool(cfg.CivilDateTime))
+	}
 
 	params.Add("ocspFailOpen", strconv.FormatBool(cfg.OCSPFailOpen != OCSPFailOpenFalse))
 
@@ -547,6 +556,20 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 				return
 			}
 			cfg.InsecureMode = vv
+		case "keepLocalTimezone":
+			var vv bool
+			vv, err = strconv.ParseBool(value)
+			if err != nil {
+				return
+			}
+			cfg.KeepLocalTimezone = vv
+		case "civilDateTime":
+			var vv bool
+			vv, err = strconv.ParseBool(value)
+			if err != nil {
+				return
+			}
+			cfg.CivilDateTime = vv
 		case "ocspFailOpen":
 			var vv bool
 			vv, err = strconv.ParseBool(value)
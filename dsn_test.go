@@ -415,6 +415,18 @@ func TestParseDSN(t *testing.T) {
 			ocspMode: ocspModeInsecure,
 			err:      nil,
 		},
+		{
+			dsn: "user:pass@account/db/s?keepLocalTimezone=true&civilDateTime=true",
+			config: &Config{
+				Account: "account", User: "user", Password: "pass",
+				Protocol: "https", Host: "account.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", OCSPFailOpen: OCSPFailOpenTrue, KeepLocalTimezone: true,
+				CivilDateTime:             true,
+				ValidateDefaultParameters: ConfigBoolTrue,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
 		{
 			dsn: "user:pass@account/db/s?validateDefaultParameters=true",
 			config: &Config{
@@ -519,6 +531,14 @@ func TestParseDSN(t *testing.T) {
 				t.Fatalf("%d: Failed to match OCSPMode. expected: %v, got: %v",
 					i, test.ocspMode, cfg.ocspMode())
 			}
+			if test.config.CivilDateTime != cfg.CivilDateTime {
+				t.Fatalf("%d: Failed to match CivilDateTime. expected: %v, got: %v",
+					i, test.config.CivilDateTime, cfg.CivilDateTime)
+			}
+			if test.config.KeepLocalTimezone != cfg.KeepLocalTimezone {
+				t.Fatalf("%d: Failed to match KeepLocalTimezone. expected: %v, got: %v",
+					i, test.config.KeepLocalTimezone, cfg.KeepLocalTimezone)
+			}
 			if test.config.ValidateDefaultParameters != cfg.ValidateDefaultParameters {
 				t.Fatalf("%d: Failed to match ValidateDefaultParameters. expected: %v, got: %v",
 					i, test.config.ValidateDefaultParameters, cfg.ValidateDefaultParameters)
@@ -744,6 +764,24 @@ func TestDSN(t *testing.T) {
 			},
 			dsn: "u:p@a.snowflakecomputing.com:443?ocspFailOpen=false&validateDefaultParameters=true",
 		},
+		{
+			cfg: &Config{
+				User:              "u",
+				Password:          "p",
+				Account:           "a",
+				KeepLocalTimezone: true,
+			},
+			dsn: "u:p@a.snowflakecomputing.com:443?keepLocalTimezone=true&ocspFailOpen=true&validateDefaultParameters=true",
+		},
+		{
+			cfg: &Config{
+				User:          "u",
+				Password:      "p",
+				Account:       "a",
+				CivilDateTime: true,
+			},
+			dsn: "u:p@a.snowflakecomputing.com:443?civilDateTime=true&ocspFailOpen=true&validateDefaultParameters=true",
+		},
 		{
 			cfg: &Config{
 				User:                      "u",
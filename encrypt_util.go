@@ -0,0 +1,280 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// snowflakeFileEncryption is the master key of a stage with client-side encryption.
+type snowflakeFileEncryption struct {
+	QueryStageMasterKey string `json:"queryStageMasterKey,omitempty"` // base64
+	QueryID             string `json:"queryId,omitempty"`
+	SMKID               int64  `json:"smkId,omitempty"`
+}
+
+// encryptionMaterials is the encryptionMaterial of a file transfer response, a single master key for PUT or one per
+// file for GET. The entries of files on stages without client-side encryption are nil.
+type encryptionMaterials []*snowflakeFileEncryption
+
+func (m *encryptionMaterials) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var materials []*snowflakeFileEncryption
+		if err := json.Unmarshal(data, &materials); err != nil {
+			return err
+		}
+		*m = materials
+		return nil
+	}
+	var material *snowflakeFileEncryption
+	if err := json.Unmarshal(data, &material); err != nil {
+		return err
+	}
+	if material == nil {
+		*m = nil
+	} else {
+		*m = encryptionMaterials{material}
+	}
+	return nil
+}
+
+// encryptMetadata is the metadata stored with an encrypted file: the file key encrypted by the master key, the IV of
+// the file content, and the description of the master key, all of which decryption needs.
+type encryptMetadata struct {
+	key     string // base64
+	iv      string // base64
+	matdesc string // JSON
+}
+
+// materialDescriptor is the description of the master key in encryptMetadata.
+type materialDescriptor struct {
+	SmkID   string `json:"smkId"`
+	QueryID string `json:"queryId"`
+	KeySize string `json:"keySize"`
+}
+
+// encryptData encrypts data with a random file key by AES-CBC, and the file key with the master key by AES-ECB, as
+// the server and the other Snowflake clients do.
+func encryptData(material *snowflakeFileEncryption, data []byte) ([]byte, *encryptMetadata, error) {
+	mode, meta, err := newFileEncrypter(material)
+	if err != nil {
+		return nil, nil, err
+	}
+	encrypted := pkcs5Pad(data, aes.BlockSize)
+	mode.CryptBlocks(encrypted, encrypted)
+	return encrypted, meta, nil
+}
+
+// newFileEncrypter returns the AES-CBC encrypter of a random file key and the metadata with the file key encrypted
+// by the master key.
+func newFileEncrypter(material *snowflakeFileEncryption) (cipher.BlockMode, *encryptMetadata, error) {
+	masterKey, err := base64.StdEncoding.DecodeString(material.QueryStageMasterKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	fileKey := make([]byte, len(masterKey))
+	if _, err = rand.Read(fileKey); err != nil {
+		return nil, nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err = rand.Read(iv); err != nil {
+		return nil, nil, err
+	}
+	block, err := aes.NewCipher(fileKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyBlock, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	encryptedKey := pkcs5Pad(fileKey, aes.BlockSize)
+	for i := 0; i < len(encryptedKey); i += aes.BlockSize {
+		keyBlock.Encrypt(encryptedKey[i:i+aes.BlockSize], encryptedKey[i:i+aes.BlockSize])
+	}
+	matdesc, err := json.Marshal(materialDescriptor{
+		SmkID:   strconv.FormatInt(material.SMKID, 10),
+		QueryID: material.QueryID,
+		KeySize: strconv.Itoa(len(masterKey) * 8),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return cipher.NewCBCEncrypter(block, iv), &encryptMetadata{
+		key:     base64.StdEncoding.EncodeToString(encryptedKey),
+		iv:      base64.StdEncoding.EncodeToString(iv),
+		matdesc: string(matdesc),
+	}, nil
+}
+
+// encryptWriter encrypts what is written to it as encryptData does, writing the encrypted blocks to w as they are
+// complete. Close writes the padded last block.
+type encryptWriter struct {
+	mode cipher.BlockMode
+	w    io.Writer
+	buf  []byte // incomplete block
+}
+
+// newEncryptWriter returns an encryptWriter to w with a random file key and the metadata to decrypt the output.
+func newEncryptWriter(material *snowflakeFileEncryption, w io.Writer) (*encryptWriter, *encryptMetadata, error) {
+	mode, meta, err := newFileEncrypter(material)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &encryptWriter{mode: mode, w: w}, meta, nil
+}
+
+func (ew *encryptWriter) Write(p []byte) (int, error) {
+	ew.buf = append(ew.buf, p...)
+	n := len(ew.buf) - len(ew.buf)%aes.BlockSize
+	if n == 0 {
+		return len(p), nil
+	}
+	ew.mode.CryptBlocks(ew.buf[:n], ew.buf[:n])
+	if _, err := ew.w.Write(ew.buf[:n]); err != nil {
+		return 0, err
+	}
+	ew.buf = append(ew.buf[:0], ew.buf[n:]...)
+	return len(p), nil
+}
+
+func (ew *encryptWriter) Close() error {
+	last := pkcs5Pad(ew.buf, aes.BlockSize)
+	ew.mode.CryptBlocks(last, last)
+	ew.buf = nil
+	_, err := ew.w.Write(last)
+	return err
+}
+
+// newFileDecrypter returns the AES-CBC decrypter of the file key of meta, decrypting the file key with the master
+// key.
+func newFileDecrypter(material *snowflakeFileEncryption, meta *encryptMetadata) (cipher.BlockMode, error) {
+	masterKey, err := base64.StdEncoding.DecodeString(material.QueryStageMasterKey)
+	if err != nil {
+		return nil, err
+	}
+	encryptedKey, err := base64.StdEncoding.DecodeString(meta.key)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := base64.StdEncoding.DecodeString(meta.iv)
+	if err != nil {
+		return nil, err
+	}
+	keyBlock, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(encryptedKey)%aes.BlockSize != 0 || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid encryption metadata")
+	}
+	fileKey := make([]byte, len(encryptedKey))
+	for i := 0; i < len(encryptedKey); i += aes.BlockSize {
+		keyBlock.Decrypt(fileKey[i:i+aes.BlockSize], encryptedKey[i:i+aes.BlockSize])
+	}
+	if fileKey, err = pkcs5Unpad(fileKey, aes.BlockSize); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewCBCDecrypter(block, iv), nil
+}
+
+// decryptReader decrypts what r reads as decryptData does, returning the decrypted blocks as they are complete. The
+// last block is held back until r reaches EOF to remove the padding.
+type decryptReader struct {
+	mode    cipher.BlockMode
+	r       io.Reader
+	chunk   []byte // read from r
+	pending []byte // encrypted, not decrypted yet
+	buf     []byte // decrypted
+	out     []byte // part of buf not returned yet
+	eof     bool
+}
+
+// newDecryptReader returns a decryptReader of r with the file key of meta.
+func newDecryptReader(material *snowflakeFileEncryption, meta *encryptMetadata, r io.Reader) (*decryptReader,
+	error) {
+	mode, err := newFileDecrypter(material, meta)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{mode: mode, r: r, chunk: make([]byte, 32<<10)}, nil
+}
+
+func (dr *decryptReader) Read(p []byte) (int, error) {
+	for len(dr.out) == 0 {
+		if dr.eof {
+			return 0, io.EOF
+		}
+		if err := dr.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, dr.out)
+	dr.out = dr.out[n:]
+	return n, nil
+}
+
+// fill reads from r and decrypts the complete blocks but the last into out.
+func (dr *decryptReader) fill() error {
+	n, err := dr.r.Read(dr.chunk)
+	dr.pending = append(dr.pending, dr.chunk[:n]...)
+	if err == io.EOF {
+		dr.eof = true
+		if len(dr.pending)%aes.BlockSize != 0 {
+			return fmt.Errorf("encrypted data is not a multiple of the block size")
+		}
+		dr.mode.CryptBlocks(dr.pending, dr.pending)
+		dr.out, err = pkcs5Unpad(dr.pending, aes.BlockSize)
+		dr.pending = nil
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	m := len(dr.pending) - len(dr.pending)%aes.BlockSize
+	if m == len(dr.pending) {
+		m -= aes.BlockSize
+	}
+	if m <= 0 {
+		return nil
+	}
+	dr.buf = append(dr.buf[:0], dr.pending[:m]...)
+	dr.mode.CryptBlocks(dr.buf, dr.buf)
+	dr.out = dr.buf
+	dr.pending = append(dr.pending[:0], dr.pending[m:]...)
+	return nil
+}
+
+// pkcs5Pad returns a copy of data padded to a multiple of the block size.
+func pkcs5Pad(data []byte, blockSize int) []byte {
+	n := blockSize - len(data)%blockSize
+	padded := make([]byte, len(data), len(data)+n)
+	copy(padded, data)
+	return append(padded, bytes.Repeat([]byte{byte(n)}, n)...)
+}
+
+// pkcs5Unpad removes the padding of pkcs5Pad.
+func pkcs5Unpad(data []byte, blockSize int) ([]byte, error) {
+	if len(data) == 0 || len(data)%blockSize != 0 {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	n := int(data[len(data)-1])
+	if n == 0 || n > blockSize || !bytes.Equal(data[len(data)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	return data[:len(data)-n], nil
+}
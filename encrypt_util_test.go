@@ -0,0 +1,149 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"testing"
+	"testing/iotest"
+)
+
+// decryptTestData decrypts data through decryptReader as GET does.
+func decryptTestData(material *snowflakeFileEncryption, meta *encryptMetadata, data []byte) ([]byte, error) {
+	r, err := newDecryptReader(material, meta, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func TestEncryptDecryptData(t *testing.T) {
+	for _, keySize := range []int{16, 32} {
+		material := &snowflakeFileEncryption{
+			QueryStageMasterKey: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, keySize)),
+			QueryID:             "01-put",
+			SMKID:               92019681909886,
+		}
+		for _, data := range [][]byte{{}, []byte("0123456789abcdef"), bytes.Repeat([]byte("abc"), 1000)} {
+			encrypted, meta, err := encryptData(material, data)
+			if err != nil {
+				t.Fatalf("failed to encrypt. err: %v", err)
+			}
+			if len(encrypted)%16 != 0 || len(encrypted) <= len(data) {
+				t.Fatalf("unexpected length of encrypted data: %v", len(encrypted))
+			}
+			var desc materialDescriptor
+			if err = json.Unmarshal([]byte(meta.matdesc), &desc); err != nil {
+				t.Fatalf("failed to parse matdesc. err: %v", err)
+			}
+			if desc.SmkID != "92019681909886" || desc.QueryID != "01-put" || desc.KeySize != strconv.Itoa(keySize*8) {
+				t.Fatalf("unexpected matdesc: %v", meta.matdesc)
+			}
+			decrypted, err := decryptTestData(material, meta, encrypted)
+			if err != nil {
+				t.Fatalf("failed to decrypt. err: %v", err)
+			}
+			if !bytes.Equal(decrypted, data) {
+				t.Fatalf("unexpected decrypted data. expected: %v, got: %v", len(data), len(decrypted))
+			}
+		}
+	}
+}
+
+func TestPkcs5Unpad(t *testing.T) {
+	if _, err := pkcs5Unpad([]byte{1, 2, 3}, 4); err == nil {
+		t.Error("should fail to unpad a partial block")
+	}
+	if _, err := pkcs5Unpad([]byte{1, 2, 3, 3}, 4); err == nil {
+		t.Error("should fail to unpad an invalid padding")
+	}
+	if b, err := pkcs5Unpad([]byte{1, 3, 3, 3}, 4); err != nil || !bytes.Equal(b, []byte{1}) {
+		t.Errorf("unexpected unpadded data: %v, err: %v", b, err)
+	}
+}
+
+func TestEncryptionMaterialsUnmarshal(t *testing.T) {
+	var m encryptionMaterials
+	if err := json.Unmarshal([]byte(`{"queryStageMasterKey": "a2V5", "queryId": "q1", "smkId": 1}`), &m); err != nil ||
+		len(m) != 1 || m[0].QueryID != "q1" {
+		t.Errorf("unexpected materials of PUT: %v, err: %v", m, err)
+	}
+	if err := json.Unmarshal([]byte(`[{"queryStageMasterKey": "a2V5", "queryId": "q2", "smkId": 2}, null]`), &m); err != nil ||
+		len(m) != 2 || m[0].SMKID != 2 || m[1] != nil {
+		t.Errorf("unexpected materials of GET: %v, err: %v", m, err)
+	}
+	if err := json.Unmarshal([]byte(`null`), &m); err != nil || m != nil {
+		t.Errorf("unexpected materials of no encryption: %v, err: %v", m, err)
+	}
+}
+
+func TestEncryptWriter(t *testing.T) {
+	material := &snowflakeFileEncryption{
+		QueryStageMasterKey: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)),
+		QueryID:             "01-writer",
+		SMKID:               2,
+	}
+	for _, size := range []int{0, 1, 16, 17, 100} {
+		data := bytes.Repeat([]byte("x"), size)
+		var buf bytes.Buffer
+		w, meta, err := newEncryptWriter(material, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < size; i += 7 {
+			if _, err = w.Write(data[i:intMin(i+7, size)]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		decrypted, err := decryptTestData(material, meta, buf.Bytes())
+		if err != nil || !bytes.Equal(decrypted, data) {
+			t.Errorf("failed to decrypt %v bytes: %v, err: %v", size, decrypted, err)
+		}
+	}
+}
+
+func TestDecryptReader(t *testing.T) {
+	material := &snowflakeFileEncryption{
+		QueryStageMasterKey: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 16)),
+		QueryID:             "01-reader",
+		SMKID:               3,
+	}
+	for _, size := range []int{0, 1, 15, 16, 17, 32, 100, 32<<10 - 1, 32 << 10, 100000} {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		encrypted, meta, err := encryptData(material, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range []io.Reader{bytes.NewReader(encrypted), iotest.HalfReader(bytes.NewReader(encrypted)),
+			iotest.DataErrReader(bytes.NewReader(encrypted))} {
+			dr, err := newDecryptReader(material, meta, r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decrypted, err := ioutil.ReadAll(dr)
+			if err != nil || !bytes.Equal(decrypted, data) {
+				t.Errorf("failed to decrypt %v bytes: %v bytes, err: %v", size, len(decrypted), err)
+			}
+		}
+		if size > 0 {
+			dr, err := newDecryptReader(material, meta, bytes.NewReader(encrypted[:len(encrypted)-1]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = ioutil.ReadAll(dr); err == nil {
+				t.Errorf("should fail to decrypt truncated %v bytes", size)
+			}
+		}
+	}
+}
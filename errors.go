@@ -90,6 +90,42 @@ const (
 
 	// ErrFailedToGetChunk is an error code for the case where it failed to get chunk of result set
 	ErrFailedToGetChunk = 262000
+	// ErrInvalidRowIndex is an error code for the case where a row index is out of the range of the result set
+	ErrInvalidRowIndex = 262001
+	// ErrChunkURLExpired is an error code for the case where the presigned URL of a chunk of result set has expired
+	ErrChunkURLExpired = 262002
+	// ErrFailedToRefreshChunkURLs is an error code for the case where the result set fetched again to refresh the
+	// chunk URLs doesn't match the original one
+	ErrFailedToRefreshChunkURLs = 262003
+
+	/* file transfer */
+
+	// ErrFileNotExists is an error code for the case where a file to upload doesn't exist
+	ErrFileNotExists = 264000
+	// ErrInvalidStageLocation is an error code for the case where the stage of a file transfer isn't supported
+	ErrInvalidStageLocation = 264001
+	// ErrInvalidCompressionType is an error code for the case where the source compression of PUT isn't supported
+	ErrInvalidCompressionType = 264002
+	// ErrNoEncryptionMaterial is an error code for the case where a file encrypted on the client side is downloaded
+	// without the material to decrypt it
+	ErrNoEncryptionMaterial = 264003
+
+	/* snowpipe */
+
+	// ErrNoPrivateKey is an error code for the case where a REST client, e.g., of Snowpipe, has no private key to
+	// authenticate
+	ErrNoPrivateKey = 265000
+	// ErrFailedToCallSnowpipe is an error code for the case where a Snowpipe REST endpoint returned an error
+	ErrFailedToCallSnowpipe = 265001
+	// ErrSnowpipeHistoryIncomplete is an error code for the case where loadHistoryScan doesn't complete the history
+	// of a time range within the maximum number of calls, or stops advancing
+	ErrSnowpipeHistoryIncomplete = 265002
+
+	/* warehouse */
+
+	// ErrWarehouseNotFound is an error code for the case where SHOW WAREHOUSES doesn't show a warehouse, which
+	// doesn't exist or the role has no privilege on
+	ErrWarehouseNotFound = 266000
 
 	/* transaction*/
 
@@ -107,6 +143,8 @@ const (
 	ErrInvalidOffsetStr = 268001
 	// ErrInvalidBinaryHexForm is an error code for the case where a binary data in hex form is invalid.
 	ErrInvalidBinaryHexForm = 268002
+	// ErrInvalidBinaryBase64Form is an error code for the case where a binary data in base64 form is invalid.
+	ErrInvalidBinaryBase64Form = 268003
 
 	/* OCSP */
 
@@ -127,6 +165,18 @@ const (
 	ErrRoleNotExist = 390189
 	// ErrObjectNotExistOrAuthorized is a GS error code for the case that the server-side object specified does not exist
 	ErrObjectNotExistOrAuthorized = 390201
+
+	/* SQL error code */
+
+	// ErrObjectNotExistOrNotAuthorized is a SQL error code for the case that the object specified does not exist or
+	// the role has no privilege on it
+	ErrObjectNotExistOrNotAuthorized = 2003
+	// ErrObjectNotExistOrCannotBePerformed is a SQL error code for the case that the object specified does not exist
+	// or the operation cannot be performed on it, e.g., by the role without the privilege
+	ErrObjectNotExistOrCannotBePerformed = 2043
+	// ErrInsufficientPrivileges is a SQL error code for the case that the role has insufficient privileges to
+	// operate on the object specified, e.g., to resume a warehouse without OPERATE
+	ErrInsufficientPrivileges = 3001
 )
 
 const (
@@ -138,6 +188,9 @@ const (
 	errMsgIdpConnectionError                 = "failed to verify URLs. authenticator: %v, token URL:%v, SSO URL:%v"
 	errMsgSSOURLNotMatch                     = "SSO URL didn't match. expected: %v, got: %v"
 	errMsgFailedToGetChunk                   = "failed to get a chunk of result sets. idx: %v"
+	errMsgInvalidRowIndex                    = "row index is out of range. index: %v, number of rows: %v"
+	errMsgChunkURLExpired                    = "the URL of a chunk of result sets has expired. idx: %v"
+	errMsgFailedToRefreshChunkURLs           = "failed to refresh the chunk URLs. query ID: %v, chunks: %v, got: %v"
 	errMsgFailedToPostQuery                  = "failed to POST. HTTP: %v, URL: %v"
 	errMsgFailedToRenew                      = "failed to renew session. HTTP: %v, URL: %v"
 	errMsgFailedToCancelQuery                = "failed to cancel query. HTTP: %v, URL: %v"
@@ -148,6 +201,12 @@ const (
 	errMsgFailedToGetSSO                     = "failed to auth via OKTA for unknown reason. HTTP: %v, URL: %v"
 	errMsgFailedToParseResponse              = "failed to parse a response from Snowflake. Response: %v"
 	errMsgFailedToGetExternalBrowserResponse = "failed to get an external browser response from Snowflake, err: %s"
+	errMsgFileNotExists                      = "file does not exist: %v"
+	errMsgInvalidStageLocation               = "stage location type is not supported: %v"
+	errMsgInvalidCompressionType             = "source compression type is not supported: %v"
+	errMsgNoEncryptionMaterial               = "no encryption material to decrypt the file: %v"
+	errMsgNoPrivateKey                       = "a private key is required for the key pair authentication"
+	errMsgWarehouseNotFound                  = "warehouse %v does not exist or not authorized"
 	errMsgNoReadOnlyTransaction              = "no readonly mode is supported"
 	errMsgNoDefaultTransactionIsolationLevel = "no default isolation transaction level is supported"
 	errMsgServiceUnavailable                 = "service is unavailable. check your connectivity. you may need a proxy server. HTTP: %v, URL: %v"
@@ -0,0 +1,503 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/decimal128"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportFormat is the format SnowflakeRowsExporter writes a result set in.
+type ExportFormat int
+
+const (
+	// ExportFormatCSV writes the rows as CSV in the dialect of ExportOptions.CSV.
+	ExportFormatCSV ExportFormat = iota
+	// ExportFormatNDJSON writes each row as a JSON object keyed by the column names, one per line.
+	ExportFormatNDJSON
+	// ExportFormatArrow writes the rows as an Arrow IPC stream.
+	ExportFormatArrow
+)
+
+const (
+	defaultExportArrowBatchSize = 10000
+
+	exportDateFormat        = "2006-01-02"
+	exportTimeFormat        = "15:04:05.999999999"
+	exportTimestampFormat   = "2006-01-02 15:04:05.999999999"
+	exportTimestampTzFormat = "2006-01-02 15:04:05.999999999 -07:00"
+)
+
+// CSVDialect specifies how ExportFormatCSV writes the rows.
+type CSVDialect struct {
+	Delimiter rune   // field delimiter. ',' if zero
+	Header    bool   // write the column names first
+	UseCRLF   bool   // end the lines with \r\n instead of \n
+	Null      string // text written for NULL. NULL is written as an empty field if empty.
+}
+
+// ExportOptions specifies how SnowflakeRowsExporter writes a result set.
+type ExportOptions struct {
+	Format         ExportFormat
+	CSV            CSVDialect
+	ArrowBatchSize int // number of rows per Arrow record batch. 10000 if zero
+}
+
+// SnowflakeRowsExporter writes the rows of the current result set, from the next row on, to an io.Writer and returns
+// the number of rows written. The chunks of the result set are downloaded in parallel as Next does. The driver rows
+// implement it and are reachable through sql.Conn.Raw.
+type SnowflakeRowsExporter interface {
+	Export(w io.Writer, opts *ExportOptions) (int64, error)
+}
+
+// ExportQuery runs the query on the connection and writes its result to w.
+func ExportQuery(ctx context.Context, conn *sql.Conn, w io.Writer, opts *ExportOptions, query string) (n int64, err error) {
+	err = conn.Raw(func(x interface{}) error {
+		queryer, ok := x.(driver.QueryerContext)
+		if !ok {
+			return fmt.Errorf("not a Snowflake connection: %T", x)
+		}
+		rows, err := queryer.QueryContext(ctx, query, nil)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		exporter, ok := rows.(SnowflakeRowsExporter)
+		if !ok {
+			return fmt.Errorf("not Snowflake rows: %T", rows)
+		}
+		n, err = exporter.Export(w, opts)
+		return err
+	})
+	return n, err
+}
+
+// rowWriter writes rows in an export format.
+type rowWriter interface {
+	writeRow(values []driver.Value) error
+	close() error
+}
+
+func (rows *snowflakeRows) Export(w io.Writer, opts *ExportOptions) (int64, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	var rw rowWriter
+	var err error
+	switch opts.Format {
+	case ExportFormatCSV:
+		rw, err = newCSVRowWriter(w, rows.RowType, &opts.CSV)
+	case ExportFormatNDJSON:
+		rw = newNDJSONRowWriter(w, rows.RowType)
+	case ExportFormatArrow:
+		rw, err = newArrowRowWriter(w, rows.RowType, opts.ArrowBatchSize)
+	default:
+		err = fmt.Errorf("unsupported export format: %v", opts.Format)
+	}
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	values := make([]driver.Value, len(rows.RowType))
+	for {
+		if err = rows.Next(values); err == io.EOF {
+			break
+		} else if err != nil {
+			return n, err
+		}
+		if err = rw.writeRow(values); err != nil {
+			return n, err
+		}
+		n++
+	}
+	logger.Debugf("exported rows: %v", n)
+	return n, rw.close()
+}
+
+// exportText returns the text of a value for the exports to text formats.
+func exportText(meta *execResponseRowType, v driver.Value) string {
+	switch v := v.(type) {
+	case string:
+		if strings.EqualFold(meta.Type, "boolean") {
+			return strconv.FormatBool(v == "1" || strings.EqualFold(v, "true"))
+		}
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case *big.Int:
+		return v.String()
+	case *big.Float:
+		return v.Text('f', int(meta.Scale))
+	case []byte:
+		return fmt.Sprintf("%X", v)
+	case time.Time:
+		switch strings.ToUpper(meta.Type) {
+		case "DATE":
+			return v.Format(exportDateFormat)
+		case "TIME":
+			return v.Format(exportTimeFormat)
+		case "TIMESTAMP_NTZ":
+			return v.Format(exportTimestampFormat)
+		}
+		return v.Format(exportTimestampTzFormat)
+	}
+	return fmt.Sprint(v)
+}
+
+type csvRowWriter struct {
+	w       *csv.Writer
+	rowType []execResponseRowType
+	null    string
+	record  []string
+}
+
+func newCSVRowWriter(w io.Writer, rowType []execResponseRowType, dialect *CSVDialect) (*csvRowWriter, error) {
+	cw := csv.NewWriter(w)
+	if dialect.Delimiter != 0 {
+		cw.Comma = dialect.Delimiter
+	}
+	cw.UseCRLF = dialect.UseCRLF
+	rw := &csvRowWriter{
+		w:       cw,
+		rowType: rowType,
+		null:    dialect.Null,
+		record:  make([]string, len(rowType)),
+	}
+	if dialect.Header {
+		for i := range rowType {
+			rw.record[i] = rowType[i].Name
+		}
+		if err := cw.Write(rw.record); err != nil {
+			return nil, err
+		}
+	}
+	return rw, nil
+}
+
+func (rw *csvRowWriter) writeRow(values []driver.Value) error {
+	for i, v := range values {
+		if v == nil {
+			rw.record[i] = rw.null
+		} else {
+			rw.record[i] = exportText(&rw.rowType[i], v)
+		}
+	}
+	return rw.w.Write(rw.record)
+}
+
+func (rw *csvRowWriter) close() error {
+	rw.w.Flush()
+	return rw.w.Error()
+}
+
+type ndjsonRowWriter struct {
+	w       *bufio.Writer
+	rowType []execResponseRowType
+	names   [][]byte // column names encoded in JSON
+}
+
+func newNDJSONRowWriter(w io.Writer, rowType []execResponseRowType) *ndjsonRowWriter {
+	names := make([][]byte, len(rowType))
+	for i := range rowType {
+		names[i], _ = json.Marshal(rowType[i].Name)
+	}
+	return &ndjsonRowWriter{
+		w:       bufio.NewWriter(w),
+		rowType: rowType,
+		names:   names,
+	}
+}
+
+func (rw *ndjsonRowWriter) writeRow(values []driver.Value) error {
+	rw.w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			rw.w.WriteByte(',')
+		}
+		rw.w.Write(rw.names[i])
+		rw.w.WriteByte(':')
+		b, err := ndjsonValue(&rw.rowType[i], v)
+		if err != nil {
+			return err
+		}
+		rw.w.Write(b)
+	}
+	rw.w.WriteByte('}')
+	return rw.w.WriteByte('\n')
+}
+
+func (rw *ndjsonRowWriter) close() error {
+	return rw.w.Flush()
+}
+
+// ndjsonValue encodes a value in JSON, keeping numbers exact and semi-structured data as it is.
+func ndjsonValue(meta *execResponseRowType, v driver.Value) ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	text := exportText(meta, v)
+	switch strings.ToUpper(meta.Type) {
+	case "FIXED", "BOOLEAN":
+		return []byte(text), nil
+	case "REAL":
+		if f, err := strconv.ParseFloat(text, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return []byte(text), nil
+		}
+	case "VARIANT", "OBJECT", "ARRAY":
+		if json.Valid([]byte(text)) {
+			return []byte(text), nil
+		}
+	}
+	return json.Marshal(text)
+}
+
+type arrowRowWriter struct {
+	w         *ipc.Writer
+	mem       memory.Allocator
+	schema    *arrow.Schema
+	rowType   []execResponseRowType
+	builders  []array.Builder
+	batchSize int
+	rows      int
+}
+
+func newArrowRowWriter(w io.Writer, rowType []execResponseRowType, batchSize int) (*arrowRowWriter, error) {
+	if batchSize <= 0 {
+		batchSize = defaultExportArrowBatchSize
+	}
+	mem := memory.NewGoAllocator()
+	fields := make([]arrow.Field, len(rowType))
+	builders := make([]array.Builder, len(rowType))
+	for i := range rowType {
+		dataType := exportArrowType(&rowType[i])
+		fields[i] = arrow.Field{
+			Name:     rowType[i].Name,
+			Type:     dataType,
+			Nullable: rowType[i].Nullable,
+			Metadata: arrow.NewMetadata([]string{"logicalType"}, []string{strings.ToUpper(rowType[i].Type)}),
+		}
+		builders[i] = newExportArrowBuilder(mem, dataType)
+	}
+	schema := arrow.NewSchema(fields, nil)
+	return &arrowRowWriter{
+		w:         ipc.NewWriter(w, ipc.WithSchema(schema), ipc.WithAllocator(mem)),
+		mem:       mem,
+		schema:    schema,
+		rowType:   rowType,
+		builders:  builders,
+		batchSize: batchSize,
+	}, nil
+}
+
+// exportArrowType returns the Arrow data type a column is exported as.
+func exportArrowType(meta *execResponseRowType) arrow.DataType {
+	switch strings.ToUpper(meta.Type) {
+	case "FIXED":
+		if meta.Scale == 0 && meta.Precision <= 18 {
+			return arrow.PrimitiveTypes.Int64
+		}
+		return &arrow.Decimal128Type{Precision: int32(meta.Precision), Scale: int32(meta.Scale)}
+	case "REAL":
+		return arrow.PrimitiveTypes.Float64
+	case "BOOLEAN":
+		return arrow.FixedWidthTypes.Boolean
+	case "BINARY":
+		return arrow.BinaryTypes.Binary
+	case "DATE":
+		return arrow.PrimitiveTypes.Date32
+	case "TIME":
+		return arrow.FixedWidthTypes.Time64ns
+	case "TIMESTAMP_NTZ":
+		return &arrow.TimestampType{Unit: arrow.Nanosecond}
+	case "TIMESTAMP_LTZ", "TIMESTAMP_TZ":
+		return &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}
+	}
+	return arrow.BinaryTypes.String
+}
+
+func newExportArrowBuilder(mem memory.Allocator, dataType arrow.DataType) array.Builder {
+	switch t := dataType.(type) {
+	case *arrow.Int64Type:
+		return array.NewInt64Builder(mem)
+	case *arrow.Decimal128Type:
+		return array.NewDecimal128Builder(mem, t)
+	case *arrow.Float64Type:
+		return array.NewFloat64Builder(mem)
+	case *arrow.BooleanType:
+		return array.NewBooleanBuilder(mem)
+	case *arrow.BinaryType:
+		return array.NewBinaryBuilder(mem, t)
+	case *arrow.Date32Type:
+		return array.NewDate32Builder(mem)
+	case *arrow.Time64Type:
+		return array.NewTime64Builder(mem, t)
+	case *arrow.TimestampType:
+		return array.NewTimestampBuilder(mem, t)
+	}
+	return array.NewStringBuilder(mem)
+}
+
+func (rw *arrowRowWriter) writeRow(values []driver.Value) error {
+	for i, v := range values {
+		if v == nil {
+			rw.builders[i].AppendNull()
+			continue
+		}
+		if err := appendExportArrowValue(rw.builders[i], &rw.rowType[i], v); err != nil {
+			return err
+		}
+	}
+	rw.rows++
+	if rw.rows == rw.batchSize {
+		return rw.flush()
+	}
+	return nil
+}
+
+func appendExportArrowValue(builder array.Builder, meta *execResponseRowType, v driver.Value) error {
+	switch b := builder.(type) {
+	case *array.Int64Builder:
+		switch v := v.(type) {
+		case int64:
+			b.Append(v)
+		case *big.Int:
+			b.Append(v.Int64())
+		default:
+			i, err := strconv.ParseInt(exportText(meta, v), 10, 64)
+			if err != nil {
+				return err
+			}
+			b.Append(i)
+		}
+	case *array.Decimal128Builder:
+		num, err := exportDecimal(exportText(meta, v), int(meta.Scale))
+		if err != nil {
+			return err
+		}
+		b.Append(num)
+	case *array.Float64Builder:
+		if f, ok := v.(float64); ok {
+			b.Append(f)
+			return nil
+		}
+		f, err := strconv.ParseFloat(exportText(meta, v), 64)
+		if err != nil {
+			return err
+		}
+		b.Append(f)
+	case *array.BooleanBuilder:
+		b.Append(exportText(meta, v) == "true")
+	case *array.BinaryBuilder:
+		if bs, ok := v.([]byte); ok {
+			b.Append(bs)
+		} else {
+			b.AppendString(exportText(meta, v))
+		}
+	case *array.Date32Builder:
+		var d Date
+		if err := d.Scan(v); err != nil {
+			return fmt.Errorf("unexpected value for %v: %v", meta.Type, v)
+		}
+		b.Append(arrow.Date32(d.In(time.UTC).Unix() / 86400))
+	case *array.Time64Builder:
+		var t TimeOfDay
+		if err := t.Scan(v); err != nil {
+			return fmt.Errorf("unexpected value for %v: %v", meta.Type, v)
+		}
+		b.Append(arrow.Time64(t.Nanos()))
+	case *array.TimestampBuilder:
+		t, ok := v.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected value for %v: %v", meta.Type, v)
+		}
+		nanos, ok := exportTimestampNanos(t)
+		if !ok {
+			return fmt.Errorf("timestamp out of the range of Arrow nanoseconds, 1677-09-21 to 2262-04-11: %v", t)
+		}
+		b.Append(arrow.Timestamp(nanos))
+	case *array.StringBuilder:
+		b.Append(exportText(meta, v))
+	}
+	return nil
+}
+
+// exportTimestampNanos returns the nanoseconds of t since the Unix epoch, or false if they overflow int64, which
+// t.UnixNano doesn't report.
+func exportTimestampNanos(t time.Time) (int64, bool) {
+	const second = int64(time.Second)
+	sec, nsec := t.Unix(), int64(t.Nanosecond())
+	if sec < 0 && nsec > 0 {
+		// so both have the same sign, e.g., -1.5 seconds is -1 second and -0.5 seconds, not -2 and 0.5
+		sec++
+		nsec -= second
+	}
+	if sec >= 0 && sec > (math.MaxInt64-nsec)/second || sec < 0 && sec < (math.MinInt64-nsec)/second {
+		return 0, false
+	}
+	return sec*second + nsec, true
+}
+
+// exportDecimal converts a decimal text to an Arrow decimal of the scale.
+func exportDecimal(text string, scale int) (decimal128.Num, error) {
+	digits := text
+	if i := strings.IndexByte(text, '.'); i >= 0 {
+		fraction := text[i+1:]
+		if len(fraction) > scale {
+			fraction = fraction[:scale]
+		}
+		digits = text[:i] + fraction + strings.Repeat("0", scale-len(fraction))
+	} else {
+		digits += strings.Repeat("0", scale)
+	}
+	num, ok := stringIntToDecimal(digits)
+	if !ok {
+		return num, fmt.Errorf("invalid decimal: %v", text)
+	}
+	return num, nil
+}
+
+func (rw *arrowRowWriter) flush() error {
+	cols := make([]array.Interface, len(rw.builders))
+	for i, b := range rw.builders {
+		cols[i] = b.NewArray()
+	}
+	rec := array.NewRecord(rw.schema, cols, int64(rw.rows))
+	for _, col := range cols {
+		col.Release()
+	}
+	defer rec.Release()
+	rw.rows = 0
+	return rw.w.Write(rec)
+}
+
+func (rw *arrowRowWriter) close() error {
+	if rw.rows > 0 {
+		if err := rw.flush(); err != nil {
+			return err
+		}
+	}
+	for _, b := range rw.builders {
+		b.Release()
+	}
+	return rw.w.Close()
+}
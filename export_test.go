@@ -0,0 +1,178 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newExportTestRows(t *testing.T) *snowflakeRows {
+	str := func(s string) *string {
+		return &s
+	}
+	rt := []execResponseRowType{
+		{Name: "ID", Type: "fixed", Precision: 38, Scale: 0, Nullable: false},
+		{Name: "AMOUNT", Type: "fixed", Precision: 38, Scale: 2, Nullable: true},
+		{Name: "RATIO", Type: "real", Nullable: true},
+		{Name: "NAME", Type: "text", Nullable: true},
+		{Name: "FLAG", Type: "boolean", Nullable: true},
+		{Name: "DAY", Type: "date", Nullable: true},
+		{Name: "TS", Type: "timestamp_ntz", Nullable: true},
+		{Name: "DATA", Type: "binary", Nullable: true},
+		{Name: "DOC", Type: "variant", Nullable: true},
+	}
+	rowSet := [][]*string{
+		{str("1"), str("12345678901234567890.12"), str("0.5"), str("a,b"), str("1"), str("18262"),
+			str("1577934245.123456789"), str("CAFE"), str(`{"k":[1,2]}`)},
+		{str("2"), nil, nil, nil, nil, nil, nil, nil, nil},
+	}
+	rows := new(snowflakeRows)
+	rows.RowType = rt
+	rows.ChunkDownloader = &snowflakeChunkDownloader{
+		ctx:           context.Background(),
+		Total:         int64(len(rowSet)),
+		TotalRowIndex: int64(-1),
+		RowSet:        rowSetType{RowType: rt, JSON: rowSet},
+	}
+	if err := rows.ChunkDownloader.start(); err != nil {
+		t.Fatalf("failed to start chunk downloader. err: %v", err)
+	}
+	return rows
+}
+
+func TestExportCSV(t *testing.T) {
+	rows := newExportTestRows(t)
+	var buf bytes.Buffer
+	n, err := rows.Export(&buf, &ExportOptions{
+		Format: ExportFormatCSV,
+		CSV:    CSVDialect{Delimiter: ';', Header: true, Null: `\N`},
+	})
+	if err != nil {
+		t.Fatalf("failed to export. err: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("unexpected number of rows. expected: %v, got: %v", 2, n)
+	}
+	expected := `ID;AMOUNT;RATIO;NAME;FLAG;DAY;TS;DATA;DOC
+1;12345678901234567890.12;0.5;a,b;true;2020-01-01;2020-01-02 03:04:05.123456789;CAFE;"{""k"":[1,2]}"
+2;\N;\N;\N;\N;\N;\N;\N;\N
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected CSV. expected:\n%v\ngot:\n%v", expected, buf.String())
+	}
+}
+
+func TestExportNDJSON(t *testing.T) {
+	rows := newExportTestRows(t)
+	var buf bytes.Buffer
+	if _, err := rows.Export(&buf, &ExportOptions{Format: ExportFormatNDJSON}); err != nil {
+		t.Fatalf("failed to export. err: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected number of lines. expected: %v, got: %v", 2, len(lines))
+	}
+	expected := `{"ID":1,"AMOUNT":12345678901234567890.12,"RATIO":0.5,"NAME":"a,b","FLAG":true,"DAY":"2020-01-01",` +
+		`"TS":"2020-01-02 03:04:05.123456789","DATA":"CAFE","DOC":{"k":[1,2]}}`
+	if lines[0] != expected {
+		t.Fatalf("unexpected line. expected: %v, got: %v", expected, lines[0])
+	}
+	var row map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &row); err != nil {
+		t.Fatalf("failed to parse line. err: %v", err)
+	}
+	if row["ID"] != float64(2) || row["NAME"] != nil || len(row) != 9 {
+		t.Fatalf("unexpected row: %v", row)
+	}
+}
+
+func TestExportArrow(t *testing.T) {
+	rows := newExportTestRows(t)
+	var buf bytes.Buffer
+	if _, err := rows.Export(&buf, &ExportOptions{Format: ExportFormatArrow, ArrowBatchSize: 1}); err != nil {
+		t.Fatalf("failed to export. err: %v", err)
+	}
+	r, err := ipc.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("failed to read Arrow stream. err: %v", err)
+	}
+	defer r.Release()
+	expectedTypes := []arrow.Type{arrow.DECIMAL, arrow.DECIMAL, arrow.FLOAT64, arrow.STRING, arrow.BOOL,
+		arrow.DATE32, arrow.TIMESTAMP, arrow.BINARY, arrow.STRING}
+	for i, f := range r.Schema().Fields() {
+		if f.Type.ID() != expectedTypes[i] {
+			t.Fatalf("unexpected type of %v. expected: %v, got: %v", f.Name, expectedTypes[i], f.Type)
+		}
+	}
+	if !r.Next() {
+		t.Fatalf("no record. err: %v", r.Err())
+	}
+	rec := r.Record()
+	if rec.NumRows() != 1 {
+		t.Fatalf("unexpected number of rows. expected: %v, got: %v", 1, rec.NumRows())
+	}
+	amount := decimalToBigInt(rec.Column(1).(*array.Decimal128).Value(0))
+	if amount.String() != "1234567890123456789012" {
+		t.Fatalf("unexpected decimal: %v", amount)
+	}
+	if v := rec.Column(5).(*array.Date32).Value(0); v != 18262 {
+		t.Fatalf("unexpected date: %v", v)
+	}
+	ts := time.Unix(0, int64(rec.Column(6).(*array.Timestamp).Value(0))).UTC()
+	if !ts.Equal(time.Date(2020, 1, 2, 3, 4, 5, 123456789, time.UTC)) {
+		t.Fatalf("unexpected timestamp: %v", ts)
+	}
+	if v := rec.Column(7).(*array.Binary).Value(0); !bytes.Equal(v, []byte{0xca, 0xfe}) {
+		t.Fatalf("unexpected binary: %v", v)
+	}
+	if !r.Next() {
+		t.Fatalf("no record. err: %v", r.Err())
+	}
+	rec = r.Record()
+	for i := 1; i < int(rec.NumCols()); i++ {
+		if !rec.Column(i).IsNull(0) {
+			t.Fatalf("column %v should be null", rec.ColumnName(i))
+		}
+	}
+	if r.Next() {
+		t.Fatal("should be the end of the stream")
+	}
+}
+
+func TestExportTimestampNanos(t *testing.T) {
+	testcases := []struct {
+		t  time.Time
+		ok bool
+	}{
+		{time.Date(2020, 1, 2, 3, 4, 5, 123456789, time.UTC), true},
+		{time.Unix(0, math.MaxInt64), true},
+		{time.Unix(0, math.MinInt64), true},
+		{time.Unix(0, math.MaxInt64).Add(time.Nanosecond), false},
+		{time.Unix(0, math.MinInt64).Add(-time.Nanosecond), false},
+		{time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC), false},
+	}
+	for _, test := range testcases {
+		nanos, ok := exportTimestampNanos(test.t)
+		if ok != test.ok || ok && !time.Unix(0, nanos).Equal(test.t) {
+			t.Errorf("unexpected nanoseconds of %v: %v, %v", test.t, nanos, ok)
+		}
+	}
+
+	b := array.NewTimestampBuilder(memory.NewGoAllocator(), &arrow.TimestampType{Unit: arrow.Nanosecond})
+	defer b.Release()
+	meta := &execResponseRowType{Name: "TS", Type: "timestamp_ntz"}
+	if err := appendExportArrowValue(b, meta, time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Fatal("should fail with a timestamp out of the range")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// fakeQueryHandler answers a query with the bindings, returning the data of the query response or an error.
+type fakeQueryHandler func(query string, bindings map[string]execBindParameter) (*execResponseData, error)
+
+// fakeConnector connects to a connection whose queries are answered by handler instead of the server. The queries
+// run through the driver connection, so that the rows, results and session state are those of a real query.
+type fakeConnector struct {
+	handler fakeQueryHandler
+	sc      *snowflakeConn // the connection to answer, or a new one if nil
+}
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	sc := c.sc
+	if sc == nil {
+		sc = newFileTransferTestConn(nil)
+	}
+	sc.rest.FuncPostQuery = func(_ context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string, body []byte, _ time.Duration, _ string) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		data, err := c.handler(req.SQLText, req.Bindings)
+		if err != nil {
+			return nil, err
+		}
+		return &execResponse{Data: *data, Success: true}, nil
+	}
+	sc.rest.FuncCloseSession = closeSessionMock
+	return sc, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return SnowflakeDriver{}
+}
+
+// newFakeRowsConnector returns a fakeConnector answering every query with the rows of text columns.
+func newFakeRowsConnector(columns []string, rowSet [][]*string) *fakeConnector {
+	return &fakeConnector{handler: func(string, map[string]execBindParameter) (*execResponseData, error) {
+		return newFakeTextResult(columns, rowSet), nil
+	}}
+}
+
+// newFakeTextResult returns the result of the rows of text columns, as SHOW and COPY return.
+func newFakeTextResult(columns []string, rowSet [][]*string) *execResponseData {
+	rowType := make([]execResponseRowType, len(columns))
+	for i, name := range columns {
+		rowType[i] = execResponseRowType{Name: name, Type: "text", Nullable: true}
+	}
+	return newFakeResult(rowType, rowSet)
+}
+
+// newFakeResult returns the JSON result of the rows of the columns of rowType.
+func newFakeResult(rowType []execResponseRowType, rowSet [][]*string) *execResponseData {
+	return &execResponseData{
+		RowType:           rowType,
+		RowSet:            rowSet,
+		Total:             int64(len(rowSet)),
+		Returned:          int64(len(rowSet)),
+		QueryResultFormat: "json",
+	}
+}
@@ -0,0 +1,642 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	fileTransferCommandUpload   = "UPLOAD"
+	fileTransferCommandDownload = "DOWNLOAD"
+
+	fileTransferStatusUploaded   = "UPLOADED"
+	fileTransferStatusDownloaded = "DOWNLOADED"
+	fileTransferStatusSkipped    = "SKIPPED"
+	fileTransferStatusError      = "ERROR"
+
+	compressionAutoDetect = "AUTO_DETECT"
+	compressionNone       = "NONE"
+	compressionGzip       = "GZIP"
+
+	defaultFileTransferParallel = 4
+)
+
+// compressionType is a compression, or a compressed file format, that PUT recognizes by file extension.
+type compressionType struct {
+	name      string // as in the status rows
+	alias     string // as in SOURCE_COMPRESSION, if different
+	extension string
+}
+
+var compressionTypes = []compressionType{
+	{name: compressionGzip, extension: ".gz"},
+	{name: "BZIP2", alias: "BZ2", extension: ".bz2"},
+	{name: "BROTLI", extension: ".br"},
+	{name: "ZSTD", extension: ".zst"},
+	{name: "DEFLATE", extension: ".deflate"},
+	{name: "RAW_DEFLATE", extension: ".raw_deflate"},
+	{name: "PARQUET", extension: ".parquet"},
+	{name: "ORC", extension: ".orc"},
+}
+
+// lookupCompressionType returns the compression type of the name or alias.
+func lookupCompressionType(name string) (compressionType, bool) {
+	for _, ct := range compressionTypes {
+		if strings.EqualFold(ct.name, name) || ct.alias != "" && strings.EqualFold(ct.alias, name) {
+			return ct, true
+		}
+	}
+	return compressionType{}, false
+}
+
+// detectCompressionType returns the compression type of the file extension, if any.
+func detectCompressionType(fileName string) (compressionType, bool) {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	for _, ct := range compressionTypes {
+		if ct.extension == ext {
+			return ct, true
+		}
+	}
+	return compressionType{}, false
+}
+
+// fileMetadata is a file of a transfer and its status.
+type fileMetadata struct {
+	srcFileName     string // path of the local file to upload, or the file on the stage to download
+	srcFileSize     int64
+	dstFileName     string // name on the stage, or of the downloaded local file
+	dstFileSize     int64
+	material        *snowflakeFileEncryption // to decrypt the file to download
+	srcCompression  string
+	dstCompression  string
+	requireCompress bool
+	status          string
+	message         string
+}
+
+// snowflakeFileTransferAgent runs the file transfer of a PUT or GET command with the response of the server.
+type snowflakeFileTransferAgent struct {
+	ctx   context.Context
+	sc    *snowflakeConn
+	data  *execResponseData
+	files []*fileMetadata
+}
+
+// processFileTransfer transfers the files of a PUT or GET command and replaces the result set of data with the
+// status of each file.
+func (sc *snowflakeConn) processFileTransfer(ctx context.Context, data *execResponseData) error {
+	sfa := &snowflakeFileTransferAgent{ctx: ctx, sc: sc, data: data}
+	if data.Command == fileTransferCommandDownload {
+		if err := sfa.download(); err != nil {
+			return err
+		}
+		sfa.setDownloadResult()
+		return nil
+	}
+	if err := sfa.upload(); err != nil {
+		return err
+	}
+	sfa.setResult()
+	return nil
+}
+
+func (sfa *snowflakeFileTransferAgent) upload() error {
+	client, err := newStorageClient(sfa.sc, &sfa.data.StageInfo)
+	if err != nil {
+		return err
+	}
+	var material *snowflakeFileEncryption
+	if sfa.data.StageInfo.IsClientSideEncrypted && len(sfa.data.EncryptionMaterial) > 0 {
+		material = sfa.data.EncryptionMaterial[0]
+	}
+	if stream, ok := sfa.ctx.Value(SnowflakeFileStreamKey).(io.Reader); ok && stream != nil {
+		meta, err := sfa.uploadStreamFile()
+		if err != nil {
+			return err
+		}
+		sfa.files = []*fileMetadata{meta}
+		sfa.uploadOneStream(client, material, meta, stream, "")
+		return nil
+	}
+	if sfa.files, err = sfa.uploadFiles(); err != nil {
+		return err
+	}
+	sfa.transfer(func(meta *fileMetadata) {
+		sfa.uploadOneFile(client, material, meta)
+	})
+	return nil
+}
+
+// sourceCompression returns the SOURCE_COMPRESSION of the PUT command, and the compression type unless it is
+// AUTO_DETECT or NONE.
+func (sfa *snowflakeFileTransferAgent) sourceCompression() (string, compressionType, error) {
+	srcCompression := strings.ToUpper(sfa.data.SourceCompression)
+	if srcCompression == "" {
+		srcCompression = compressionAutoDetect
+	}
+	var given compressionType
+	if srcCompression != compressionAutoDetect && srcCompression != compressionNone {
+		var ok bool
+		if given, ok = lookupCompressionType(srcCompression); !ok {
+			return "", given, &SnowflakeError{
+				Number:      ErrInvalidCompressionType,
+				Message:     errMsgInvalidCompressionType,
+				MessageArgs: []interface{}{sfa.data.SourceCompression},
+			}
+		}
+	}
+	return srcCompression, given, nil
+}
+
+// newUploadFile returns the file to upload from path with how it is compressed.
+func (sfa *snowflakeFileTransferAgent) newUploadFile(path string, srcCompression string,
+	given compressionType) *fileMetadata {
+	meta := &fileMetadata{
+		srcFileName:    path,
+		dstFileName:    filepath.Base(path),
+		srcCompression: compressionNone,
+	}
+	switch srcCompression {
+	case compressionAutoDetect:
+		if ct, ok := detectCompressionType(path); ok {
+			meta.srcCompression = ct.name
+		}
+	case compressionNone:
+	default:
+		meta.srcCompression = given.name
+	}
+	meta.dstCompression = meta.srcCompression
+	if meta.srcCompression == compressionNone && sfa.data.AutoCompress {
+		meta.requireCompress = true
+		meta.dstFileName += ".gz"
+		meta.dstCompression = compressionGzip
+	}
+	return meta
+}
+
+// uploadStreamFile returns the file to upload from the stream, named as the file of the source location.
+func (sfa *snowflakeFileTransferAgent) uploadStreamFile() (*fileMetadata, error) {
+	srcCompression, given, err := sfa.sourceCompression()
+	if err != nil {
+		return nil, err
+	}
+	if len(sfa.data.SrcLocations) != 1 {
+		return nil, &SnowflakeError{
+			Number:      ErrFileNotExists,
+			Message:     errMsgFileNotExists,
+			MessageArgs: []interface{}{strings.Join(sfa.data.SrcLocations, ", ")},
+		}
+	}
+	path, err := localPath(sfa.data.SrcLocations[0])
+	if err != nil {
+		return nil, err
+	}
+	return sfa.newUploadFile(path, srcCompression, given), nil
+}
+
+// uploadFiles returns the files matching the source locations with how each of them is compressed.
+func (sfa *snowflakeFileTransferAgent) uploadFiles() ([]*fileMetadata, error) {
+	srcCompression, given, err := sfa.sourceCompression()
+	if err != nil {
+		return nil, err
+	}
+	var files []*fileMetadata
+	for _, location := range sfa.data.SrcLocations {
+		pattern, err := localPath(location)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		var found bool
+		for _, path := range matches {
+			fi, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if fi.IsDir() {
+				continue
+			}
+			found = true
+			meta := sfa.newUploadFile(path, srcCompression, given)
+			meta.srcFileSize = fi.Size()
+			files = append(files, meta)
+		}
+		if !found {
+			return nil, &SnowflakeError{
+				Number:      ErrFileNotExists,
+				Message:     errMsgFileNotExists,
+				MessageArgs: []interface{}{location},
+			}
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].srcFileName < files[j].srcFileName
+	})
+	return files, nil
+}
+
+// transfer runs fn for each file in parallel.
+func (sfa *snowflakeFileTransferAgent) transfer(fn func(meta *fileMetadata)) {
+	parallel := sfa.data.Parallel
+	if parallel <= 0 {
+		parallel = defaultFileTransferParallel
+	}
+	parallel = intMin(parallel, len(sfa.files))
+	jobs := make(chan *fileMetadata)
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for meta := range jobs {
+				fn(meta)
+			}
+		}()
+	}
+	for _, meta := range sfa.files {
+		jobs <- meta
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+func (sfa *snowflakeFileTransferAgent) download() error {
+	client, err := newStorageClient(sfa.sc, &sfa.data.StageInfo)
+	if err != nil {
+		return err
+	}
+	if gcs, ok := client.(*gcsClient); ok && len(sfa.data.PresignedURLs) > 0 {
+		gcs.presignedURLs = make(map[string]string, len(sfa.data.SrcLocations))
+		for i, location := range sfa.data.SrcLocations {
+			if i < len(sfa.data.PresignedURLs) {
+				gcs.presignedURLs[location] = sfa.data.PresignedURLs[i]
+			}
+		}
+	}
+	dir, err := localPath(sfa.data.LocalLocation)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	decompress, _ := sfa.ctx.Value(SnowflakeGetDecompressKey).(bool)
+	sfa.files = make([]*fileMetadata, len(sfa.data.SrcLocations))
+	for i, location := range sfa.data.SrcLocations {
+		meta := &fileMetadata{srcFileName: location, dstFileName: path.Base(location)}
+		if ct, ok := detectCompressionType(location); ok {
+			meta.srcCompression = ct.name
+		}
+		meta.dstCompression = meta.srcCompression
+		if decompress && meta.srcCompression == compressionGzip {
+			meta.requireCompress = true // decompress
+			meta.dstFileName = strings.TrimSuffix(meta.dstFileName, filepath.Ext(meta.dstFileName))
+			meta.dstCompression = ""
+		}
+		if i < len(sfa.data.EncryptionMaterial) {
+			meta.material = sfa.data.EncryptionMaterial[i]
+		}
+		sfa.files[i] = meta
+	}
+	sfa.transfer(func(meta *fileMetadata) {
+		sfa.downloadOneFile(client, dir, meta)
+	})
+	return nil
+}
+
+// downloadOneFile downloads, decrypts if it is encrypted, and decompresses if required, the file into dir, recording
+// the status in meta. The file is streamed from the storage to a temporary file, which is renamed once complete.
+func (sfa *snowflakeFileTransferAgent) downloadOneFile(client storageClient, dir string, meta *fileMetadata) {
+	body, header, err := client.downloadStream(sfa.ctx, meta.srcFileName)
+	if err != nil {
+		meta.fail(err)
+		return
+	}
+	defer body.Close()
+	src := &countingReader{r: body}
+	var r io.Reader = src
+	if header.encryptionMetadata != nil {
+		if meta.material == nil {
+			meta.fail(&SnowflakeError{
+				Number:      ErrNoEncryptionMaterial,
+				Message:     errMsgNoEncryptionMaterial,
+				MessageArgs: []interface{}{meta.srcFileName},
+			})
+			return
+		}
+		if r, err = newDecryptReader(meta.material, header.encryptionMetadata, r); err != nil {
+			meta.fail(err)
+			return
+		}
+	}
+	if meta.requireCompress {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			meta.fail(err)
+			return
+		}
+		defer gr.Close()
+		r = gr
+	}
+	dst := &countingReader{r: r}
+	if err = writeStreamAtomically(filepath.Join(dir, meta.dstFileName), dst); err != nil {
+		meta.fail(err)
+		return
+	}
+	meta.srcFileSize = src.n
+	meta.dstFileSize = dst.n
+	meta.status = fileTransferStatusDownloaded
+}
+
+// writeStreamAtomically writes what r reads to a temporary file in the directory of name and renames it to name, so
+// name has either the whole data or the previous content.
+func writeStreamAtomically(name string, r io.Reader) error {
+	f, err := ioutil.TempFile(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err = os.Rename(f.Name(), name); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// uploadOneFile uploads the local file, recording the status in meta. The file is streamed as uploadOneStream does,
+// after a pass to compute the digest if it is larger than a part.
+func (sfa *snowflakeFileTransferAgent) uploadOneFile(client storageClient, material *snowflakeFileEncryption,
+	meta *fileMetadata) {
+	f, err := os.Open(meta.srcFileName)
+	if err != nil {
+		meta.fail(err)
+		return
+	}
+	defer f.Close()
+	var digest string
+	if meta.srcFileSize > int64(fileStreamPartSize) {
+		if digest, err = streamDigest(f, meta.requireCompress); err != nil {
+			meta.fail(err)
+			return
+		}
+		if _, err = f.Seek(0, io.SeekStart); err != nil {
+			meta.fail(err)
+			return
+		}
+	}
+	sfa.uploadOneStream(client, material, meta, f, digest)
+}
+
+// streamDigest returns the base64 SHA-256 of what r reads, compressed by gzip if compress is true, as uploadData
+// computes it of the data to upload.
+func streamDigest(r io.Reader, compress bool) (string, error) {
+	h := sha256.New()
+	if !compress {
+		if _, err := io.Copy(h, r); err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+	}
+	w := gzip.NewWriter(h)
+	if _, err := io.Copy(w, r); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// uploadData compresses, encrypts if material isn't nil, and uploads data, recording the status in meta.
+func (sfa *snowflakeFileTransferAgent) uploadData(client storageClient, material *snowflakeFileEncryption,
+	meta *fileMetadata, data []byte) {
+	if skip, err := sfa.skipExisting(client, meta); skip || err != nil {
+		return
+	}
+	var err error
+	if meta.requireCompress {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err = w.Write(data); err != nil {
+			meta.fail(err)
+			return
+		}
+		if err = w.Close(); err != nil {
+			meta.fail(err)
+			return
+		}
+		data = buf.Bytes()
+	}
+	meta.dstFileSize = int64(len(data))
+	digest := sha256.Sum256(data)
+	header := &fileHeader{digest: base64.StdEncoding.EncodeToString(digest[:]), contentLength: meta.dstFileSize}
+	if material != nil {
+		if data, header.encryptionMetadata, err = encryptData(material, data); err != nil {
+			meta.fail(err)
+			return
+		}
+	}
+	if err = client.uploadFile(sfa.ctx, meta.dstFileName, data, header); err != nil {
+		meta.fail(err)
+		return
+	}
+	meta.status = fileTransferStatusUploaded
+}
+
+// uploadOneStream uploads what stream reads as uploadData does, but compressing and encrypting the stream on the fly
+// and uploading it by parts, unless the stream fits in a single part. The digest of a stream isn't known until it is
+// uploaded, so the file has digest, which may be empty for none.
+func (sfa *snowflakeFileTransferAgent) uploadOneStream(client storageClient, material *snowflakeFileEncryption,
+	meta *fileMetadata, stream io.Reader, digest string) {
+	first := make([]byte, fileStreamPartSize)
+	n, err := io.ReadFull(stream, first)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		meta.srcFileSize = int64(n)
+		sfa.uploadData(client, material, meta, first[:n])
+		return
+	}
+	if err != nil {
+		meta.fail(err)
+		return
+	}
+	if skip, err := sfa.skipExisting(client, meta); skip || err != nil {
+		return
+	}
+
+	src := &countingReader{r: io.MultiReader(bytes.NewReader(first), stream)}
+	pr, pw := io.Pipe()
+	header := &fileHeader{digest: digest}
+	var w io.Writer = pw
+	var closers []io.Closer // in the order to close
+	if material != nil {
+		var ew *encryptWriter
+		if ew, header.encryptionMetadata, err = newEncryptWriter(material, pw); err != nil {
+			meta.fail(err)
+			return
+		}
+		w = ew
+		closers = append(closers, ew)
+	}
+	if meta.requireCompress {
+		gw := gzip.NewWriter(w)
+		w = gw
+		closers = append([]io.Closer{gw}, closers...)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := io.Copy(w, src)
+		for _, c := range closers {
+			if err == nil {
+				err = c.Close()
+			}
+		}
+		pw.CloseWithError(err)
+	}()
+	dst := &countingReader{r: pr}
+	err = client.uploadStream(sfa.ctx, meta.dstFileName, dst, header)
+	pr.Close() // stops the writer if the upload failed
+	<-done
+	meta.srcFileSize = src.n
+	meta.dstFileSize = dst.n
+	if err != nil {
+		meta.fail(err)
+		return
+	}
+	meta.status = fileTransferStatusUploaded
+}
+
+// skipExisting records the SKIPPED status in meta and returns true if the file exists on the stage and OVERWRITE
+// isn't set, or records the error.
+func (sfa *snowflakeFileTransferAgent) skipExisting(client storageClient, meta *fileMetadata) (bool, error) {
+	if sfa.data.Overwrite {
+		return false, nil
+	}
+	existing, err := client.getFileHeader(sfa.ctx, meta.dstFileName)
+	if err != nil {
+		meta.fail(err)
+		return false, err
+	}
+	if existing != nil {
+		meta.status = fileTransferStatusSkipped
+		meta.message = "file exists on the stage; use OVERWRITE = TRUE to replace it"
+		return true, nil
+	}
+	return false, nil
+}
+
+func (meta *fileMetadata) fail(err error) {
+	meta.status = fileTransferStatusError
+	meta.message = err.Error()
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// setResult replaces the result set of the response with the status of each uploaded file.
+func (sfa *snowflakeFileTransferAgent) setResult() {
+	sfa.data.RowType = []execResponseRowType{
+		{Name: "source", Type: "text", Length: 10000},
+		{Name: "target", Type: "text", Length: 10000},
+		{Name: "source_size", Type: "fixed", Precision: 64},
+		{Name: "target_size", Type: "fixed", Precision: 64},
+		{Name: "source_compression", Type: "text", Length: 10000},
+		{Name: "target_compression", Type: "text", Length: 10000},
+		{Name: "status", Type: "text", Length: 10000},
+		{Name: "message", Type: "text", Length: 10000},
+	}
+	rowSet := make([][]*string, len(sfa.files))
+	for i, meta := range sfa.files {
+		srcSize := strconv.FormatInt(meta.srcFileSize, 10)
+		dstSize := strconv.FormatInt(meta.dstFileSize, 10)
+		rowSet[i] = []*string{
+			stringPtr(filepath.Base(meta.srcFileName)), stringPtr(meta.dstFileName), &srcSize, &dstSize,
+			stringPtr(meta.srcCompression), stringPtr(meta.dstCompression), stringPtr(meta.status),
+			stringPtr(meta.message),
+		}
+	}
+	sfa.setRowSet(rowSet)
+}
+
+// setDownloadResult replaces the result set of the response with the status of each downloaded file.
+func (sfa *snowflakeFileTransferAgent) setDownloadResult() {
+	sfa.data.RowType = []execResponseRowType{
+		{Name: "file", Type: "text", Length: 10000},
+		{Name: "size", Type: "fixed", Precision: 64},
+		{Name: "status", Type: "text", Length: 10000},
+		{Name: "message", Type: "text", Length: 10000},
+	}
+	rowSet := make([][]*string, len(sfa.files))
+	for i, meta := range sfa.files {
+		size := strconv.FormatInt(meta.dstFileSize, 10)
+		rowSet[i] = []*string{stringPtr(meta.dstFileName), &size, stringPtr(meta.status), stringPtr(meta.message)}
+	}
+	sfa.setRowSet(rowSet)
+}
+
+func (sfa *snowflakeFileTransferAgent) setRowSet(rowSet [][]*string) {
+	sfa.data.RowSet = rowSet
+	sfa.data.RowSetBase64 = ""
+	sfa.data.Chunks = nil
+	sfa.data.Total = int64(len(rowSet))
+	sfa.data.Returned = int64(len(rowSet))
+	sfa.data.QueryResultFormat = "json"
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// localPath returns the path of a local location of a file transfer, removing the file:// prefix and expanding ~.
+func localPath(location string) (string, error) {
+	if len(location) >= 7 && strings.EqualFold(location[:7], "file://") {
+		location = location[7:]
+	}
+	return expandUser(location)
+}
+
+// expandUser replaces the leading ~ of path with the home directory.
+func expandUser(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
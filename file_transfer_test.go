@@ -0,0 +1,639 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// s3StandIn is an in-memory S3 for the stand-in of the storage of a stage.
+type s3StandIn struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	headers map[string]http.Header
+	uploads map[string]*s3StandInUpload // multipart uploads by ID
+}
+
+type s3StandInUpload struct {
+	header http.Header
+	parts  map[int][]byte
+}
+
+func newS3StandIn() *s3StandIn {
+	return &s3StandIn{
+		objects: make(map[string][]byte),
+		headers: make(map[string]http.Header),
+		uploads: make(map[string]*s3StandInUpload),
+	}
+}
+
+func (s *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), s3SigningAlgo+" Credential=KEYID/") ||
+		r.Header.Get("x-amz-security-token") != "TOKEN" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case "HEAD", "GET":
+		header, ok := s.headers[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for k, v := range header {
+			if strings.HasPrefix(strings.ToLower(k), s3MetaPrefix) {
+				w.Header()[k] = v
+			}
+		}
+		if r.Method == "GET" {
+			w.Write(s.objects[r.URL.Path])
+		}
+	case "PUT":
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if uploadID := r.URL.Query().Get("uploadId"); uploadID != "" {
+			upload, ok := s.uploads[uploadID]
+			number, _ := strconv.Atoi(r.URL.Query().Get("partNumber"))
+			if !ok || number < 1 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			upload.parts[number] = b
+			w.Header().Set("ETag", `"etag-`+strconv.Itoa(number)+`"`)
+			return
+		}
+		s.objects[r.URL.Path] = b
+		s.headers[r.URL.Path] = r.Header.Clone()
+	case "POST":
+		if _, ok := r.URL.Query()["uploads"]; ok {
+			uploadID := "upload-" + strconv.Itoa(len(s.uploads)+1)
+			s.uploads[uploadID] = &s3StandInUpload{header: r.Header.Clone(), parts: make(map[int][]byte)}
+			w.Write([]byte("<InitiateMultipartUploadResult><UploadId>" + uploadID +
+				"</UploadId></InitiateMultipartUploadResult>"))
+			return
+		}
+		upload, ok := s.uploads[r.URL.Query().Get("uploadId")]
+		var complete s3CompleteMultipartUpload
+		b, _ := ioutil.ReadAll(r.Body)
+		if !ok || xml.Unmarshal(b, &complete) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var object []byte
+		for i, part := range complete.Parts {
+			if part.PartNumber != i+1 || part.ETag != `"etag-`+strconv.Itoa(i+1)+`"` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			object = append(object, upload.parts[part.PartNumber]...)
+		}
+		delete(s.uploads, r.URL.Query().Get("uploadId"))
+		s.objects[r.URL.Path] = object
+		s.headers[r.URL.Path] = upload.header
+		w.Write([]byte("<CompleteMultipartUploadResult></CompleteMultipartUploadResult>"))
+	case "DELETE":
+		delete(s.uploads, r.URL.Query().Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// newFileTransferTestConn returns a connection whose queries get the response of body.
+func newFileTransferTestConn(body func() string) *snowflakeConn {
+	sr := &snowflakeRestful{
+		Client: &http.Client{},
+		FuncPostQuery: func(_ context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string, _ []byte, _ time.Duration, _ string) (*execResponse, error) {
+			var resp execResponse
+			if err := json.Unmarshal([]byte(body()), &resp); err != nil {
+				return nil, err
+			}
+			return &resp, nil
+		},
+		RequestTimeout: 10 * time.Second,
+	}
+	return &snowflakeConn{cfg: &Config{Params: map[string]*string{}}, rest: sr}
+}
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readFileTransferRows reads the status rows of a PUT or GET command.
+func readFileTransferRows(t *testing.T, rows driver.Rows) [][]driver.Value {
+	defer rows.Close()
+	var result [][]driver.Value
+	for {
+		dest := make([]driver.Value, len(rows.Columns()))
+		if err := rows.Next(dest); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("failed to get a row. err: %v", err)
+		}
+		result = append(result, dest)
+	}
+	return result
+}
+
+func TestPutS3(t *testing.T) {
+	dir, err := ioutil.TempDir("", "put")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{"data1.csv": "1,a\n2,b\n", "data2.csv": "3,c\n", "data3.csv.gz": "x"})
+
+	s3 := newS3StandIn()
+	ts := httptest.NewServer(s3)
+	defer ts.Close()
+	masterKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 16))
+	response := map[string]interface{}{
+		"data": map[string]interface{}{
+			"command":           "UPLOAD",
+			"src_locations":     []string{"file://" + filepath.Join(dir, "data*.csv*")},
+			"parallel":          2,
+			"autoCompress":      true,
+			"sourceCompression": "auto_detect",
+			"stageInfo": map[string]interface{}{
+				"locationType":          "S3",
+				"location":              "bucket1/stages/abc/",
+				"region":                "us-west-2",
+				"endPoint":              ts.URL,
+				"isClientSideEncrypted": true,
+				"creds":                 map[string]string{"AWS_KEY_ID": "KEYID", "AWS_SECRET_KEY": "SECRET", "AWS_TOKEN": "TOKEN"},
+			},
+			"encryptionMaterial": map[string]interface{}{"queryStageMasterKey": masterKey, "queryId": "01-put", "smkId": 1},
+			"queryId":            "01-put",
+		},
+		"success": true,
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := newFileTransferTestConn(func() string { return string(body) })
+	rows, err := sc.QueryContext(context.Background(), "PUT file://"+dir+"/data*.csv* @~", nil)
+	if err != nil {
+		t.Fatalf("failed to put. err: %v", err)
+	}
+	result := readFileTransferRows(t, rows)
+	expected := [][]string{
+		{"data1.csv", "data1.csv.gz", "8", "NONE", "GZIP", fileTransferStatusUploaded},
+		{"data2.csv", "data2.csv.gz", "4", "NONE", "GZIP", fileTransferStatusUploaded},
+		{"data3.csv.gz", "data3.csv.gz", "1", "GZIP", "GZIP", fileTransferStatusUploaded},
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("unexpected number of rows: %v", result)
+	}
+	for i, row := range result {
+		e := expected[i]
+		if row[0] != e[0] || row[1] != e[1] || row[2] != e[2] || row[4] != e[3] || row[5] != e[4] || row[6] != e[5] {
+			t.Errorf("unexpected row. expected: %v, got: %v", e, row)
+		}
+	}
+
+	material := &snowflakeFileEncryption{QueryStageMasterKey: masterKey, QueryID: "01-put", SMKID: 1}
+	key := "/bucket1/stages/abc/data1.csv.gz"
+	header := s3.headers[key]
+	meta := &encryptMetadata{
+		key:     header.Get(s3MetaKey),
+		iv:      header.Get(s3MetaIV),
+		matdesc: header.Get(s3MetaMatdesc),
+	}
+	decrypted, err := decryptTestData(material, meta, s3.objects[key])
+	if err != nil {
+		t.Fatalf("failed to decrypt. err: %v", err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(decrypted))
+	if err != nil {
+		t.Fatalf("failed to decompress. err: %v", err)
+	}
+	if b, err := ioutil.ReadAll(r); err != nil || string(b) != "1,a\n2,b\n" {
+		t.Fatalf("unexpected content: %q, err: %v", b, err)
+	}
+	if header.Get(s3MetaDigest) == "" || result[0][3] != strconv.Itoa(len(decrypted)) {
+		t.Fatalf("unexpected digest or target size: %v, %v", header.Get(s3MetaDigest), result[0][3])
+	}
+
+	// files on the stage are skipped without OVERWRITE
+	rows, err = sc.QueryContext(context.Background(), "PUT file://"+dir+"/data*.csv* @~", nil)
+	if err != nil {
+		t.Fatalf("failed to put. err: %v", err)
+	}
+	for _, row := range readFileTransferRows(t, rows) {
+		if row[6] != fileTransferStatusSkipped {
+			t.Errorf("should skip the existing file: %v", row)
+		}
+	}
+}
+
+func TestPutLocalFS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "put")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stage := filepath.Join(dir, "stage")
+	writeTestFiles(t, dir, map[string]string{"a.json": `{"a":1}`})
+
+	body := func(overwrite bool, compression string) func() string {
+		return func() string {
+			b, _ := json.Marshal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"command":           "UPLOAD",
+					"src_locations":     []string{filepath.Join(dir, "a.json")},
+					"autoCompress":      false,
+					"overwrite":         overwrite,
+					"sourceCompression": compression,
+					"stageInfo":         map[string]interface{}{"locationType": "LOCAL_FS", "location": stage},
+				},
+				"success": true,
+			})
+			return string(b)
+		}
+	}
+	for _, overwrite := range []bool{false, true} {
+		sc := newFileTransferTestConn(body(overwrite, "none"))
+		rows, err := sc.QueryContext(context.Background(), "PUT file://a.json @~", nil)
+		if err != nil {
+			t.Fatalf("failed to put. err: %v", err)
+		}
+		result := readFileTransferRows(t, rows)
+		if len(result) != 1 || result[0][1] != "a.json" || result[0][6] != fileTransferStatusUploaded {
+			t.Fatalf("unexpected result: %v", result)
+		}
+	}
+	if b, err := ioutil.ReadFile(filepath.Join(stage, "a.json")); err != nil || string(b) != `{"a":1}` {
+		t.Fatalf("unexpected content: %q, err: %v", b, err)
+	}
+
+	sc := newFileTransferTestConn(body(true, "lzma"))
+	if _, err = sc.QueryContext(context.Background(), "PUT file://a.json @~", nil); err == nil {
+		t.Fatal("should fail with an unsupported compression")
+	}
+	sc = newFileTransferTestConn(func() string {
+		return `{"data": {"command": "UPLOAD", "src_locations": ["` + filepath.ToSlash(filepath.Join(dir, "none*")) +
+			`"], "stageInfo": {"locationType": "LOCAL_FS", "location": "` + filepath.ToSlash(stage) + `"}}, "success": true}`
+	})
+	_, err = sc.QueryContext(context.Background(), "PUT file://none* @~", nil)
+	if driverErr, ok := err.(*SnowflakeError); !ok || driverErr.Number != ErrFileNotExists {
+		t.Fatalf("should fail with no file. err: %v", err)
+	}
+}
+
+func TestGetS3(t *testing.T) {
+	dir, err := ioutil.TempDir("", "get")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{"data1.csv": "1,a\n2,b\n"})
+
+	s3 := newS3StandIn()
+	ts := httptest.NewServer(s3)
+	defer ts.Close()
+	masterKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 16))
+	material := map[string]interface{}{"queryStageMasterKey": masterKey, "queryId": "01-get", "smkId": 1}
+	stageInfo := map[string]interface{}{
+		"locationType":          "S3",
+		"location":              "bucket1/stages/abc/",
+		"region":                "us-west-2",
+		"endPoint":              ts.URL,
+		"isClientSideEncrypted": true,
+		"creds":                 map[string]string{"AWS_KEY_ID": "KEYID", "AWS_SECRET_KEY": "SECRET", "AWS_TOKEN": "TOKEN"},
+	}
+	put, _ := json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{
+			"command":            "UPLOAD",
+			"src_locations":      []string{filepath.Join(dir, "data1.csv")},
+			"autoCompress":       true,
+			"stageInfo":          stageInfo,
+			"encryptionMaterial": material,
+		},
+		"success": true,
+	})
+	sc := newFileTransferTestConn(func() string { return string(put) })
+	rows, err := sc.QueryContext(context.Background(), "PUT file://data1.csv @~", nil)
+	if err != nil {
+		t.Fatalf("failed to put. err: %v", err)
+	}
+	readFileTransferRows(t, rows)
+
+	out := filepath.Join(dir, "out")
+	get, _ := json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{
+			"command":            "DOWNLOAD",
+			"src_locations":      []string{"data1.csv.gz", "missing.csv.gz"},
+			"localLocation":      "file://" + out,
+			"stageInfo":          stageInfo,
+			"encryptionMaterial": []interface{}{material, material},
+		},
+		"success": true,
+	})
+	sc = newFileTransferTestConn(func() string { return string(get) })
+	rows, err = sc.QueryContext(WithGetDecompress(context.Background()), "GET @~ file://out", nil)
+	if err != nil {
+		t.Fatalf("failed to get. err: %v", err)
+	}
+	result := readFileTransferRows(t, rows)
+	if len(result) != 2 || result[0][0] != "data1.csv" || result[0][1] != "8" ||
+		result[0][2] != fileTransferStatusDownloaded || result[1][2] != fileTransferStatusError {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if b, err := ioutil.ReadFile(filepath.Join(out, "data1.csv")); err != nil || string(b) != "1,a\n2,b\n" {
+		t.Fatalf("unexpected content: %q, err: %v", b, err)
+	}
+	entries, err := ioutil.ReadDir(out)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("should leave no temporary file: %v, err: %v", entries, err)
+	}
+
+	// an encrypted file can't be downloaded without the material
+	get, _ = json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{
+			"command":       "DOWNLOAD",
+			"src_locations": []string{"data1.csv.gz"},
+			"localLocation": out,
+			"stageInfo":     stageInfo,
+		},
+		"success": true,
+	})
+	rows, err = sc.QueryContext(context.Background(), "GET @~ file://out", nil)
+	if err != nil {
+		t.Fatalf("failed to get. err: %v", err)
+	}
+	result = readFileTransferRows(t, rows)
+	if len(result) != 1 || result[0][2] != fileTransferStatusError ||
+		!strings.Contains(result[0][3].(string), strconv.Itoa(ErrNoEncryptionMaterial)) {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
+
+func TestGetLocalFS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "get")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stage := filepath.Join(dir, "stage")
+	if err = os.MkdirAll(filepath.Join(stage, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFiles(t, stage, map[string]string{"sub/a.json": `{"a":1}`})
+
+	out := filepath.Join(dir, "out")
+	if err = os.MkdirAll(out, 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFiles(t, out, map[string]string{"a.json": "old"})
+	sc := newFileTransferTestConn(func() string {
+		b, _ := json.Marshal(map[string]interface{}{
+			"data": map[string]interface{}{
+				"command":       "DOWNLOAD",
+				"src_locations": []string{"sub/a.json"},
+				"localLocation": out,
+				"stageInfo":     map[string]interface{}{"locationType": "LOCAL_FS", "location": stage},
+			},
+			"success": true,
+		})
+		return string(b)
+	})
+	rows, err := sc.QueryContext(context.Background(), "GET @~/sub file://out", nil)
+	if err != nil {
+		t.Fatalf("failed to get. err: %v", err)
+	}
+	result := readFileTransferRows(t, rows)
+	if len(result) != 1 || result[0][0] != "a.json" || result[0][1] != "7" ||
+		result[0][2] != fileTransferStatusDownloaded {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if b, err := ioutil.ReadFile(filepath.Join(out, "a.json")); err != nil || string(b) != `{"a":1}` {
+		t.Fatalf("unexpected content: %q, err: %v", b, err)
+	}
+}
+
+func TestPutStreamS3(t *testing.T) {
+	defer func(partSize int) { fileStreamPartSize = partSize }(fileStreamPartSize)
+	fileStreamPartSize = 64
+
+	s3 := newS3StandIn()
+	ts := httptest.NewServer(s3)
+	defer ts.Close()
+	masterKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, 16))
+	material := map[string]interface{}{"queryStageMasterKey": masterKey, "queryId": "01-stream", "smkId": 1}
+	stageInfo := map[string]interface{}{
+		"locationType":          "S3",
+		"location":              "bucket1/stages/abc/",
+		"endPoint":              ts.URL,
+		"isClientSideEncrypted": true,
+		"creds":                 map[string]string{"AWS_KEY_ID": "KEYID", "AWS_SECRET_KEY": "SECRET", "AWS_TOKEN": "TOKEN"},
+	}
+	put, _ := json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{
+			"command":            "UPLOAD",
+			"src_locations":      []string{"file://orders.csv"},
+			"autoCompress":       true,
+			"overwrite":          true,
+			"stageInfo":          stageInfo,
+			"encryptionMaterial": material,
+		},
+		"success": true,
+	})
+	get := func(dir string) func() string {
+		return func() string {
+			b, _ := json.Marshal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"command":            "DOWNLOAD",
+					"src_locations":      []string{"orders.csv.gz"},
+					"localLocation":      dir,
+					"stageInfo":          stageInfo,
+					"encryptionMaterial": []interface{}{material},
+				},
+				"success": true,
+			})
+			return string(b)
+		}
+	}
+
+	var large strings.Builder
+	for i := 0; i < 1000; i++ {
+		large.WriteString(strconv.Itoa(i) + ",order" + strconv.Itoa(i*7919) + "\n")
+	}
+	for _, content := range []string{"1,a\n", large.String()} {
+		sc := newFileTransferTestConn(func() string { return string(put) })
+		ctx := WithFileStream(context.Background(), strings.NewReader(content))
+		rows, err := sc.QueryContext(ctx, "PUT file://orders.csv @~", nil)
+		if err != nil {
+			t.Fatalf("failed to put. err: %v", err)
+		}
+		result := readFileTransferRows(t, rows)
+		if len(result) != 1 || result[0][1] != "orders.csv.gz" || result[0][2] != strconv.Itoa(len(content)) ||
+			result[0][6] != fileTransferStatusUploaded {
+			t.Fatalf("unexpected result: %v", result)
+		}
+		if len(s3.uploads) != 0 {
+			t.Fatalf("should complete the multipart upload: %v", s3.uploads)
+		}
+
+		dir, err := ioutil.TempDir("", "stream")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		sc = newFileTransferTestConn(get(dir))
+		rows, err = sc.QueryContext(WithGetDecompress(context.Background()), "GET @~ file://out", nil)
+		if err != nil {
+			t.Fatalf("failed to get. err: %v", err)
+		}
+		if result = readFileTransferRows(t, rows); len(result) != 1 || result[0][2] != fileTransferStatusDownloaded {
+			t.Fatalf("unexpected result: %v", result)
+		}
+		if b, err := ioutil.ReadFile(filepath.Join(dir, "orders.csv")); err != nil || string(b) != content {
+			t.Fatalf("unexpected content: %q, err: %v", b, err)
+		}
+	}
+}
+
+func TestPutLargeFileS3(t *testing.T) {
+	defer func(partSize int) { fileStreamPartSize = partSize }(fileStreamPartSize)
+	fileStreamPartSize = 64
+	dir, err := ioutil.TempDir("", "put")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var large strings.Builder
+	for i := 0; i < 1000; i++ {
+		large.WriteString(strconv.Itoa(i) + ",order" + strconv.Itoa(i*7919) + "\n")
+	}
+	writeTestFiles(t, dir, map[string]string{"orders.csv": large.String()})
+
+	s3 := newS3StandIn()
+	ts := httptest.NewServer(s3)
+	defer ts.Close()
+	masterKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{4}, 16))
+	body, _ := json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{
+			"command":       "UPLOAD",
+			"src_locations": []string{"file://" + filepath.Join(dir, "orders.csv")},
+			"autoCompress":  true,
+			"stageInfo": map[string]interface{}{
+				"locationType":          "S3",
+				"location":              "bucket1/stages/abc/",
+				"endPoint":              ts.URL,
+				"isClientSideEncrypted": true,
+				"creds":                 map[string]string{"AWS_KEY_ID": "KEYID", "AWS_SECRET_KEY": "SECRET", "AWS_TOKEN": "TOKEN"},
+			},
+			"encryptionMaterial": map[string]interface{}{"queryStageMasterKey": masterKey, "queryId": "01-large", "smkId": 1},
+		},
+		"success": true,
+	})
+	sc := newFileTransferTestConn(func() string { return string(body) })
+	rows, err := sc.QueryContext(context.Background(), "PUT file://"+dir+"/orders.csv @~", nil)
+	if err != nil {
+		t.Fatalf("failed to put. err: %v", err)
+	}
+	result := readFileTransferRows(t, rows)
+	if len(result) != 1 || result[0][2] != strconv.Itoa(large.Len()) || result[0][6] != fileTransferStatusUploaded {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if len(s3.uploads) != 0 {
+		t.Fatalf("should complete the multipart upload: %v", s3.uploads)
+	}
+
+	key := "/bucket1/stages/abc/orders.csv.gz"
+	header := s3.headers[key]
+	material := &snowflakeFileEncryption{QueryStageMasterKey: masterKey, QueryID: "01-large", SMKID: 1}
+	meta := &encryptMetadata{
+		key:     header.Get(s3MetaKey),
+		iv:      header.Get(s3MetaIV),
+		matdesc: header.Get(s3MetaMatdesc),
+	}
+	decrypted, err := decryptTestData(material, meta, s3.objects[key])
+	if err != nil {
+		t.Fatalf("failed to decrypt. err: %v", err)
+	}
+	sum := sha256.Sum256(decrypted)
+	if digest := base64.StdEncoding.EncodeToString(sum[:]); header.Get(s3MetaDigest) != digest {
+		t.Fatalf("unexpected digest: %v, expected: %v", header.Get(s3MetaDigest), digest)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(decrypted))
+	if err != nil {
+		t.Fatalf("failed to decompress. err: %v", err)
+	}
+	if b, err := ioutil.ReadAll(r); err != nil || string(b) != large.String() {
+		t.Fatalf("unexpected content of %v bytes, err: %v", len(b), err)
+	}
+}
+
+func TestForEachPart(t *testing.T) {
+	testcases := []struct {
+		data  string
+		parts []string
+	}{
+		{"", []string{""}},
+		{"abc", []string{"abc"}},
+		{"abcd", []string{"abcd"}},
+		{"abcdefghij", []string{"abcd", "efgh", "ij"}},
+	}
+	for _, test := range testcases {
+		var parts []string
+		err := forEachPart(strings.NewReader(test.data), 4, func(number int, part []byte) error {
+			if number != len(parts)+1 {
+				t.Errorf("unexpected part number: %v", number)
+			}
+			parts = append(parts, string(part))
+			return nil
+		})
+		if err != nil || strings.Join(parts, "|") != strings.Join(test.parts, "|") {
+			t.Errorf("unexpected parts of %q: %q, err: %v", test.data, parts, err)
+		}
+	}
+}
+
+func TestDetectCompressionType(t *testing.T) {
+	testcases := map[string]string{
+		"a.csv":         "",
+		"a.csv.gz":      "GZIP",
+		"a.CSV.BZ2":     "BZIP2",
+		"a.parquet":     "PARQUET",
+		"a.raw_deflate": "RAW_DEFLATE",
+	}
+	for name, expected := range testcases {
+		ct, ok := detectCompressionType(name)
+		if ok != (expected != "") || ct.name != expected {
+			t.Errorf("unexpected compression type of %v: %v", name, ct.name)
+		}
+	}
+	if ct, ok := lookupCompressionType("bz2"); !ok || ct.name != "BZIP2" {
+		t.Errorf("unexpected compression type of bz2: %v", ct.name)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GeoFormat is a representation of GEOGRAPHY and GEOMETRY values, i.e., a value of the GEOGRAPHY_OUTPUT_FORMAT and
+// GEOMETRY_OUTPUT_FORMAT parameters.
+type GeoFormat string
+
+const (
+	// GeoFormatGeoJSON is GeoJSON text.
+	GeoFormatGeoJSON GeoFormat = "GeoJSON"
+	// GeoFormatWKT is Well-Known Text.
+	GeoFormatWKT GeoFormat = "WKT"
+	// GeoFormatEWKT is Extended Well-Known Text, i.e., WKT with an SRID prefix.
+	GeoFormatEWKT GeoFormat = "EWKT"
+	// GeoFormatWKB is Well-Known Binary.
+	GeoFormatWKB GeoFormat = "WKB"
+	// GeoFormatEWKB is Extended Well-Known Binary, i.e., WKB with an SRID.
+	GeoFormatEWKB GeoFormat = "EWKB"
+)
+
+const ewkbSRIDFlag = 0x20000000
+
+// isBinary returns true if the format is WKB or EWKB.
+func (f GeoFormat) isBinary() bool {
+	return strings.EqualFold(string(f), string(GeoFormatWKB)) || strings.EqualFold(string(f), string(GeoFormatEWKB))
+}
+
+// GeoValue is a GEOGRAPHY or GEOMETRY value in any of the output formats. It scans a column of either type and binds
+// to one, in which case Snowflake converts it from text, or hex for WKB and EWKB.
+type GeoValue struct {
+	Format GeoFormat
+	Data   []byte // GeoJSON or (E)WKT text, or (E)WKB
+	Valid  bool   // Valid is true if the value is not NULL
+}
+
+// NewGeoJSON returns a GeoValue of GeoJSON text.
+func NewGeoJSON(data []byte) GeoValue {
+	return GeoValue{Format: GeoFormatGeoJSON, Data: data, Valid: true}
+}
+
+// NewWKT returns a GeoValue of WKT or EWKT text.
+func NewWKT(text string) GeoValue {
+	return GeoValue{Format: detectGeoTextFormat(text), Data: []byte(text), Valid: true}
+}
+
+// NewWKB returns a GeoValue of WKB or EWKB.
+func NewWKB(data []byte) GeoValue {
+	return GeoValue{Format: detectGeoBinaryFormat(data), Data: data, Valid: true}
+}
+
+// Scan implements the sql.Scanner interface.
+func (g *GeoValue) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*g = GeoValue{}
+	case string:
+		if b, ok := geoHexToBytes(v); ok {
+			*g = NewWKB(b)
+			return nil
+		}
+		*g = NewWKT(v)
+	case []byte:
+		*g = NewWKB(append([]byte(nil), v...))
+	default:
+		return fmt.Errorf("cannot convert %T to GeoValue", src)
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (g GeoValue) Value() (driver.Value, error) {
+	if !g.Valid {
+		return nil, nil
+	}
+	return g.String(), nil
+}
+
+// String returns the text of the value, or the upper case hex of WKB and EWKB.
+func (g GeoValue) String() string {
+	if g.Format.isBinary() {
+		return strings.ToUpper(hex.EncodeToString(g.Data))
+	}
+	return string(g.Data)
+}
+
+// UnmarshalGeoJSON parses a GeoJSON value into v with json.Unmarshal.
+func (g GeoValue) UnmarshalGeoJSON(v interface{}) error {
+	if g.Format != GeoFormatGeoJSON {
+		return fmt.Errorf("not a GeoJSON value: %v", g.Format)
+	}
+	return json.Unmarshal(g.Data, v)
+}
+
+func detectGeoTextFormat(text string) GeoFormat {
+	text = strings.TrimSpace(text)
+	switch {
+	case strings.HasPrefix(text, "{"):
+		return GeoFormatGeoJSON
+	case len(text) > 5 && strings.EqualFold(text[:5], "SRID="):
+		return GeoFormatEWKT
+	}
+	return GeoFormatWKT
+}
+
+func detectGeoBinaryFormat(data []byte) GeoFormat {
+	if len(data) < 5 {
+		return GeoFormatWKB
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	if data[0] == 1 {
+		order = binary.LittleEndian
+	}
+	if order.Uint32(data[1:5])&ewkbSRIDFlag != 0 {
+		return GeoFormatEWKB
+	}
+	return GeoFormatWKB
+}
+
+// geoHexToBytes decodes the hex of WKB and EWKB that JSON result sets have. As WKB starts with the byte order, 00 or
+// 01, it never collides with GeoJSON or WKT.
+func geoHexToBytes(src string) ([]byte, bool) {
+	if len(src) < 2 || len(src)%2 != 0 || src[0] != '0' || (src[1] != '0' && src[1] != '1') {
+		return nil, false
+	}
+	b, err := hex.DecodeString(src)
+	return b, err == nil
+}
+
+// geoToValue converts a GEOGRAPHY or GEOMETRY value of a result set to []byte for WKB and EWKB, or leaves the text of
+// the other formats as is.
+func geoToValue(src string) snowflakeValue {
+	if b, ok := geoHexToBytes(src); ok {
+		return b
+	}
+	return src
+}
+
+// geoOutputFormat returns the session output format of dbtype, geography or geometry. GeoJSON is the default.
+func (sc *snowflakeConn) geoOutputFormat(dbtype string) GeoFormat {
+	if sc == nil || sc.cfg == nil {
+		return GeoFormatGeoJSON
+	}
+	if v, ok := sc.sessionParameter(dbtype + "_output_format"); ok && v != "" {
+		return GeoFormat(v)
+	}
+	return GeoFormatGeoJSON
+}
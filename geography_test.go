@@ -0,0 +1,158 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/hex"
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+	"reflect"
+	"testing"
+)
+
+const (
+	pointWKBHex  = "0101000000000000000000F03F0000000000000040"
+	pointEWKBHex = "0101000020E6100000000000000000F03F0000000000000040"
+	pointGeoJSON = `{"coordinates":[1,2],"type":"Point"}`
+)
+
+func TestGeoValueScan(t *testing.T) {
+	wkb, _ := hex.DecodeString(pointWKBHex)
+	ewkb, _ := hex.DecodeString(pointEWKBHex)
+	for _, tc := range []struct {
+		src    interface{}
+		format GeoFormat
+		data   []byte
+		valid  bool
+	}{
+		{src: nil},
+		{src: pointGeoJSON, format: GeoFormatGeoJSON, data: []byte(pointGeoJSON), valid: true},
+		{src: "POINT(1 2)", format: GeoFormatWKT, data: []byte("POINT(1 2)"), valid: true},
+		{src: "SRID=4326;POINT(1 2)", format: GeoFormatEWKT, data: []byte("SRID=4326;POINT(1 2)"), valid: true},
+		{src: pointWKBHex, format: GeoFormatWKB, data: wkb, valid: true},
+		{src: wkb, format: GeoFormatWKB, data: wkb, valid: true},
+		{src: ewkb, format: GeoFormatEWKB, data: ewkb, valid: true},
+	} {
+		var g GeoValue
+		if err := g.Scan(tc.src); err != nil {
+			t.Fatalf("failed to scan %v. err: %v", tc.src, err)
+		}
+		if g.Format != tc.format || !bytes.Equal(g.Data, tc.data) || g.Valid != tc.valid {
+			t.Errorf("unexpected value of %v. got: %+v", tc.src, g)
+		}
+	}
+	var g GeoValue
+	if err := g.Scan(int64(1)); err == nil {
+		t.Error("should fail to scan int64")
+	}
+}
+
+func TestGeoValueValue(t *testing.T) {
+	wkb, _ := hex.DecodeString(pointWKBHex)
+	for _, tc := range []struct {
+		in  GeoValue
+		out driver.Value
+	}{
+		{in: GeoValue{}, out: nil},
+		{in: NewWKT("POINT(1 2)"), out: "POINT(1 2)"},
+		{in: NewGeoJSON([]byte(pointGeoJSON)), out: pointGeoJSON},
+		{in: NewWKB(wkb), out: pointWKBHex},
+	} {
+		v, err := tc.in.Value()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v != tc.out {
+			t.Errorf("unexpected value of %+v. expected: %v, got: %v", tc.in, tc.out, v)
+		}
+	}
+}
+
+func TestGeoValueUnmarshalGeoJSON(t *testing.T) {
+	var point struct {
+		Type        string    `json:"type"`
+		Coordinates []float64 `json:"coordinates"`
+	}
+	if err := NewGeoJSON([]byte(pointGeoJSON)).UnmarshalGeoJSON(&point); err != nil {
+		t.Fatalf("failed to unmarshal. err: %v", err)
+	}
+	if point.Type != "Point" || !reflect.DeepEqual(point.Coordinates, []float64{1, 2}) {
+		t.Fatalf("unexpected point: %+v", point)
+	}
+	if err := NewWKT("POINT(1 2)").UnmarshalGeoJSON(&point); err == nil {
+		t.Fatal("should fail to unmarshal WKT")
+	}
+}
+
+func TestGeographyToValue(t *testing.T) {
+	wkb, _ := hex.DecodeString(pointWKBHex)
+	for _, typ := range []string{"geography", "geometry"} {
+		meta := execResponseRowType{Type: typ}
+		for _, tc := range []struct {
+			in  string
+			out snowflakeValue
+		}{
+			{in: pointGeoJSON, out: pointGeoJSON},
+			{in: "POINT(1 2)", out: "POINT(1 2)"},
+			{in: pointWKBHex, out: wkb},
+		} {
+			var dest driver.Value
+			src := tc.in
+			if err := stringToValue(&dest, meta, &src, nil, false, false); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(dest, tc.out) {
+				t.Errorf("stringToValue: %v, %v. expected: %v, got: %v", typ, tc.in, tc.out, dest)
+			}
+			v, err := bytesToValue(&meta, []byte(tc.in), nil, false, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(v, tc.out) {
+				t.Errorf("bytesToValue: %v, %v. expected: %v, got: %v", typ, tc.in, tc.out, v)
+			}
+		}
+
+		pool := memory.NewGoAllocator()
+		sb := array.NewStringBuilder(pool)
+		sb.AppendValues([]string{pointGeoJSON, ""}, []bool{true, false})
+		strArr := sb.NewArray()
+		dest := make([]snowflakeValue, 2)
+		if err := arrowToValue(&dest, execResponseRowType{Type: typ}, strArr, nil, false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dest[0] != pointGeoJSON || dest[1] != nil {
+			t.Errorf("arrowToValue: %v. unexpected values: %v", typ, dest)
+		}
+		strArr.Release()
+
+		bb := array.NewBinaryBuilder(pool, arrow.BinaryTypes.Binary)
+		bb.Append(wkb)
+		binArr := bb.NewArray()
+		dest = make([]snowflakeValue, 1)
+		if err := arrowToValue(&dest, execResponseRowType{Type: typ}, binArr, nil, false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(dest[0].([]byte), wkb) {
+			t.Errorf("arrowToValue: %v. unexpected value: %v", typ, dest[0])
+		}
+		binArr.Release()
+	}
+}
+
+func TestColumnTypeScanTypeGeography(t *testing.T) {
+	format := "WKB"
+	rows := &snowflakeRows{
+		sc:      &snowflakeConn{cfg: &Config{Params: map[string]*string{"geometry_output_format": &format}}},
+		RowType: []execResponseRowType{{Type: "geography"}, {Type: "geometry"}},
+	}
+	if st := rows.ColumnTypeScanType(0); st != reflect.TypeOf("") {
+		t.Errorf("unexpected scan type of GeoJSON. got: %v", st)
+	}
+	if st := rows.ColumnTypeScanType(1); st != reflect.TypeOf([]byte{}) {
+		t.Errorf("unexpected scan type of WKB. got: %v", st)
+	}
+}
@@ -30,6 +30,10 @@ type execResponseRowType struct {
 	Precision  int64  `json:"precision"`
 	Scale      int64  `json:"scale"`
 	Nullable   bool   `json:"nullable"`
+	Collation  string `json:"collation,omitempty"`
+	Database   string `json:"database,omitempty"`
+	Schema     string `json:"schema,omitempty"`
+	Table      string `json:"table,omitempty"`
 }
 
 type execResponseChunk struct {
@@ -61,6 +65,8 @@ type execResponseData struct {
 	Chunks             []execResponseChunk   `json:"chunks,omitempty"`
 	Qrmk               string                `json:"qrmk,omitempty"`
 	ChunkHeaders       map[string]string     `json:"chunkHeaders,omitempty"`
+	Stats              *execResponseStats    `json:"stats,omitempty"`
+	SendResultTime     int64                 `json:"sendResultTime,omitempty"` // java:long, epoch milliseconds
 
 	// ping pong response data
 	GetResultURL      string        `json:"getResultUrl,omitempty"`
@@ -69,6 +75,26 @@ type execResponseData struct {
 	ResultIDs         string        `json:"resultIds,omitempty"`
 	ResultTypes       string        `json:"resultTypes,omitempty"`
 	QueryResultFormat string        `json:"queryResultFormat,omitempty"`
+
+	// file transfer response data
+	Command            string                `json:"command,omitempty"` // UPLOAD or DOWNLOAD
+	SrcLocations       []string              `json:"src_locations,omitempty"`
+	LocalLocation      string                `json:"localLocation,omitempty"`
+	PresignedURLs      []string              `json:"presignedUrls,omitempty"`
+	Parallel           int                   `json:"parallel,omitempty"`
+	AutoCompress       bool                  `json:"autoCompress,omitempty"`
+	Overwrite          bool                  `json:"overwrite,omitempty"`
+	SourceCompression  string                `json:"sourceCompression,omitempty"`
+	StageInfo          execResponseStageInfo `json:"stageInfo,omitempty"`
+	EncryptionMaterial encryptionMaterials   `json:"encryptionMaterial,omitempty"`
+}
+
+// execResponseStats is the number of rows a DML statement changed.
+type execResponseStats struct {
+	NumRowsInserted  int64 `json:"numRowsInserted"`
+	NumRowsUpdated   int64 `json:"numRowsUpdated"`
+	NumRowsDeleted   int64 `json:"numRowsDeleted"`
+	NumDMLDuplicates int64 `json:"numDmlDuplicates"`
 }
 
 type execResponse struct {
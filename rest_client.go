@@ -0,0 +1,106 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// RESTClient sends requests to the REST APIs of Snowflake outside of a session, e.g., of Snowpipe, with the key pair
+// authentication and the retries of the driver. The packages of such APIs build on it.
+type RESTClient struct {
+	cfg    *Config
+	client *http.Client
+}
+
+// RESTRequest is a request RESTClient.Do sends.
+type RESTRequest struct {
+	Method  string // GET if empty
+	Path    string
+	Query   url.Values
+	Headers map[string]string
+	Body    []byte // nil for none
+	// Prepare, if not nil, is called with the request of every attempt before it is sent, e.g., to set the
+	// Authorization header to a new JWT, so that a request retried longer than a token lives isn't rejected.
+	Prepare func(req *http.Request) error
+}
+
+// NewRESTClient returns the client of cfg, which needs Account, User and PrivateKey. Host, Port and Protocol are
+// derived from Account unless given. The error is a *SnowflakeError with ErrNoPrivateKey if cfg has no private key.
+func NewRESTClient(cfg *Config) (*RESTClient, error) {
+	c := *cfg
+	if c.PrivateKey == nil {
+		return nil, &SnowflakeError{Number: ErrNoPrivateKey, Message: errMsgNoPrivateKey}
+	}
+	c.Authenticator = AuthTypeJwt
+	if err := fillMissingConfigParameters(&c); err != nil {
+		return nil, err
+	}
+	transport := c.Transporter
+	if transport == nil {
+		transport = SnowflakeTransport
+		if c.InsecureMode {
+			transport = snowflakeInsecureTransport
+		}
+	}
+	return &RESTClient{
+		cfg:    &c,
+		client: &http.Client{Timeout: defaultClientTimeout, Transport: transport},
+	}, nil
+}
+
+// NewJWTToken returns a new JWT of the key pair authentication, which expires after Config.JWTExpireTimeout.
+func (c *RESTClient) NewJWTToken() (string, error) {
+	return prepareJWTToken(c.cfg)
+}
+
+// Do sends the request to the host of the client with the requestId parameter of the context, as the driver's
+// requests have, or a new one. Network errors, 5xx errors and throttling, HTTP 429, are retried with backoff until
+// Config.RequestTimeout. The response is that of the first attempt that succeeds or fails with another status, and
+// the caller closes its body.
+func (c *RESTClient) Do(ctx context.Context, req *RESTRequest) (*http.Response, error) {
+	query := url.Values{}
+	for k, v := range req.Query {
+		query[k] = v
+	}
+	if query.Get("requestId") == "" {
+		query.Set("requestId", getOrGenerateRequestIDFromContext(ctx))
+	}
+	u := &url.URL{
+		Scheme:   c.cfg.Protocol,
+		Host:     c.cfg.Host + ":" + strconv.Itoa(c.cfg.Port),
+		Path:     req.Path,
+		RawQuery: query.Encode(),
+	}
+	headers := map[string]string{
+		"Accept":     headerContentTypeApplicationJSON,
+		"User-Agent": userAgent,
+	}
+	for k, v := range req.Headers {
+		headers[k] = v
+	}
+	newRequest := func(method, urlStr string, body io.Reader) (*http.Request, error) {
+		r, err := http.NewRequest(method, urlStr, body)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		if req.Prepare != nil {
+			if err = req.Prepare(r); err != nil {
+				return nil, err
+			}
+		}
+		return r, nil
+	}
+	r := newRetryHTTP(ctx, c.client, newRequest, u, nil, c.cfg.RequestTimeout).doRaise4XX(true)
+	if req.Method != "" {
+		r.method = req.Method
+	}
+	return r.setBody(req.Body).execute()
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRESTClientDo(t *testing.T) {
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+r.URL.Query().Get("requestId")+" "+
+			r.URL.Query().Get("x")+" "+r.Header.Get("Authorization")+" "+r.Header.Get("X-Test")+" "+string(b))
+		if len(calls) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewRESTClient(&Config{
+		Account:    "testaccount",
+		User:       "testuser",
+		PrivateKey: key,
+		Protocol:   "http",
+		Host:       u.Hostname(),
+		Port:       port,
+	})
+	if err != nil {
+		t.Fatalf("failed to create a client. err: %v", err)
+	}
+	if token, err := client.NewJWTToken(); err != nil || strings.Count(token, ".") != 2 {
+		t.Fatalf("unexpected token: %v, err: %v", token, err)
+	}
+
+	var attempts int
+	res, err := client.Do(WithRequestID(context.Background(), "req1"), &RESTRequest{
+		Method:  http.MethodPost,
+		Path:    "/v1/test",
+		Query:   url.Values{"x": {"1"}},
+		Headers: map[string]string{"X-Test": "yes"},
+		Body:    []byte("body"),
+		Prepare: func(r *http.Request) error {
+			attempts++
+			r.Header.Set("Authorization", "Bearer token"+strconv.Itoa(attempts))
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to send. err: %v", err)
+	}
+	defer res.Body.Close()
+	expected := []string{
+		"POST /v1/test req1 1 Bearer token1 yes body",
+		"POST /v1/test req1 1 Bearer token2 yes body",
+	}
+	if res.StatusCode != http.StatusOK || strings.Join(calls, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected calls: %q", calls)
+	}
+
+	_, err = NewRESTClient(&Config{Account: "testaccount", User: "testuser"})
+	if driverErr, ok := err.(*SnowflakeError); !ok || driverErr.Number != ErrNoPrivateKey {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -2,15 +2,119 @@
 
 package gosnowflake
 
+import "time"
+
 // SnowflakeResult provides the associated query ID
 type SnowflakeResult interface {
 	QueryID() string
 }
 
+// SnowflakeQueryStats provides the execution metadata of the query. The driver results and rows implement it, and
+// the rows are reachable through sql.Conn.Raw.
+type SnowflakeQueryStats interface {
+	QueryStats() QueryStats
+}
+
+// QueryStats is the execution metadata of a query that the server returns with the result, and the times the driver
+// measures. The server doesn't return the compilation and execution times, which are in QUERY_HISTORY by QueryID.
+type QueryStats struct {
+	QueryID           string
+	SQLState          string
+	StatementTypeID   int64
+	StatementType     string // e.g., SELECT, INSERT, COPY or DDL. See StatementTypeID if UNKNOWN
+	RowsReturned      int64  // rows of the result set, including those in chunks
+	RowsInserted      int64  // rows a DML statement inserted
+	RowsUpdated       int64  // rows a DML statement updated
+	RowsDeleted       int64  // rows a DML statement deleted
+	DMLDuplicates     int64  // rows a DML statement updated more than once
+	ResultFormat      string // json or arrow
+	NumberOfBinds     int
+	Database          string        // database of the session after the query
+	Schema            string        // schema of the session after the query
+	Role              string        // role of the session after the query
+	Warehouse         string        // warehouse of the session after the query
+	ServerResultTime  time.Time     // when the server sent the result, zero if the response has none
+	ClientStartTime   time.Time     // when the driver submitted the query
+	ClientElapsedTime time.Duration // from the submission to the response, including the wait for an asynchronous result
+}
+
+// newQueryStats returns the stats of the query of data, submitted at start and responded after elapsed.
+func newQueryStats(data *execResponseData, start time.Time, elapsed time.Duration) QueryStats {
+	stats := QueryStats{
+		QueryID:           data.QueryID,
+		SQLState:          data.SQLState,
+		StatementTypeID:   data.StatementTypeID,
+		StatementType:     statementTypeName(data.StatementTypeID),
+		RowsReturned:      data.Total,
+		ResultFormat:      data.QueryResultFormat,
+		NumberOfBinds:     data.NumberOfBinds,
+		Database:          data.FinalDatabaseName,
+		Schema:            data.FinalSchemaName,
+		Role:              data.FinalRoleName,
+		Warehouse:         data.FinalWarehouseName,
+		ClientStartTime:   start,
+		ClientElapsedTime: elapsed,
+	}
+	if data.SendResultTime > 0 {
+		stats.ServerResultTime = time.Unix(0, data.SendResultTime*int64(time.Millisecond))
+	}
+	if stats.ResultFormat == "" && len(data.RowType) > 0 {
+		stats.ResultFormat = "json"
+	}
+	// a multi-statement query has the type ID of SELECT
+	if data.StatementTypeID == statementTypeIDSelect && len(data.RowType) > 0 &&
+		data.RowType[0].Name == "multiple statement execution" {
+		stats.StatementType = "MULTI_STATEMENT"
+	}
+	if data.Stats != nil {
+		stats.RowsInserted = data.Stats.NumRowsInserted
+		stats.RowsUpdated = data.Stats.NumRowsUpdated
+		stats.RowsDeleted = data.Stats.NumRowsDeleted
+		stats.DMLDuplicates = data.Stats.NumDMLDuplicates
+	}
+	return stats
+}
+
+// statementTypeName returns the name of a statement type ID, or of its category if the type is not known.
+func statementTypeName(id int64) string {
+	switch id {
+	case statementTypeIDSelect:
+		return "SELECT"
+	case statementTypeIDDml:
+		return "DML"
+	case statementTypeIDInsert:
+		return "INSERT"
+	case statementTypeIDUpdate:
+		return "UPDATE"
+	case statementTypeIDDelete:
+		return "DELETE"
+	case statementTypeIDMerge:
+		return "MERGE"
+	case statementTypeIDMultiTableInsert:
+		return "MULTI_TABLE_INSERT"
+	case statementTypeIDCopy:
+		return "COPY"
+	case statementTypeIDUnload:
+		return "UNLOAD"
+	}
+	switch id & 0xf000 {
+	case statementTypeIDDml:
+		return "DML"
+	case statementTypeIDScl:
+		return "SCL"
+	case statementTypeIDTcl:
+		return "TCL"
+	case statementTypeIDDdl:
+		return "DDL"
+	}
+	return "UNKNOWN"
+}
+
 type snowflakeResult struct {
 	affectedRows int64
 	insertID     int64 // Snowflake doesn't support last insert id
 	queryID      string
+	stats        QueryStats
 }
 
 func (res *snowflakeResult) LastInsertId() (int64, error) {
@@ -24,3 +128,7 @@ func (res *snowflakeResult) RowsAffected() (int64, error) {
 func (res *snowflakeResult) QueryID() string {
 	return res.queryID
 }
+
+func (res *snowflakeResult) QueryStats() QueryStats {
+	return res.stats
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestExecQueryStats(t *testing.T) {
+	var body string
+	sr := &snowflakeRestful{
+		FuncPostQuery: func(_ context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string, _ []byte, _ time.Duration, _ string) (*execResponse, error) {
+			var resp execResponse
+			if err := json.Unmarshal([]byte(body), &resp); err != nil {
+				return nil, err
+			}
+			return &resp, nil
+		},
+	}
+	sc := &snowflakeConn{
+		cfg:  &Config{Params: map[string]*string{}},
+		rest: sr,
+	}
+	body = `{"data": {"rowtype": [{"name": "number of rows inserted", "type": "fixed"}], "rowset": [["2"]],
+		"total": 1, "queryId": "01-insert", "finalDatabaseName": "DB1", "finalSchemaName": "PUBLIC",
+		"finalRoleName": "SYSADMIN", "finalWarehouseName": "WH1", "numberOfBinds": 2, "statementTypeId": 12544,
+		"stats": {"numRowsInserted": 2}, "sendResultTime": 1591012800123}, "code": null, "message": null,
+		"success": true}`
+	start := time.Now()
+	result, err := sc.ExecContext(context.Background(), "INSERT INTO T1 VALUES (?), (?)", []driver.NamedValue{
+		{Ordinal: 1, Value: int64(1)},
+		{Ordinal: 2, Value: int64(2)},
+	})
+	if err != nil {
+		t.Fatalf("failed to exec. err: %v", err)
+	}
+	stats := result.(SnowflakeQueryStats).QueryStats()
+	if stats.QueryID != "01-insert" || stats.StatementType != "INSERT" || stats.RowsInserted != 2 ||
+		stats.NumberOfBinds != 2 || stats.ResultFormat != "json" || stats.Database != "DB1" ||
+		stats.Schema != "PUBLIC" || stats.Role != "SYSADMIN" || stats.Warehouse != "WH1" ||
+		!stats.ServerResultTime.Equal(time.Date(2020, 6, 1, 12, 0, 0, 123e6, time.UTC)) ||
+		stats.ClientStartTime.Before(start) || stats.ClientElapsedTime < 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	body = `{"data": {"rowtype": [{"name": "C1", "type": "fixed"}], "rowsetbase64": "", "total": 12345,
+		"queryId": "01-select", "statementTypeId": 4096, "queryResultFormat": "arrow"}, "success": true}`
+	rows, err := sc.QueryContext(context.Background(), "SELECT C1 FROM T1", nil)
+	if err != nil {
+		t.Fatalf("failed to query. err: %v", err)
+	}
+	defer rows.Close()
+	stats = rows.(SnowflakeQueryStats).QueryStats()
+	if stats.QueryID != "01-select" || stats.StatementType != "SELECT" || stats.RowsReturned != 12345 ||
+		stats.ResultFormat != "arrow" || !stats.ServerResultTime.IsZero() {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestStatementTypeName(t *testing.T) {
+	testcases := []struct {
+		id   int64
+		name string
+	}{
+		{0x1000, "SELECT"},
+		{0x3100, "INSERT"},
+		{0x3600, "COPY"},
+		{0x3900, "DML"},
+		{0x4100, "SCL"},
+		{0x5000, "TCL"},
+		{0x6200, "DDL"},
+		{0x7100, "UNKNOWN"},
+	}
+	for _, tc := range testcases {
+		if name := statementTypeName(tc.id); name != tc.name {
+			t.Errorf("unexpected name of %x. expected: %v, got: %v", tc.id, tc.name, name)
+		}
+	}
+}
@@ -203,6 +203,21 @@ func (r *retryHTTP) doPost() *retryHTTP {
 	return r
 }
 
+func (r *retryHTTP) doPut() *retryHTTP {
+	r.method = "PUT"
+	return r
+}
+
+func (r *retryHTTP) doHead() *retryHTTP {
+	r.method = "HEAD"
+	return r
+}
+
+func (r *retryHTTP) doDelete() *retryHTTP {
+	r.method = "DELETE"
+	return r
+}
+
 func (r *retryHTTP) setBody(body []byte) *retryHTTP {
 	r.body = body
 	return r
@@ -240,10 +255,14 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 			logger.WithContext(r.ctx).Warningf(
 				"failed http connection. no response is returned. err: %v. retrying...\n", err)
 		} else {
-			if res.StatusCode == http.StatusOK || r.raise4XX && res != nil && res.StatusCode >= 400 && res.StatusCode < 500 {
-				// exit if success
+			if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusCreated ||
+				res.StatusCode == http.StatusNoContent ||
+				r.raise4XX && res != nil && res.StatusCode >= 400 && res.StatusCode < 500 &&
+					res.StatusCode != http.StatusTooManyRequests {
+				// exit if success, including 201 Created of storage uploads and 204 No Content of deletes
 				// or
-				// abort connection if raise4XX flag is enabled and the range of HTTP status code are 4XX.
+				// abort connection if raise4XX flag is enabled and the range of HTTP status code are 4XX, but for
+				// 429 Too Many Requests of throttling, which is retried.
 				// This is currently used for Snowflake login. The caller must generate an error object based on HTTP status.
 				break
 			}
@@ -8,13 +8,16 @@ import (
 	"context"
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 	"github.com/apache/arrow/go/arrow/ipc"
 	"github.com/apache/arrow/go/arrow/memory"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -32,21 +35,112 @@ var (
 
 	// CustomJSONDecoderEnabled has the chunk downloader use the custom JSON decoder to reduce memory footprint.
 	CustomJSONDecoderEnabled = false
+
+	// TypedJSONDecoderEnabled has the chunk downloader convert JSON chunks to Go values while decoding them.
+	// It takes precedence over CustomJSONDecoderEnabled.
+	TypedJSONDecoderEnabled = true
+
+	// ChunkSpillEnabled has the chunk downloader write the downloaded chunks, compressed, to ChunkSpillDir and
+	// decode each of them only when the rows reach it, instead of holding the decoded chunks in memory.
+	ChunkSpillEnabled = false
+
+	// ChunkSpillDir specifies the directory for the spilled chunks. The default directory for temporary files is
+	// used if empty. The chunks are written to a subdirectory of the process, gosnowflake-spill-<pid>, and those of
+	// the processes no longer running are removed the first time a process spills.
+	ChunkSpillDir = ""
 )
 
 var (
+	// maximum number of retries per chunk
 	maxChunkDownloaderErrorCounter = 5
+
+	// backoff between the retries of a chunk
+	chunkDownloaderRetryWaitAlgo = &waitAlgo{
+		mutex: &sync.Mutex{},
+		base:  2 * time.Second,
+		cap:   32 * time.Second,
+	}
 )
 
+// SnowflakeRowsSeeker moves the cursor of the current result set without reading the rows in between. The driver
+// rows implement it and are reachable through sql.Conn.Raw.
+type SnowflakeRowsSeeker interface {
+	SeekRow(rowIndex int64) error
+	SkipRows(n int64) error
+}
+
+// SnowflakeRowsProgress reports the progress of reading the current result set. The driver rows implement it and are
+// reachable through sql.Conn.Raw.
+type SnowflakeRowsProgress interface {
+	TotalRows() int64
+	ConsumedRows() int64
+	ChunkDownloadProgress() ChunkDownloadProgress
+}
+
+// ChunkDownloadProgress counts the chunks of a result set by download state. The row set that comes with the query
+// response is not a chunk.
+type ChunkDownloadProgress struct {
+	Total      int // number of chunks
+	Downloaded int // chunks downloaded, including the ones the rows are done with
+	Pending    int // chunks not downloaded yet, including the ones being downloaded
+	Error      int // chunks that failed to download after retries
+}
+
+// ColumnMetadata is the metadata of a column of a result set as the server describes it.
+type ColumnMetadata struct {
+	Name       string
+	Type       string // Snowflake type in upper case, e.g., FIXED, TEXT or TIMESTAMP_TZ
+	Precision  int64  // digits of FIXED
+	Scale      int64  // digits of the fraction of FIXED, or of the seconds of TIME and timestamps
+	Length     int64  // maximum number of characters of TEXT, or bytes of BINARY
+	ByteLength int64  // maximum number of bytes of TEXT
+	Nullable   bool
+	Collation  string // collation specification of TEXT, empty if none
+	Database   string // database of the table the column comes from, empty if the column is computed
+	Schema     string // schema of the table the column comes from
+	Table      string // table the column comes from
+}
+
+// DeclaredType returns the type as declared in DDL, e.g., NUMBER(38,2), VARCHAR(16777216) or TIMESTAMP_TZ(9).
+func (c ColumnMetadata) DeclaredType() string {
+	switch c.Type {
+	case "FIXED":
+		return fmt.Sprintf("NUMBER(%v,%v)", c.Precision, c.Scale)
+	case "REAL":
+		return "FLOAT"
+	case "TEXT":
+		if c.Collation != "" {
+			return fmt.Sprintf("VARCHAR(%v) COLLATE '%v'", c.Length, c.Collation)
+		}
+		return fmt.Sprintf("VARCHAR(%v)", c.Length)
+	case "BINARY":
+		return fmt.Sprintf("BINARY(%v)", c.Length)
+	case "TIME", "TIMESTAMP_LTZ", "TIMESTAMP_NTZ", "TIMESTAMP_TZ":
+		return fmt.Sprintf("%v(%v)", c.Type, c.Scale)
+	}
+	return c.Type
+}
+
+// SnowflakeRowsColumnMetadata returns the complete metadata of the columns of the current result set, which the
+// ColumnType methods of database/sql expose only partly. The driver rows implement it and are reachable through
+// sql.Conn.Raw.
+type SnowflakeRowsColumnMetadata interface {
+	ColumnMetadata() []ColumnMetadata
+}
+
 type snowflakeRows struct {
 	sc              *snowflakeConn
 	RowType         []execResponseRowType
 	ChunkDownloader *snowflakeChunkDownloader
 	queryID         string
+	stats           QueryStats
 }
 
 func (rows *snowflakeRows) Close() (err error) {
 	logger.WithContext(rows.sc.ctx).Debugln("Rows.Close")
+	for scd := rows.ChunkDownloader; scd != nil; scd = scd.NextDownloader {
+		scd.close()
+	}
 	return nil
 }
 
@@ -55,6 +149,7 @@ type snowflakeValue interface{}
 type chunkRowType struct {
 	RowSet   []*string
 	ArrowRow []snowflakeValue
+	TypedRow []snowflakeValue // JSON row already converted by the typed chunk decoder
 }
 
 type rowSetType struct {
@@ -68,6 +163,11 @@ type chunkError struct {
 	Error error
 }
 
+type chunkRetry struct {
+	count int           // number of retries
+	sleep time.Duration // wait before the last retry
+}
+
 type snowflakeChunkDownloader struct {
 	sc                 *snowflakeConn
 	ctx                context.Context
@@ -77,12 +177,16 @@ type snowflakeChunkDownloader struct {
 	CurrentChunk       []chunkRowType
 	CurrentChunkIndex  int
 	CurrentChunkSize   int
+	FirstChunkSize     int // number of rows in the row set that comes with the query response
 	ChunksMutex        *sync.Mutex
 	ChunkMetas         []execResponseChunk
 	Chunks             map[int][]chunkRowType
-	ChunksChan         chan int
+	SpilledChunks      map[int]*os.File // chunks written to disk by the downloader, yet to be decoded
+	ChunksScheduled    map[int]bool     // chunks downloaded or being downloaded. used by the consumer goroutine only.
+	ChunksDownloaded   map[int]bool     // chunks ever downloaded
 	ChunksError        chan *chunkError
-	ChunksErrorCounter int
+	ChunksErrorCounter int                // number of retries of all chunks
+	ChunksRetries      map[int]chunkRetry // used by the consumer goroutine only
 	ChunksFinalErrors  []*chunkError
 	Qrmk               string
 	QueryResultFormat  string
@@ -92,8 +196,16 @@ type snowflakeChunkDownloader struct {
 	FuncDownload       func(context.Context, *snowflakeChunkDownloader, int)
 	FuncDownloadHelper func(context.Context, *snowflakeChunkDownloader, int) error
 	FuncGet            func(context.Context, *snowflakeChunkDownloader, string, map[string]string, time.Duration) (*http.Response, error)
+	FuncGetResult      func(context.Context, *snowflakeChunkDownloader) (*execResponseData, error)
+	QueryID            string
+	Location           *time.Location // location of TIMESTAMP_LTZ values. time.Local if nil
+	CivilDateTime      bool           // DATE and TIME values are Date and TimeOfDay instead of time.Time
+	BinaryBase64       bool           // BINARY values of JSON result sets are in base64 instead of hex
+	RefreshMutex       *sync.Mutex    // serializes the refreshes of the chunk URLs
+	ChunksRefreshed    time.Time      // last time the chunk URLs were refreshed
 	DoneDownloadCond   *sync.Cond
 	NextDownloader     *snowflakeChunkDownloader
+	closed             bool
 }
 
 // ColumnTypeDatabaseTypeName returns the database column name.
@@ -101,40 +213,61 @@ func (rows *snowflakeRows) ColumnTypeDatabaseTypeName(index int) string {
 	return strings.ToUpper(rows.RowType[index].Type)
 }
 
-// ColumnTypeLength returns the length of the column
+// ColumnTypeLength returns the length of the column, or the precision of FIXED
 func (rows *snowflakeRows) ColumnTypeLength(index int) (length int64, ok bool) {
-	if index < 0 || index > len(rows.RowType) {
+	if index < 0 || index >= len(rows.RowType) {
 		return 0, false
 	}
 	switch rows.RowType[index].Type {
 	case "text", "variant", "object", "array", "binary":
 		return rows.RowType[index].Length, true
+	case "fixed":
+		return rows.RowType[index].Precision, true
 	}
 	return 0, false
 }
 
 func (rows *snowflakeRows) ColumnTypeNullable(index int) (nullable, ok bool) {
-	if index < 0 || index > len(rows.RowType) {
+	if index < 0 || index >= len(rows.RowType) {
 		return false, false
 	}
 	return rows.RowType[index].Nullable, true
 }
 
 func (rows *snowflakeRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
-	if index < 0 || index > len(rows.RowType) {
+	if index < 0 || index >= len(rows.RowType) {
 		return 0, 0, false
 	}
 	switch rows.RowType[index].Type {
 	case "fixed":
 		return rows.RowType[index].Precision, rows.RowType[index].Scale, true
-	case "time":
-		return rows.RowType[index].Scale, 0, true
-	case "timestamp":
+	case "time", "timestamp_ltz", "timestamp_ntz", "timestamp_tz":
 		return rows.RowType[index].Scale, 0, true
 	}
 	return 0, 0, false
 }
 
+// ColumnMetadata returns the metadata of the columns of the current result set.
+func (rows *snowflakeRows) ColumnMetadata() []ColumnMetadata {
+	columns := make([]ColumnMetadata, len(rows.RowType))
+	for i, rt := range rows.RowType {
+		columns[i] = ColumnMetadata{
+			Name:       rt.Name,
+			Type:       strings.ToUpper(rt.Type),
+			Precision:  rt.Precision,
+			Scale:      rt.Scale,
+			Length:     rt.Length,
+			ByteLength: rt.ByteLength,
+			Nullable:   rt.Nullable,
+			Collation:  rt.Collation,
+			Database:   rt.Database,
+			Schema:     rt.Schema,
+			Table:      rt.Table,
+		}
+	}
+	return columns
+}
+
 func (rows *snowflakeRows) Columns() []string {
 	logger.Debug("Rows.Columns")
 	ret := make([]string, len(rows.RowType))
@@ -145,20 +278,63 @@ func (rows *snowflakeRows) Columns() []string {
 }
 
 func (rows *snowflakeRows) ColumnTypeScanType(index int) reflect.Type {
-	return snowflakeTypeToGo(rows.RowType[index].Type, rows.RowType[index].Scale)
+	dbtype := rows.RowType[index].Type
+	if (dbtype == "geography" || dbtype == "geometry") && rows.sc.geoOutputFormat(dbtype).isBinary() {
+		return reflect.TypeOf([]byte{})
+	}
+	if rows.ChunkDownloader != nil && rows.ChunkDownloader.CivilDateTime {
+		switch dbtype {
+		case "date":
+			return reflect.TypeOf(Date{})
+		case "time":
+			return reflect.TypeOf(TimeOfDay{})
+		}
+	}
+	return snowflakeTypeToGo(dbtype, rows.RowType[index].Scale)
 }
 
 func (rows *snowflakeRows) QueryID() string {
 	return rows.queryID
 }
 
+func (rows *snowflakeRows) QueryStats() QueryStats {
+	return rows.stats
+}
+
+// TotalRows returns the number of rows of the current result set.
+func (rows *snowflakeRows) TotalRows() int64 {
+	return rows.ChunkDownloader.rowCount()
+}
+
+// ConsumedRows returns the number of rows of the current result set before the row the next call to Next returns,
+// including the rows skipped.
+func (rows *snowflakeRows) ConsumedRows() int64 {
+	return rows.ChunkDownloader.TotalRowIndex + 1
+}
+
+// ChunkDownloadProgress returns the download state of the chunks of the current result set.
+func (rows *snowflakeRows) ChunkDownloadProgress() ChunkDownloadProgress {
+	return rows.ChunkDownloader.progress()
+}
+
+// SeekRow positions the rows so that the next call to Next returns the row at rowIndex, counted from zero, of the
+// current result set. Only the chunks from the one holding the row on are downloaded. Seeking to the number of rows
+// moves to the end, and seeking fails with io.EOF once the result set is exhausted.
+func (rows *snowflakeRows) SeekRow(rowIndex int64) error {
+	logger.Debugf("Rows.SeekRow: %v", rowIndex)
+	return rows.ChunkDownloader.seek(rowIndex)
+}
+
+// SkipRows skips the next n rows of the current result set.
+func (rows *snowflakeRows) SkipRows(n int64) error {
+	logger.Debugf("Rows.SkipRows: %v", n)
+	return rows.ChunkDownloader.seek(rows.ChunkDownloader.TotalRowIndex + 1 + n)
+}
+
 func (rows *snowflakeRows) Next(dest []driver.Value) (err error) {
 	row, err := rows.ChunkDownloader.Next()
 	if err != nil {
 		// includes io.EOF
-		if err == io.EOF {
-			rows.ChunkDownloader.Chunks = nil // detach all chunks. No way to go backward without reinitialize it.
-		}
 		return err
 	}
 
@@ -166,11 +342,16 @@ func (rows *snowflakeRows) Next(dest []driver.Value) (err error) {
 		for i, n := 0, len(row.ArrowRow); i < n; i++ {
 			dest[i] = row.ArrowRow[i]
 		}
+	} else if row.TypedRow != nil {
+		for i, n := 0, len(row.TypedRow); i < n; i++ {
+			dest[i] = row.TypedRow[i]
+		}
 	} else {
 		for i, n := 0, len(row.RowSet); i < n; i++ {
 			// could move to chunk downloader so that each go routine
 			// can convert data
-			err := stringToValue(&dest[i], rows.RowType[i], row.RowSet[i])
+			err := stringToValue(&dest[i], rows.RowType[i], row.RowSet[i], rows.ChunkDownloader.Location,
+				rows.ChunkDownloader.CivilDateTime, rows.ChunkDownloader.BinaryBase64)
 			if err != nil {
 				return err
 			}
@@ -218,10 +399,36 @@ func (scd *snowflakeChunkDownloader) nextResultSet() error {
 }
 
 func (scd *snowflakeChunkDownloader) start() error {
-	scd.CurrentChunkSize = len(scd.RowSet.JSON) // cache the size
-	scd.CurrentIndex = -1                       // initial chunks idx
-	scd.CurrentChunkIndex = -1                  // initial chunk
+	scd.CurrentIndex = -1      // initial chunks idx
+	scd.CurrentChunkIndex = -1 // initial chunk
+
+	err := scd.decodeFirstChunk()
+	scd.FirstChunkSize = scd.CurrentChunkSize
+
+	chunkMetaLen := len(scd.ChunkMetas)
+	if chunkMetaLen > 0 {
+		logger.Debugf("MaxChunkDownloadWorkers: %v", MaxChunkDownloadWorkers)
+		logger.Debugf("chunks: %v, total bytes: %d", chunkMetaLen, scd.totalUncompressedSize())
+		scd.ChunksMutex = &sync.Mutex{}
+		scd.DoneDownloadCond = sync.NewCond(scd.ChunksMutex)
+		scd.Chunks = make(map[int][]chunkRowType)
+		scd.SpilledChunks = make(map[int]*os.File)
+		scd.ChunksScheduled = make(map[int]bool)
+		scd.ChunksDownloaded = make(map[int]bool)
+		scd.ChunksRetries = make(map[int]chunkRetry)
+		scd.RefreshMutex = &sync.Mutex{}
+		// downloads skipped by a seek may still be running, so every chunk gets room for an error.
+		scd.ChunksError = make(chan *chunkError, chunkMetaLen)
+		if deferred, _ := scd.ctx.Value(SnowflakeDeferChunkDownloadsKey).(bool); !deferred {
+			scd.schedule()
+		}
+	}
+	return err
+}
 
+// decodeFirstChunk makes the row set that comes with the query response the current chunk.
+func (scd *snowflakeChunkDownloader) decodeFirstChunk() error {
+	scd.CurrentChunkSize = len(scd.RowSet.JSON) // cache the size
 	scd.CurrentChunk = make([]chunkRowType, scd.CurrentChunkSize)
 	populateJSONRowSet(scd.CurrentChunk, scd.RowSet.JSON)
 
@@ -229,54 +436,48 @@ func (scd *snowflakeChunkDownloader) start() error {
 		// if the rowsetbase64 retrieved from the server is empty, move on to downloading chunks
 		var err error
 		firstArrowChunk := buildFirstArrowChunk(scd.RowSet.RowSetBase64)
-		scd.CurrentChunk, err = firstArrowChunk.decodeArrowChunk(scd.RowSet.RowType)
+		scd.CurrentChunk, err = firstArrowChunk.decodeArrowChunk(scd.RowSet.RowType, scd.Location, scd.CivilDateTime)
 		scd.CurrentChunkSize = firstArrowChunk.rowCount
 		if err != nil {
 			return err
 		}
 	}
-
-	// start downloading chunks if exists
-	chunkMetaLen := len(scd.ChunkMetas)
-	if chunkMetaLen > 0 {
-		logger.Debugf("MaxChunkDownloadWorkers: %v", MaxChunkDownloadWorkers)
-		logger.Debugf("chunks: %v, total bytes: %d", chunkMetaLen, scd.totalUncompressedSize())
-		scd.ChunksMutex = &sync.Mutex{}
-		scd.DoneDownloadCond = sync.NewCond(scd.ChunksMutex)
-		scd.Chunks = make(map[int][]chunkRowType)
-		scd.ChunksChan = make(chan int, chunkMetaLen)
-		scd.ChunksError = make(chan *chunkError, MaxChunkDownloadWorkers)
-		for i := 0; i < chunkMetaLen; i++ {
-			logger.Debugf("add chunk to channel ChunksChan: %v", i+1)
-			scd.ChunksChan <- i
-		}
-		for i := 0; i < intMin(MaxChunkDownloadWorkers, chunkMetaLen); i++ {
-			scd.schedule()
-		}
-	}
 	return nil
 }
 
+// schedule starts downloading the chunks following the current one, up to MaxChunkDownloadWorkers chunks ahead,
+// that are neither downloaded nor being downloaded.
 func (scd *snowflakeChunkDownloader) schedule() {
-	select {
-	case nextIdx := <-scd.ChunksChan:
-		logger.Infof("schedule chunk: %v", nextIdx+1)
-		go scd.FuncDownload(scd.ctx, scd, nextIdx)
-	default:
-		// no more download
-		logger.Info("no more download")
+	end := intMin(scd.CurrentChunkIndex+1+MaxChunkDownloadWorkers, len(scd.ChunkMetas))
+	for idx := scd.CurrentChunkIndex + 1; idx < end; idx++ {
+		if !scd.ChunksScheduled[idx] {
+			scd.download(idx)
+		}
 	}
 }
 
+func (scd *snowflakeChunkDownloader) download(idx int) {
+	logger.Infof("schedule chunk: %v", idx+1)
+	scd.ChunksScheduled[idx] = true
+	go scd.FuncDownload(scd.ctx, scd, idx)
+}
+
 func (scd *snowflakeChunkDownloader) checkErrorRetry() (err error) {
 	select {
 	case errc := <-scd.ChunksError:
-		if scd.ChunksErrorCounter < maxChunkDownloaderErrorCounter && errc.Error != context.Canceled {
-			// add the index to the chunks channel so that the download will be retried.
-			go scd.FuncDownload(scd.ctx, scd, errc.Index)
+		if errc.Index < scd.CurrentChunkIndex {
+			// the chunk was skipped by a seek while being downloaded. download it again if it is needed.
+			scd.ChunksScheduled[errc.Index] = false
+			logger.Infof("chunk idx: %v, err: %v. ignored as it is no longer needed", errc.Index, errc.Error)
+		} else if r := scd.ChunksRetries[errc.Index]; r.count < maxChunkDownloaderErrorCounter &&
+			errc.Error != context.Canceled {
+			r.count++
+			r.sleep = chunkDownloaderRetryWaitAlgo.decorr(r.count, r.sleep)
+			scd.ChunksRetries[errc.Index] = r
 			scd.ChunksErrorCounter++
-			logger.Warningf("chunk idx: %v, err: %v. retrying (%v/%v)...",
-				errc.Index, errc.Error, scd.ChunksErrorCounter, maxChunkDownloaderErrorCounter)
+			logger.Warningf("chunk idx: %v, err: %v. retrying in %v (%v/%v)...",
+				errc.Index, errc.Error, r.sleep, r.count, maxChunkDownloaderErrorCounter)
+			go scd.retry(errc.Index, r.sleep, isChunkURLExpired(errc.Error))
 		} else {
 			scd.ChunksFinalErrors = append(scd.ChunksFinalErrors, errc)
 			logger.Warningf("chunk idx: %v, err: %v. no further retry", errc.Index, errc.Error)
@@ -287,52 +488,257 @@ func (scd *snowflakeChunkDownloader) checkErrorRetry() (err error) {
 	}
 	return nil
 }
+
+// retry downloads the chunk idx again after waiting, refreshing the chunk URLs first if they have expired.
+func (scd *snowflakeChunkDownloader) retry(idx int, wait time.Duration, refresh bool) {
+	since := time.Now()
+	if wait > 0 {
+		await := time.NewTimer(wait)
+		select {
+		case <-await.C:
+		case <-scd.ctx.Done():
+			await.Stop()
+			scd.ChunksError <- &chunkError{Index: idx, Error: scd.ctx.Err()}
+			scd.DoneDownloadCond.Broadcast()
+			return
+		}
+	}
+	if refresh {
+		if err := scd.refreshChunkURLs(since); err != nil {
+			logger.Errorf("failed to refresh chunk URLs. query ID: %v, err: %v", scd.QueryID, err)
+			scd.ChunksError <- &chunkError{Index: idx, Error: err}
+			scd.DoneDownloadCond.Broadcast()
+			return
+		}
+	}
+	scd.FuncDownload(scd.ctx, scd, idx)
+}
+
+// refreshChunkURLs replaces the chunk URLs, which expire, and the chunk headers with the ones of the result fetched
+// again by the query ID, unless they were refreshed after since.
+func (scd *snowflakeChunkDownloader) refreshChunkURLs(since time.Time) error {
+	scd.RefreshMutex.Lock()
+	defer scd.RefreshMutex.Unlock()
+	if scd.ChunksRefreshed.After(since) {
+		return nil
+	}
+	logger.Infof("refreshing chunk URLs. query ID: %v", scd.QueryID)
+	data, err := scd.FuncGetResult(scd.ctx, scd)
+	if err != nil {
+		return err
+	}
+	if len(data.Chunks) != len(scd.ChunkMetas) {
+		return &SnowflakeError{
+			Number:      ErrFailedToRefreshChunkURLs,
+			Message:     errMsgFailedToRefreshChunkURLs,
+			MessageArgs: []interface{}{scd.QueryID, len(scd.ChunkMetas), len(data.Chunks)},
+			QueryID:     scd.QueryID,
+		}
+	}
+	scd.ChunksMutex.Lock()
+	defer scd.ChunksMutex.Unlock()
+	for i := range scd.ChunkMetas {
+		scd.ChunkMetas[i].URL = data.Chunks[i].URL
+	}
+	scd.ChunkHeader = data.ChunkHeaders
+	scd.Qrmk = data.Qrmk
+	scd.ChunksRefreshed = time.Now()
+	return nil
+}
+
+func isChunkURLExpired(err error) bool {
+	driverErr, ok := err.(*SnowflakeError)
+	return ok && driverErr.Number == ErrChunkURLExpired
+}
+
 func (scd *snowflakeChunkDownloader) Next() (chunkRowType, error) {
+	if scd.TotalRowIndex < 0 {
+		// the first access starts the downloads deferred by WithDeferredChunkDownloads
+		scd.schedule()
+	}
 	for {
 		scd.CurrentIndex++
 		if scd.CurrentIndex < scd.CurrentChunkSize {
+			scd.TotalRowIndex++
 			return scd.CurrentChunk[scd.CurrentIndex], nil
 		}
-		scd.CurrentChunkIndex++ // next chunk
-		scd.CurrentIndex = -1   // reset
-		if scd.CurrentChunkIndex >= len(scd.ChunkMetas) {
+		if scd.CurrentChunkIndex+1 >= len(scd.ChunkMetas) {
 			break
 		}
+		if err := scd.loadChunk(scd.CurrentChunkIndex + 1); err != nil {
+			return chunkRowType{}, err
+		}
+	}
 
+	logger.Debugf("no more data")
+	scd.CurrentChunkIndex = len(scd.ChunkMetas)
+	scd.CurrentChunk = nil
+	scd.CurrentChunkSize = 0
+	scd.CurrentIndex = -1
+	if len(scd.ChunkMetas) > 0 {
+		// detach all chunks. No way to go backward without reinitialize it.
 		scd.ChunksMutex.Lock()
-		if scd.CurrentChunkIndex > 1 {
-			scd.Chunks[scd.CurrentChunkIndex-1] = nil // detach the previously used chunk
-		}
+		scd.releaseChunks(len(scd.ChunkMetas))
+		scd.ChunksMutex.Unlock()
+	}
+	return chunkRowType{}, io.EOF
+}
 
-		for scd.Chunks[scd.CurrentChunkIndex] == nil {
-			logger.Debugf("waiting for chunk idx: %v/%v",
-				scd.CurrentChunkIndex+1, len(scd.ChunkMetas))
+// loadChunk makes the chunk idx the current one, downloading it if needed, and releases the chunks before it.
+func (scd *snowflakeChunkDownloader) loadChunk(idx int) error {
+	scd.CurrentChunkIndex = idx
+	scd.ChunksMutex.Lock()
+	scd.releaseChunks(idx)
+	if !scd.ChunksScheduled[idx] {
+		// the chunk was skipped or released before
+		scd.download(idx)
+	}
+	for scd.Chunks[idx] == nil && scd.SpilledChunks[idx] == nil {
+		logger.Debugf("waiting for chunk idx: %v/%v", idx+1, len(scd.ChunkMetas))
 
-			err := scd.checkErrorRetry()
-			if err != nil {
-				scd.ChunksMutex.Unlock()
-				return chunkRowType{}, err
-			}
+		err := scd.checkErrorRetry()
+		if err != nil {
+			scd.ChunksMutex.Unlock()
+			return err
+		}
 
-			// wait for chunk downloader goroutine to broadcast the event,
-			// 1) one chunk download finishes or 2) an error occurs.
-			scd.DoneDownloadCond.Wait()
+		// wait for chunk downloader goroutine to broadcast the event,
+		// 1) one chunk download finishes or 2) an error occurs.
+		scd.DoneDownloadCond.Wait()
+	}
+	logger.Debugf("ready: chunk %v", idx+1)
+	if f, ok := scd.SpilledChunks[idx]; ok {
+		// the chunk is decoded only once, so it has to be downloaded again to come back to it.
+		delete(scd.SpilledChunks, idx)
+		scd.ChunksScheduled[idx] = false
+		scd.ChunksMutex.Unlock()
+		respd, err := decodeSpilledChunk(scd, idx, f)
+		removeSpilledChunk(f)
+		if err != nil {
+			return err
 		}
-		logger.Debugf("ready: chunk %v", scd.CurrentChunkIndex+1)
-		scd.CurrentChunk = scd.Chunks[scd.CurrentChunkIndex]
+		scd.CurrentChunk = respd
+	} else {
+		scd.CurrentChunk = scd.Chunks[idx]
 		scd.ChunksMutex.Unlock()
-		scd.CurrentChunkSize = len(scd.CurrentChunk)
+	}
+	scd.CurrentChunkSize = len(scd.CurrentChunk)
+	scd.CurrentIndex = -1
 
-		// kick off the next download
-		scd.schedule()
+	// kick off the next download
+	scd.schedule()
+	return nil
+}
+
+// releaseChunks detaches the downloaded chunks before the chunk idx. The caller must hold ChunksMutex.
+func (scd *snowflakeChunkDownloader) releaseChunks(idx int) {
+	for i := range scd.Chunks {
+		if i < idx {
+			delete(scd.Chunks, i)
+			scd.ChunksScheduled[i] = false
+		}
 	}
+	for i, f := range scd.SpilledChunks {
+		if i < idx {
+			removeSpilledChunk(f)
+			delete(scd.SpilledChunks, i)
+			scd.ChunksScheduled[i] = false
+		}
+	}
+}
 
-	logger.Debugf("no more data")
-	if len(scd.ChunkMetas) > 0 {
-		close(scd.ChunksError)
-		close(scd.ChunksChan)
+// close removes the chunks spilled to disk, including the ones being downloaded.
+func (scd *snowflakeChunkDownloader) close() {
+	if scd.ChunksMutex == nil {
+		return
+	}
+	scd.ChunksMutex.Lock()
+	defer scd.ChunksMutex.Unlock()
+	scd.closed = true
+	for i, f := range scd.SpilledChunks {
+		removeSpilledChunk(f)
+		delete(scd.SpilledChunks, i)
 	}
-	return chunkRowType{}, io.EOF
+}
+
+// rowCount returns the number of rows of the result set according to the chunk metadata.
+func (scd *snowflakeChunkDownloader) rowCount() int64 {
+	count := int64(scd.FirstChunkSize)
+	for _, c := range scd.ChunkMetas {
+		count += int64(c.RowCount)
+	}
+	return count
+}
+
+// progress counts the chunks by download state.
+func (scd *snowflakeChunkDownloader) progress() ChunkDownloadProgress {
+	p := ChunkDownloadProgress{Total: len(scd.ChunkMetas)}
+	if p.Total == 0 {
+		return p
+	}
+	scd.ChunksMutex.Lock()
+	p.Downloaded = len(scd.ChunksDownloaded)
+	scd.ChunksMutex.Unlock()
+	failed := make(map[int]bool)
+	for _, errc := range scd.ChunksFinalErrors {
+		failed[errc.Index] = true
+	}
+	p.Error = len(failed)
+	p.Pending = p.Total - p.Downloaded - p.Error
+	return p
+}
+
+// chunkOf returns the index of the chunk holding the row at rowIndex, -1 standing for the row set that comes with the
+// query response, and the offset of the row in the chunk.
+func (scd *snowflakeChunkDownloader) chunkOf(rowIndex int64) (int, int) {
+	if rowIndex < int64(scd.FirstChunkSize) {
+		return -1, int(rowIndex)
+	}
+	rowIndex -= int64(scd.FirstChunkSize)
+	for idx, c := range scd.ChunkMetas {
+		if rowIndex < int64(c.RowCount) {
+			return idx, int(rowIndex)
+		}
+		rowIndex -= int64(c.RowCount)
+	}
+	return len(scd.ChunkMetas), int(rowIndex)
+}
+
+// seek positions the downloader so that the next call to Next returns the row at rowIndex.
+func (scd *snowflakeChunkDownloader) seek(rowIndex int64) error {
+	if scd.CurrentChunkIndex >= len(scd.ChunkMetas) {
+		return io.EOF
+	}
+	total := scd.rowCount()
+	if rowIndex < 0 || rowIndex > total {
+		return &SnowflakeError{
+			Number:      ErrInvalidRowIndex,
+			Message:     errMsgInvalidRowIndex,
+			MessageArgs: []interface{}{rowIndex, total},
+		}
+	}
+	idx, offset := scd.chunkOf(rowIndex)
+	switch {
+	case idx == len(scd.ChunkMetas):
+		// the end of the result set. nothing needs downloading.
+		scd.CurrentChunkIndex = idx - 1
+		scd.CurrentChunk = nil
+		scd.CurrentChunkSize = 0
+	case idx == scd.CurrentChunkIndex && scd.CurrentChunk != nil:
+	case idx < 0:
+		if err := scd.decodeFirstChunk(); err != nil {
+			return err
+		}
+		scd.CurrentChunkIndex = idx
+	default:
+		if err := scd.loadChunk(idx); err != nil {
+			return err
+		}
+	}
+	scd.schedule()
+	scd.CurrentIndex = offset - 1
+	scd.TotalRowIndex = rowIndex - 1
+	return nil
 }
 
 func getChunk(
@@ -346,7 +752,29 @@ func getChunk(
 	if err != nil {
 		return nil, err
 	}
-	return newRetryHTTP(ctx, scd.sc.rest.Client, http.NewRequest, u, headers, timeout).execute()
+	// 4XX are not retried here. The chunk downloader retries them, with the refreshed URL if it has expired.
+	return newRetryHTTP(ctx, scd.sc.rest.Client, http.NewRequest, u, headers, timeout).doRaise4XX(true).execute()
+}
+
+func getChunkResult(ctx context.Context, scd *snowflakeChunkDownloader) (*execResponseData, error) {
+	resultPath := fmt.Sprintf("/queries/%s/result", scd.QueryID)
+	data, err := scd.sc.getQueryResult(ctx, resultPath)
+	if err != nil {
+		return nil, err
+	}
+	if !data.Success {
+		code, err := strconv.Atoi(data.Code)
+		if err != nil {
+			code = -1
+		}
+		return nil, &SnowflakeError{
+			Number:   code,
+			SQLState: data.Data.SQLState,
+			Message:  data.Message,
+			QueryID:  scd.QueryID,
+		}
+	}
+	return &data.Data, nil
 }
 
 /* largeResultSetReader is a reader that wraps the large result set with leading and tailing brackets. */
@@ -366,6 +794,10 @@ func (r *largeResultSetReader) Read(p []byte) (n int, err error) {
 		len, err = r.body.Read(p)
 		if err == io.EOF {
 			r.status = 2
+			if len == 0 {
+				// emit the tail right away. the chunk decoders take an empty read for the end of stream.
+				return r.Read(p)
+			}
 			return len, nil
 		}
 		if err != nil {
@@ -387,8 +819,7 @@ func downloadChunk(ctx context.Context, scd *snowflakeChunkDownloader, idx int)
 	defer scd.DoneDownloadCond.Broadcast()
 
 	if err := scd.FuncDownloadHelper(ctx, scd, idx); err != nil {
-		logger.Errorf(
-			"failed to extract HTTP response body. URL: %v, err: %v", scd.ChunkMetas[idx].URL, err)
+		logger.Errorf("failed to extract HTTP response body. chunk: %v, err: %v", idx+1, err)
 		scd.ChunksError <- &chunkError{Index: idx, Error: err}
 	} else if scd.ctx.Err() == context.Canceled || scd.ctx.Err() == context.DeadlineExceeded {
 		scd.ChunksError <- &chunkError{Index: idx, Error: scd.ctx.Err()}
@@ -396,6 +827,9 @@ func downloadChunk(ctx context.Context, scd *snowflakeChunkDownloader, idx int)
 }
 
 func downloadChunkHelper(ctx context.Context, scd *snowflakeChunkDownloader, idx int) error {
+	// the chunk URLs and headers may be refreshed by another goroutine
+	scd.ChunksMutex.Lock()
+	chunkURL := scd.ChunkMetas[idx].URL
 	headers := make(map[string]string)
 	if len(scd.ChunkHeader) > 0 {
 		logger.Debug("chunk header is provided.")
@@ -406,8 +840,9 @@ func downloadChunkHelper(ctx context.Context, scd *snowflakeChunkDownloader, idx
 		headers[headerSseCAlgorithm] = headerSseCAes
 		headers[headerSseCKey] = scd.Qrmk
 	}
+	scd.ChunksMutex.Unlock()
 
-	resp, err := scd.FuncGet(ctx, scd, scd.ChunkMetas[idx].URL, headers, scd.sc.rest.RequestTimeout)
+	resp, err := scd.FuncGet(ctx, scd, chunkURL, headers, scd.sc.rest.RequestTimeout)
 	if err != nil {
 		return err
 	}
@@ -419,8 +854,16 @@ func downloadChunkHelper(ctx context.Context, scd *snowflakeChunkDownloader, idx
 		if err != nil {
 			return err
 		}
-		logger.Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, scd.ChunkMetas[idx].URL, b)
+		logger.Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, chunkURL, b)
 		logger.Infof("Header: %v", resp.Header)
+		if resp.StatusCode == http.StatusForbidden {
+			return &SnowflakeError{
+				Number:      ErrChunkURLExpired,
+				SQLState:    SQLStateConnectionFailure,
+				Message:     errMsgChunkURLExpired,
+				MessageArgs: []interface{}{idx},
+			}
+		}
 		return &SnowflakeError{
 			Number:      ErrFailedToGetChunk,
 			SQLState:    SQLStateConnectionFailure,
@@ -428,21 +871,36 @@ func downloadChunkHelper(ctx context.Context, scd *snowflakeChunkDownloader, idx
 			MessageArgs: []interface{}{idx},
 		}
 	}
+	if ChunkSpillEnabled {
+		return spillChunk(scd, idx, bufStream)
+	}
 	return decodeChunk(scd, idx, bufStream)
 }
 
 func decodeChunk(scd *snowflakeChunkDownloader, idx int, bufStream *bufio.Reader) (err error) {
-	gzipMagic, err := bufStream.Peek(2)
+	respd, err := decodeChunkRows(scd, idx, bufStream)
 	if err != nil {
 		return err
 	}
+	scd.ChunksMutex.Lock()
+	defer scd.ChunksMutex.Unlock()
+	scd.Chunks[idx] = respd
+	scd.ChunksDownloaded[idx] = true
+	return nil
+}
+
+func decodeChunkRows(scd *snowflakeChunkDownloader, idx int, bufStream *bufio.Reader) (respd []chunkRowType, err error) {
+	gzipMagic, err := bufStream.Peek(2)
+	if err != nil {
+		return nil, err
+	}
 	start := time.Now()
 	var source io.Reader
 	if gzipMagic[0] == 0x1f && gzipMagic[1] == 0x8b {
 		// detects and uncompresses Gzip format data
 		bufStream0, err := gzip.NewReader(bufStream)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		defer bufStream0.Close()
 		source = bufStream0
@@ -453,8 +911,13 @@ func decodeChunk(scd *snowflakeChunkDownloader, idx int, bufStream *bufio.Reader
 		status: 0,
 		body:   source,
 	}
-	var respd []chunkRowType
-	if scd.QueryResultFormat != arrowFormat {
+	if scd.QueryResultFormat != arrowFormat && TypedJSONDecoderEnabled {
+		respd, err = decodeTypedChunk(st, scd.RowSet.RowType, scd.ChunkMetas[idx].RowCount, scd.Location,
+			scd.CivilDateTime, scd.BinaryBase64)
+		if err != nil {
+			return nil, err
+		}
+	} else if scd.QueryResultFormat != arrowFormat {
 		var decRespd [][]*string
 		if !CustomJSONDecoderEnabled {
 			dec := json.NewDecoder(st)
@@ -462,13 +925,13 @@ func decodeChunk(scd *snowflakeChunkDownloader, idx int, bufStream *bufio.Reader
 				if err := dec.Decode(&decRespd); err == io.EOF {
 					break
 				} else if err != nil {
-					return err
+					return nil, err
 				}
 			}
 		} else {
 			decRespd, err = decodeLargeChunk(st, scd.ChunkMetas[idx].RowCount, scd.CellCount)
 			if err != nil {
-				return err
+				return nil, err
 			}
 		}
 		respd = make([]chunkRowType, len(decRespd))
@@ -476,7 +939,7 @@ func decodeChunk(scd *snowflakeChunkDownloader, idx int, bufStream *bufio.Reader
 	} else {
 		ipcReader, err := ipc.NewReader(source)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		arc := arrowResultChunk{
 			*ipcReader,
@@ -484,9 +947,9 @@ func decodeChunk(scd *snowflakeChunkDownloader, idx int, bufStream *bufio.Reader
 			int(scd.totalUncompressedSize()),
 			memory.NewGoAllocator(),
 		}
-		respd, err = arc.decodeArrowChunk(scd.RowSet.RowType)
+		respd, err = arc.decodeArrowChunk(scd.RowSet.RowType, scd.Location, scd.CivilDateTime)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	logger.Debugf(
@@ -495,11 +958,7 @@ func decodeChunk(scd *snowflakeChunkDownloader, idx int, bufStream *bufio.Reader
 		scd.ChunkMetas[idx].UncompressedSize,
 		time.Since(start), idx+1,
 	)
-
-	scd.ChunksMutex.Lock()
-	defer scd.ChunksMutex.Unlock()
-	scd.Chunks[idx] = respd
-	return nil
+	return respd, nil
 }
 
 func populateJSONRowSet(dst []chunkRowType, src [][]*string) {
@@ -5,9 +5,17 @@ package gosnowflake
 import (
 	"context"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -139,6 +147,167 @@ func TestRowsWithChunkDownloader(t *testing.T) {
 	logger.Info("END TESTS")
 }
 
+func newSeekTestRows(ctx context.Context, numChunks int, downloaded *sync.Map) *snowflakeRows {
+	cc := make([][]*string, 0)
+	for i := 0; i < 100; i++ {
+		v1 := fmt.Sprintf("%v", i)
+		v2 := fmt.Sprintf("Test%v", i)
+		cc = append(cc, []*string{&v1, &v2})
+	}
+	cm := make([]execResponseChunk, 0)
+	for i := 0; i < numChunks; i++ {
+		cm = append(cm, execResponseChunk{URL: fmt.Sprintf("dummyURL%v", i+1), RowCount: rowsInChunk})
+	}
+	rows := new(snowflakeRows)
+	rows.RowType = []execResponseRowType{
+		{Name: "c1", ByteLength: 10, Length: 10, Type: "FIXED", Scale: 0, Nullable: true},
+		{Name: "c2", ByteLength: 100000, Length: 100000, Type: "TEXT", Scale: 0, Nullable: false},
+	}
+	rows.ChunkDownloader = &snowflakeChunkDownloader{
+		ctx:           ctx,
+		Total:         int64(len(cc) + numChunks*rowsInChunk),
+		ChunkMetas:    cm,
+		TotalRowIndex: int64(-1),
+		FuncDownload: func(ctx context.Context, scd *snowflakeChunkDownloader, idx int) {
+			downloaded.Store(idx, true)
+			downloadChunkTest(ctx, scd, idx)
+		},
+		RowSet: rowSetType{JSON: cc},
+	}
+	rows.ChunkDownloader.start()
+	return rows
+}
+
+func TestRowsSeekRow(t *testing.T) {
+	numChunks := 12
+	backupMaxChunkDownloadWorkers := MaxChunkDownloadWorkers
+	MaxChunkDownloadWorkers = 2
+	defer func() {
+		MaxChunkDownloadWorkers = backupMaxChunkDownloadWorkers
+	}()
+	var downloaded sync.Map
+	rows := newSeekTestRows(WithDeferredChunkDownloads(context.Background()), numChunks, &downloaded)
+	var seeker SnowflakeRowsSeeker = rows
+	dest := make([]driver.Value, 2)
+
+	testcases := []struct {
+		index int64
+		value string
+	}{
+		{100 + 7*int64(rowsInChunk) + 5, "7005"},
+		{100 + 7*int64(rowsInChunk) + 1, "7001"},
+		{42, "42"},
+		{100 + 2*int64(rowsInChunk), "2000"},
+		{100 + 12*int64(rowsInChunk) - 1, "11122"},
+	}
+	for i, tc := range testcases {
+		if err := seeker.SeekRow(tc.index); err != nil {
+			t.Fatalf("failed to seek to %v. err: %v", tc.index, err)
+		}
+		if err := rows.Next(dest); err != nil {
+			t.Fatalf("failed to get value. err: %v", err)
+		}
+		if dest[0] != tc.value {
+			t.Fatalf("unexpected value at %v. expected: %v, got: %v", tc.index, tc.value, dest[0])
+		}
+		if i == 0 {
+			// only the chunk holding the row and the ones after it are downloaded
+			for idx := 0; idx < 7; idx++ {
+				if _, ok := downloaded.Load(idx); ok {
+					t.Errorf("chunk %v should not be downloaded", idx)
+				}
+			}
+		}
+	}
+	if err := rows.Next(dest); err != io.EOF {
+		t.Fatalf("should be the end of the result set. err: %v", err)
+	}
+	if err := seeker.SeekRow(0); err != io.EOF {
+		t.Fatalf("should fail to seek after the end of the result set. err: %v", err)
+	}
+}
+
+func TestRowsPrefetchChunks(t *testing.T) {
+	backupMaxChunkDownloadWorkers := MaxChunkDownloadWorkers
+	MaxChunkDownloadWorkers = 2
+	defer func() {
+		MaxChunkDownloadWorkers = backupMaxChunkDownloadWorkers
+	}()
+	var downloaded sync.Map
+	rows := newSeekTestRows(context.Background(), 5, &downloaded)
+	// the chunks are scheduled as the query returns, before the first row is read
+	scd := rows.ChunkDownloader
+	scd.ChunksMutex.Lock()
+	scheduled := []bool{scd.ChunksScheduled[0], scd.ChunksScheduled[1], scd.ChunksScheduled[2]}
+	scd.ChunksMutex.Unlock()
+	if !scheduled[0] || !scheduled[1] || scheduled[2] {
+		t.Fatalf("unexpected scheduled chunks: %v", scheduled)
+	}
+
+	var deferred sync.Map
+	rows = newSeekTestRows(WithDeferredChunkDownloads(context.Background()), 5, &deferred)
+	scd = rows.ChunkDownloader
+	scd.ChunksMutex.Lock()
+	n := len(scd.ChunksScheduled)
+	scd.ChunksMutex.Unlock()
+	if n != 0 {
+		t.Fatalf("the deferred chunks are scheduled: %v", n)
+	}
+}
+
+func TestRowsSkipRows(t *testing.T) {
+	var downloaded sync.Map
+	rows := newSeekTestRows(WithDeferredChunkDownloads(context.Background()), 3, &downloaded)
+	dest := make([]driver.Value, 2)
+	if err := rows.Next(dest); err != nil {
+		t.Fatalf("failed to get value. err: %v", err)
+	}
+	if err := rows.SkipRows(99 + int64(rowsInChunk)); err != nil {
+		t.Fatalf("failed to skip. err: %v", err)
+	}
+	if err := rows.Next(dest); err != nil {
+		t.Fatalf("failed to get value. err: %v", err)
+	}
+	if dest[0] != "1000" {
+		t.Fatalf("unexpected value. expected: %v, got: %v", "1000", dest[0])
+	}
+	if err := rows.SkipRows(2*int64(rowsInChunk) - 1); err != nil {
+		t.Fatalf("failed to skip to the end. err: %v", err)
+	}
+	if err := rows.Next(dest); err != io.EOF {
+		t.Fatalf("should be the end of the result set. err: %v", err)
+	}
+}
+
+func TestRowsSeekRowOutOfRange(t *testing.T) {
+	var downloaded sync.Map
+	rows := newSeekTestRows(WithDeferredChunkDownloads(context.Background()), 3, &downloaded)
+	total := int64(100 + 3*rowsInChunk)
+	for _, idx := range []int64{-1, total + 1} {
+		err := rows.SeekRow(idx)
+		driverErr, ok := err.(*SnowflakeError)
+		if !ok {
+			t.Fatalf("should be snowflake error. err: %v", err)
+		}
+		if driverErr.Number != ErrInvalidRowIndex {
+			t.Fatalf("unexpected error code. expected: %v, got: %v", ErrInvalidRowIndex, driverErr.Number)
+		}
+	}
+	if err := rows.SeekRow(total); err != nil {
+		t.Fatalf("failed to seek to the end. err: %v", err)
+	}
+	if err := rows.SeekRow(total - 1); err != nil {
+		t.Fatalf("failed to seek back. err: %v", err)
+	}
+	dest := make([]driver.Value, 2)
+	if err := rows.Next(dest); err != nil {
+		t.Fatalf("failed to get value. err: %v", err)
+	}
+	if dest[0] != "2122" {
+		t.Fatalf("unexpected value. expected: %v, got: %v", "2122", dest[0])
+	}
+}
+
 func downloadChunkTestError(ctx context.Context, scd *snowflakeChunkDownloader, idx int) {
 	// fail to download 6th and 10th chunk, and retry up to N times and success
 	// NOTE: zero based index
@@ -163,7 +332,17 @@ func downloadChunkTestError(ctx context.Context, scd *snowflakeChunkDownloader,
 	scd.DoneDownloadCond.Broadcast()
 }
 
+// disableChunkRetryWait makes the chunk downloader retry at once and returns the function restoring the backoff.
+func disableChunkRetryWait() func() {
+	backup := chunkDownloaderRetryWaitAlgo
+	chunkDownloaderRetryWaitAlgo = &waitAlgo{mutex: &sync.Mutex{}, base: time.Second, cap: 0}
+	return func() {
+		chunkDownloaderRetryWaitAlgo = backup
+	}
+}
+
 func TestRowsWithChunkDownloaderError(t *testing.T) {
+	defer disableChunkRetryWait()()
 	numChunks := 12
 	// changed the workers
 	backupMaxChunkDownloadWorkers := MaxChunkDownloadWorkers
@@ -245,6 +424,7 @@ func downloadChunkTestErrorFail(ctx context.Context, scd *snowflakeChunkDownload
 }
 
 func TestRowsWithChunkDownloaderErrorFail(t *testing.T) {
+	defer disableChunkRetryWait()()
 	numChunks := 12
 	// changed the workers
 	logger.Info("START TESTS")
@@ -325,6 +505,7 @@ func TestDownloadChunkInvalidResponseBody(t *testing.T) {
 	scd.ChunksMutex = &sync.Mutex{}
 	scd.DoneDownloadCond = sync.NewCond(scd.ChunksMutex)
 	scd.Chunks = make(map[int][]chunkRowType)
+	scd.ChunksDownloaded = make(map[int]bool)
 	scd.ChunksError = make(chan *chunkError, 1)
 	scd.FuncDownload(scd.ctx, scd, 1)
 	select {
@@ -367,6 +548,7 @@ func TestDownloadChunkErrorStatus(t *testing.T) {
 	scd.ChunksMutex = &sync.Mutex{}
 	scd.DoneDownloadCond = sync.NewCond(scd.ChunksMutex)
 	scd.Chunks = make(map[int][]chunkRowType)
+	scd.ChunksDownloaded = make(map[int]bool)
 	scd.ChunksError = make(chan *chunkError, 1)
 	scd.FuncDownload(scd.ctx, scd, 1)
 	select {
@@ -385,3 +567,421 @@ func TestDownloadChunkErrorStatus(t *testing.T) {
 		t.Fatal("should have caused an error and queued in scd.ChunksError")
 	}
 }
+
+func getChunkTestJSONBody(_ context.Context, _ *snowflakeChunkDownloader, _ string, _ map[string]string, _ time.Duration) (
+	*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       &fakeResponseBody{body: []byte(`["1", "2020-01-01"], ["2", null]`)},
+	}, nil
+}
+
+func TestDownloadChunkTypedJSON(t *testing.T) {
+	rt := []execResponseRowType{
+		{Name: "c1", Type: "fixed"},
+		{Name: "c2", Type: "text"},
+	}
+	scd := &snowflakeChunkDownloader{
+		sc: &snowflakeConn{
+			rest: &snowflakeRestful{RequestTimeout: defaultRequestTimeout},
+		},
+		ctx:                context.Background(),
+		ChunkMetas:         []execResponseChunk{{URL: "dummyURL1", RowCount: 2}},
+		TotalRowIndex:      int64(-1),
+		Qrmk:               "HOHOHO",
+		FuncDownload:       downloadChunk,
+		FuncDownloadHelper: downloadChunkHelper,
+		FuncGet:            getChunkTestJSONBody,
+		RowSet:             rowSetType{RowType: rt},
+	}
+	scd.ChunksMutex = &sync.Mutex{}
+	scd.DoneDownloadCond = sync.NewCond(scd.ChunksMutex)
+	scd.Chunks = make(map[int][]chunkRowType)
+	scd.ChunksDownloaded = make(map[int]bool)
+	scd.ChunksError = make(chan *chunkError, 1)
+	scd.FuncDownload(scd.ctx, scd, 0)
+	select {
+	case errc := <-scd.ChunksError:
+		t.Fatalf("failed to download chunk. err: %v", errc.Error)
+	default:
+	}
+	if len(scd.Chunks[0]) != 2 || scd.Chunks[0][0].TypedRow == nil {
+		t.Fatalf("chunk is not decoded typed: %v", scd.Chunks[0])
+	}
+	rows := new(snowflakeRows)
+	rows.RowType = rt
+	rows.ChunkDownloader = scd
+	scd.CurrentChunk = scd.Chunks[0]
+	scd.CurrentChunkSize = len(scd.CurrentChunk)
+	scd.CurrentIndex = -1
+	dest := make([]driver.Value, 2)
+	if err := rows.Next(dest); err != nil {
+		t.Fatalf("failed to get value. err: %v", err)
+	}
+	if dest[0] != "1" || dest[1] != "2020-01-01" {
+		t.Fatalf("unexpected row: %v", dest)
+	}
+	if err := rows.Next(dest); err != nil {
+		t.Fatalf("failed to get value. err: %v", err)
+	}
+	if dest[0] != "2" || dest[1] != nil {
+		t.Fatalf("unexpected row: %v", dest)
+	}
+}
+
+func newJSONChunkTestRows(t *testing.T, numChunks int) *snowflakeRows {
+	rt := []execResponseRowType{
+		{Name: "c1", Type: "fixed"},
+		{Name: "c2", Type: "text"},
+	}
+	cm := make([]execResponseChunk, 0)
+	for i := 0; i < numChunks; i++ {
+		cm = append(cm, execResponseChunk{URL: fmt.Sprintf("dummyURL%v", i+1), RowCount: 2})
+	}
+	sc := &snowflakeConn{
+		ctx:  context.Background(),
+		rest: &snowflakeRestful{RequestTimeout: defaultRequestTimeout},
+	}
+	rows := new(snowflakeRows)
+	rows.sc = sc
+	rows.RowType = rt
+	rows.ChunkDownloader = &snowflakeChunkDownloader{
+		sc:                 sc,
+		ctx:                context.Background(),
+		Total:              int64(numChunks * 2),
+		ChunkMetas:         cm,
+		TotalRowIndex:      int64(-1),
+		FuncDownload:       downloadChunk,
+		FuncDownloadHelper: downloadChunkHelper,
+		FuncGet:            getChunkTestJSONBody,
+		RowSet:             rowSetType{RowType: rt},
+	}
+	if err := rows.ChunkDownloader.start(); err != nil {
+		t.Fatalf("failed to start chunk downloader. err: %v", err)
+	}
+	return rows
+}
+
+func TestRowsWithSpilledChunks(t *testing.T) {
+	backupChunkSpillEnabled, backupChunkSpillDir := ChunkSpillEnabled, ChunkSpillDir
+	ChunkSpillEnabled, ChunkSpillDir = true, t.TempDir()
+	defer func() {
+		ChunkSpillEnabled, ChunkSpillDir = backupChunkSpillEnabled, backupChunkSpillDir
+	}()
+	numChunks := 5
+	rows := newJSONChunkTestRows(t, numChunks)
+	dest := make([]driver.Value, 2)
+	cnt := 0
+	for {
+		err := rows.Next(dest)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to get value. err: %v", err)
+		}
+		if cnt%2 == 0 && (dest[0] != "1" || dest[1] != "2020-01-01") {
+			t.Fatalf("unexpected row: %v", dest)
+		}
+		cnt++
+	}
+	if cnt != numChunks*2 {
+		t.Fatalf("failed to get all results. expected: %v, got: %v", numChunks*2, cnt)
+	}
+	if len(rows.ChunkDownloader.SpilledChunks) != 0 {
+		t.Fatalf("spilled chunks are left: %v", rows.ChunkDownloader.SpilledChunks)
+	}
+}
+
+func TestRowsCloseRemovesSpilledChunks(t *testing.T) {
+	backupChunkSpillEnabled, backupChunkSpillDir := ChunkSpillEnabled, ChunkSpillDir
+	ChunkSpillEnabled, ChunkSpillDir = true, t.TempDir()
+	defer func() {
+		ChunkSpillEnabled, ChunkSpillDir = backupChunkSpillEnabled, backupChunkSpillDir
+	}()
+	numChunks := 3
+	rows := newJSONChunkTestRows(t, numChunks)
+	scd := rows.ChunkDownloader
+	scd.schedule()
+	scd.ChunksMutex.Lock()
+	for len(scd.SpilledChunks) < numChunks {
+		if err := scd.checkErrorRetry(); err != nil {
+			scd.ChunksMutex.Unlock()
+			t.Fatalf("failed to download chunks. err: %v", err)
+		}
+		scd.DoneDownloadCond.Wait()
+	}
+	files := make([]*os.File, 0, numChunks)
+	for _, f := range scd.SpilledChunks {
+		files = append(files, f)
+	}
+	scd.ChunksMutex.Unlock()
+
+	magic := make([]byte, 2)
+	if _, err := files[0].ReadAt(magic, 0); err != nil {
+		t.Fatalf("failed to read spilled chunk. err: %v", err)
+	}
+	if magic[0] != 0x1f || magic[1] != 0x8b {
+		t.Fatalf("spilled chunk is not compressed: %v", magic)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatalf("failed to close rows. err: %v", err)
+	}
+	if len(scd.SpilledChunks) != 0 {
+		t.Fatalf("spilled chunks are left: %v", scd.SpilledChunks)
+	}
+	for _, f := range files {
+		if _, err := f.Stat(); err == nil {
+			t.Fatalf("spilled chunk file is still open: %v", f.Name())
+		}
+	}
+	entries, err := ioutil.ReadDir(filepath.Join(ChunkSpillDir, spillDirPrefix+strconv.Itoa(os.Getpid())))
+	if err != nil {
+		t.Fatalf("failed to read spill directory. err: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("spilled chunk files are left: %v", entries)
+	}
+}
+
+func TestSpillDirSweepsStaleDirs(t *testing.T) {
+	backupChunkSpillDir := ChunkSpillDir
+	ChunkSpillDir = t.TempDir()
+	defer func() {
+		ChunkSpillDir = backupChunkSpillDir
+	}()
+	exited := exec.Command(os.Args[0], "-test.run=^$")
+	exited.Env = append(os.Environ(), "SKIP_SETUP=true")
+	if err := exited.Run(); err != nil {
+		t.Fatalf("failed to run a process. err: %v", err)
+	}
+	stale := filepath.Join(ChunkSpillDir, spillDirPrefix+strconv.Itoa(exited.ProcessState.Pid()))
+	running := filepath.Join(ChunkSpillDir, spillDirPrefix+strconv.Itoa(os.Getppid()))
+	other := filepath.Join(ChunkSpillDir, spillDirPrefix+"other")
+	for _, dir := range []string{stale, running, other} {
+		if err := os.Mkdir(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(stale, spilledChunkFilePattern+"1"), []byte("chunk"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := spillDir()
+	if err != nil {
+		t.Fatalf("failed to create the spill directory. err: %v", err)
+	}
+	if dir != filepath.Join(ChunkSpillDir, spillDirPrefix+strconv.Itoa(os.Getpid())) {
+		t.Fatalf("unexpected directory: %v", dir)
+	}
+	if _, err = os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("the directory of the exited process is left. err: %v", err)
+	}
+	for _, d := range []string{dir, running, other} {
+		if _, err = os.Stat(d); err != nil {
+			t.Fatalf("the directory is removed: %v, err: %v", d, err)
+		}
+	}
+	// a directory removed by a cleaner is created again
+	if err = os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = spillDir(); err != nil {
+		t.Fatalf("failed to create the spill directory. err: %v", err)
+	}
+	if _, err = os.Stat(dir); err != nil {
+		t.Fatalf("the directory is not created. err: %v", err)
+	}
+}
+
+func TestRowsProgress(t *testing.T) {
+	backupMaxChunkDownloadWorkers := MaxChunkDownloadWorkers
+	MaxChunkDownloadWorkers = 1
+	defer func() {
+		MaxChunkDownloadWorkers = backupMaxChunkDownloadWorkers
+	}()
+	numChunks := 4
+	rows := newJSONChunkTestRows(t, numChunks)
+	var progress SnowflakeRowsProgress = rows
+	if progress.TotalRows() != int64(numChunks*2) {
+		t.Fatalf("unexpected total rows. expected: %v, got: %v", numChunks*2, progress.TotalRows())
+	}
+	expected := ChunkDownloadProgress{Total: numChunks, Pending: numChunks}
+	if p := progress.ChunkDownloadProgress(); p != expected {
+		t.Fatalf("unexpected progress. expected: %+v, got: %+v", expected, p)
+	}
+	dest := make([]driver.Value, 2)
+	for i := 0; i < 3; i++ {
+		if err := rows.Next(dest); err != nil {
+			t.Fatalf("failed to get value. err: %v", err)
+		}
+	}
+	if progress.ConsumedRows() != 3 {
+		t.Fatalf("unexpected consumed rows. expected: %v, got: %v", 3, progress.ConsumedRows())
+	}
+	// the second chunk is current and the third one is prefetched
+	scd := rows.ChunkDownloader
+	scd.ChunksMutex.Lock()
+	for scd.Chunks[2] == nil {
+		scd.DoneDownloadCond.Wait()
+	}
+	scd.ChunksMutex.Unlock()
+	expected = ChunkDownloadProgress{Total: numChunks, Downloaded: 3, Pending: 1}
+	if p := progress.ChunkDownloadProgress(); p != expected {
+		t.Fatalf("unexpected progress. expected: %+v, got: %+v", expected, p)
+	}
+	if err := rows.SkipRows(2); err != nil {
+		t.Fatalf("failed to skip. err: %v", err)
+	}
+	if progress.ConsumedRows() != 5 {
+		t.Fatalf("unexpected consumed rows. expected: %v, got: %v", 5, progress.ConsumedRows())
+	}
+	for {
+		if err := rows.Next(dest); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("failed to get value. err: %v", err)
+		}
+	}
+	if progress.ConsumedRows() != progress.TotalRows() {
+		t.Fatalf("unexpected consumed rows. expected: %v, got: %v", progress.TotalRows(), progress.ConsumedRows())
+	}
+	expected = ChunkDownloadProgress{Total: numChunks, Downloaded: numChunks}
+	if p := progress.ChunkDownloadProgress(); p != expected {
+		t.Fatalf("unexpected progress. expected: %+v, got: %+v", expected, p)
+	}
+}
+
+func TestRowsWithChunkDownloaderRetryPerChunk(t *testing.T) {
+	defer disableChunkRetryWait()()
+	numChunks := 6
+	rows := newJSONChunkTestRows(t, numChunks)
+	scd := rows.ChunkDownloader
+	// every other chunk fails up to the limit of retries. more than the limit fail in total.
+	failures := make(map[int]int)
+	scd.FuncDownloadHelper = func(ctx context.Context, scd *snowflakeChunkDownloader, idx int) error {
+		scd.ChunksMutex.Lock()
+		fail := idx%2 == 0 && failures[idx] < maxChunkDownloaderErrorCounter
+		if fail {
+			failures[idx]++
+		}
+		scd.ChunksMutex.Unlock()
+		if fail {
+			return fmt.Errorf("dummy error. idx: %v", idx+1)
+		}
+		return downloadChunkHelper(ctx, scd, idx)
+	}
+	cnt := 0
+	dest := make([]driver.Value, 2)
+	for {
+		err := rows.Next(dest)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to get value. err: %v", err)
+		}
+		cnt++
+	}
+	if cnt != numChunks*2 {
+		t.Fatalf("failed to get all results. expected: %v, got: %v", numChunks*2, cnt)
+	}
+	if scd.ChunksErrorCounter != numChunks/2*maxChunkDownloaderErrorCounter {
+		t.Fatalf("unexpected number of retries. expected: %v, got: %v",
+			numChunks/2*maxChunkDownloaderErrorCounter, scd.ChunksErrorCounter)
+	}
+}
+
+func TestRowsWithChunkDownloaderURLExpired(t *testing.T) {
+	defer disableChunkRetryWait()()
+	backupMaxChunkDownloadWorkers := MaxChunkDownloadWorkers
+	MaxChunkDownloadWorkers = 1
+	defer func() {
+		MaxChunkDownloadWorkers = backupMaxChunkDownloadWorkers
+	}()
+	numChunks := 3
+	rows := newJSONChunkTestRows(t, numChunks)
+	scd := rows.ChunkDownloader
+	scd.QueryID = "dummyQueryID"
+	scd.FuncGet = func(ctx context.Context, scd *snowflakeChunkDownloader, fullURL string, headers map[string]string, timeout time.Duration) (
+		*http.Response, error) {
+		if !strings.HasPrefix(fullURL, "refreshed") {
+			return &http.Response{
+				StatusCode: http.StatusForbidden,
+				Body:       &fakeResponseBody{body: []byte("expired")},
+			}, nil
+		}
+		if headers[headerSseCKey] != "refreshedQrmk" {
+			t.Errorf("unexpected chunk header: %v", headers)
+		}
+		return getChunkTestJSONBody(ctx, scd, fullURL, headers, timeout)
+	}
+	refreshes := 0
+	scd.FuncGetResult = func(_ context.Context, scd *snowflakeChunkDownloader) (*execResponseData, error) {
+		refreshes++
+		if scd.QueryID != "dummyQueryID" {
+			t.Errorf("unexpected query ID: %v", scd.QueryID)
+		}
+		cm := make([]execResponseChunk, numChunks)
+		for i := range cm {
+			cm[i] = execResponseChunk{URL: fmt.Sprintf("refreshedURL%v", i+1), RowCount: 2}
+		}
+		return &execResponseData{Chunks: cm, Qrmk: "refreshedQrmk"}, nil
+	}
+	cnt := 0
+	dest := make([]driver.Value, 2)
+	for {
+		err := rows.Next(dest)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to get value. err: %v", err)
+		}
+		cnt++
+	}
+	if cnt != numChunks*2 {
+		t.Fatalf("failed to get all results. expected: %v, got: %v", numChunks*2, cnt)
+	}
+	if refreshes != 1 {
+		t.Fatalf("chunk URLs should be refreshed once. got: %v", refreshes)
+	}
+}
+
+func TestRowsColumnMetadata(t *testing.T) {
+	var data execResponseData
+	if err := json.Unmarshal([]byte(`{"rowtype": [
+		{"name": "C1", "database": "DB1", "schema": "PUBLIC", "table": "T1", "byteLength": null, "length": null,
+			"type": "fixed", "scale": 2, "precision": 38, "nullable": false, "collation": null},
+		{"name": "C2", "database": "DB1", "schema": "PUBLIC", "table": "T1", "byteLength": 64, "length": 16,
+			"type": "text", "scale": null, "precision": null, "nullable": true, "collation": "en-ci"},
+		{"name": "C3", "database": "", "schema": "", "table": "", "byteLength": null, "length": null,
+			"type": "timestamp_tz", "scale": 9, "precision": 0, "nullable": true}]}`), &data); err != nil {
+		t.Fatalf("failed to parse row type. err: %v", err)
+	}
+	rows := &snowflakeRows{RowType: data.RowType}
+	expected := []ColumnMetadata{
+		{Name: "C1", Type: "FIXED", Precision: 38, Scale: 2, Database: "DB1", Schema: "PUBLIC", Table: "T1"},
+		{Name: "C2", Type: "TEXT", Length: 16, ByteLength: 64, Nullable: true, Collation: "en-ci", Database: "DB1",
+			Schema: "PUBLIC", Table: "T1"},
+		{Name: "C3", Type: "TIMESTAMP_TZ", Scale: 9, Nullable: true},
+	}
+	columns := rows.ColumnMetadata()
+	if !reflect.DeepEqual(columns, expected) {
+		t.Fatalf("unexpected metadata. expected: %+v, got: %+v", expected, columns)
+	}
+	for i, declared := range []string{"NUMBER(38,2)", "VARCHAR(16) COLLATE 'en-ci'", "TIMESTAMP_TZ(9)"} {
+		if columns[i].DeclaredType() != declared {
+			t.Errorf("unexpected declared type. expected: %v, got: %v", declared, columns[i].DeclaredType())
+		}
+	}
+	if length, ok := rows.ColumnTypeLength(0); !ok || length != 38 {
+		t.Errorf("unexpected length of FIXED: %v, %v", length, ok)
+	}
+	if precision, scale, ok := rows.ColumnTypePrecisionScale(2); !ok || precision != 9 || scale != 0 {
+		t.Errorf("unexpected precision and scale of TIMESTAMP_TZ: %v, %v, %v", precision, scale, ok)
+	}
+	if _, ok := rows.ColumnTypeLength(3); ok {
+		t.Error("should fail to get the length of a column out of range")
+	}
+}
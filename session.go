@@ -0,0 +1,119 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SnowflakeSession provides the state of the session of the connection. The driver connection implements it, and is
+// reachable through sql.Conn.Raw.
+type SnowflakeSession interface {
+	SessionState() SessionState
+}
+
+// SessionState is a snapshot of the state of a session, as of the last query. It is a copy, which doesn't change with
+// later queries.
+type SessionState struct {
+	Database      string // current database, empty if none
+	Schema        string // current schema, empty if none
+	Role          string // current role
+	Warehouse     string // current warehouse, empty if none
+	SessionID     int64
+	ServerVersion string // version of Snowflake at the login, e.g., 4.22.2
+	Parameters    SessionParameters
+}
+
+// SessionParameters are the parameters of a session the server returns, along with those of Config.Params, by
+// lowercase name, e.g., timezone or query_tag. The server returns a part of the parameters, and not all are updated
+// by ALTER SESSION.
+type SessionParameters map[string]string
+
+// String returns the value of the parameter of the case-insensitive name, and false if it isn't set.
+func (p SessionParameters) String(name string) (string, bool) {
+	v, ok := p[strings.ToLower(name)]
+	return v, ok
+}
+
+// Bool returns the value of the boolean parameter of the case-insensitive name, and false if it isn't set. The error
+// is non-nil if the value isn't a boolean.
+func (p SessionParameters) Bool(name string) (bool, bool, error) {
+	v, ok := p.String(name)
+	if !ok {
+		return false, false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, true, fmt.Errorf("parameter %v is not a boolean: %v", name, v)
+	}
+	return b, true, nil
+}
+
+// Int returns the value of the integer parameter of the case-insensitive name, and false if it isn't set. The error
+// is non-nil if the value isn't an integer.
+func (p SessionParameters) Int(name string) (int64, bool, error) {
+	v, ok := p.String(name)
+	if !ok {
+		return 0, false, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, true, fmt.Errorf("parameter %v is not an integer: %v", name, v)
+	}
+	return n, true, nil
+}
+
+// Float returns the value of the numeric parameter of the case-insensitive name, and false if it isn't set. The error
+// is non-nil if the value isn't a number.
+func (p SessionParameters) Float(name string) (float64, bool, error) {
+	v, ok := p.String(name)
+	if !ok {
+		return 0, false, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, true, fmt.Errorf("parameter %v is not a number: %v", name, v)
+	}
+	return f, true, nil
+}
+
+// SessionState returns a snapshot of the state of the session. The database, schema, role, warehouse and parameters
+// are read under stateMu, with which a query updates them, so they may be read while a query runs on the connection.
+// The session ID and the server version are set at the login.
+func (sc *snowflakeConn) SessionState() SessionState {
+	sc.stateMu.RLock()
+	defer sc.stateMu.RUnlock()
+	state := SessionState{
+		Database:      sc.cfg.Database,
+		Schema:        sc.cfg.Schema,
+		Role:          sc.cfg.Role,
+		Warehouse:     sc.cfg.Warehouse,
+		ServerVersion: sc.serverVersion,
+		Parameters:    make(SessionParameters, len(sc.cfg.Params)),
+	}
+	if sc.rest != nil {
+		state.SessionID = int64(sc.rest.SessionID)
+	}
+	for k, v := range sc.cfg.Params {
+		if v != nil {
+			state.Parameters[strings.ToLower(k)] = *v
+		}
+	}
+	return state
+}
+
+// GetSessionState returns a snapshot of the state of the session of the connection.
+func GetSessionState(conn *sql.Conn) (state SessionState, err error) {
+	err = conn.Raw(func(x interface{}) error {
+		session, ok := x.(SnowflakeSession)
+		if !ok {
+			return fmt.Errorf("not a Snowflake connection: %T", x)
+		}
+		state = session.SessionState()
+		return nil
+	})
+	return state, err
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSessionState(t *testing.T) {
+	var mu sync.Mutex
+	var n int
+	respond := func(string, map[string]execBindParameter) (*execResponseData, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		n++
+		return &execResponseData{
+			FinalDatabaseName:  fmt.Sprintf("DB%v", n),
+			FinalSchemaName:    "PUBLIC",
+			FinalRoleName:      "SYSADMIN",
+			FinalWarehouseName: "WH",
+			Parameters: []nameValueParameter{
+				{Name: "TIMEZONE", Value: "UTC"}, {Name: "CLIENT_RESULT_PREFETCH_THREADS", Value: float64(4)},
+				{Name: "AUTOCOMMIT", Value: true}, {Name: "QUERY_TAG", Value: fmt.Sprintf("job%v", n)},
+			},
+		}, nil
+	}
+	sc := newFileTransferTestConn(nil)
+	sc.rest.SessionID = 1234
+	sc.serverVersion = "4.22.2"
+	keepAlive := "false"
+	sc.cfg.Params["CLIENT_SESSION_KEEP_ALIVE"] = &keepAlive
+	db := sql.OpenDB(&fakeConnector{handler: respond, sc: sc})
+	defer db.Close()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(context.Background(), "USE DATABASE DB1"); err != nil {
+		t.Fatalf("failed to exec. err: %v", err)
+	}
+	state, err := GetSessionState(conn)
+	if err != nil {
+		t.Fatalf("failed to get the state. err: %v", err)
+	}
+	if state.Database != "DB1" || state.Schema != "PUBLIC" || state.Role != "SYSADMIN" || state.Warehouse != "WH" ||
+		state.SessionID != 1234 || state.ServerVersion != "4.22.2" {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+	if tz, ok := state.Parameters.String("TimeZone"); !ok || tz != "UTC" {
+		t.Fatalf("unexpected timezone: %v", tz)
+	}
+	if threads, ok, err := state.Parameters.Int("client_result_prefetch_threads"); err != nil || !ok || threads != 4 {
+		t.Fatalf("unexpected threads: %v, err: %v", threads, err)
+	}
+	if autocommit, ok, err := state.Parameters.Bool("AUTOCOMMIT"); err != nil || !ok || !autocommit {
+		t.Fatalf("unexpected autocommit: %v, err: %v", autocommit, err)
+	}
+	if keepAlive, ok, err := state.Parameters.Bool("client_session_keep_alive"); err != nil || !ok || keepAlive {
+		t.Fatalf("unexpected keep alive: %v, err: %v", keepAlive, err)
+	}
+	if _, ok, err := state.Parameters.Int("missing"); ok || err != nil {
+		t.Fatalf("unexpected missing parameter: %v, err: %v", ok, err)
+	}
+	if _, _, err = state.Parameters.Float("timezone"); err == nil {
+		t.Fatal("should fail with a non-numeric parameter")
+	}
+
+	// the snapshot is a copy while the state is updated concurrently
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if s := sc.SessionState(); s.Role != "SYSADMIN" {
+				t.Errorf("unexpected state: %+v", s)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		if _, err = conn.ExecContext(context.Background(), "ALTER SESSION SET QUERY_TAG = 'job'"); err != nil {
+			t.Fatalf("failed to exec. err: %v", err)
+		}
+	}
+	wg.Wait()
+	if state.Database != "DB1" || state.Parameters["query_tag"] != "job1" {
+		t.Fatalf("the snapshot has changed: %+v", state)
+	}
+	if state, err = GetSessionState(conn); err != nil || state.Database != "DB11" ||
+		state.Parameters["query_tag"] != "job11" {
+		t.Fatalf("unexpected state: %+v, err: %v", state, err)
+	}
+}
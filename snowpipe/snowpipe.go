@@ -0,0 +1,271 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+// Package snowpipe calls the Snowpipe REST API of a pipe to ingest staged files and to report their load status.
+//
+// Client authenticates with the key pair of a gosnowflake.Config, signing a new JWT for every attempt of a request,
+// and retries the requests on throttling, HTTP 429, as well as on network and server errors, as the driver does:
+//
+//	client, err := snowpipe.NewClient(&sf.Config{
+//		Account:    "myaccount",
+//		User:       "myuser",
+//		PrivateKey: privateKey,
+//	}, "mydb.public.mypipe")
+//	...
+//	_, err = client.InsertFiles(ctx, []snowpipe.File{{Path: "orders/2020-06-01.csv.gz"}})
+//	...
+//	report, err := client.InsertReport(ctx, "")
+//	for _, f := range report.Files {
+//		log.Printf("%v: %v, %v rows inserted", f.Path, f.Status, f.RowsInserted)
+//	}
+//
+// InsertFiles queues staged files to be loaded, InsertReport returns the recent load events from a begin mark, and
+// LoadHistoryScan returns the load history of a time range, calling the endpoint until the history is complete and
+// returning the files at the boundaries of the calls once. It fails with gosnowflake.ErrSnowpipeHistoryIncomplete
+// rather than return a partial history if the range has too many files to scan. Other errors are
+// *gosnowflake.SnowflakeError with gosnowflake.ErrFailedToCallSnowpipe and the code and message of Snowpipe.
+package snowpipe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	sf "github.com/snowflakedb/gosnowflake"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	pathFormat        = "/v1/data/pipes/%v/%v"
+	insertFiles       = "insertFiles"
+	insertReport      = "insertReport"
+	loadHistoryScan   = "loadHistoryScan"
+	timeFormat        = "2006-01-02T15:04:05.000Z"
+	responseSuccess   = "SUCCESS"
+	maxHistoryScanned = 1000 // pages of loadHistoryScan
+
+	errMsgFailedToCall          = "failed to call %v of Snowpipe. HTTP: %v, code: %v, message: %v"
+	errMsgFailedToParseResponse = "failed to parse a response from Snowpipe. Response: %v"
+	errMsgHistoryIncomplete     = "load history is incomplete from %v after %v calls of loadHistoryScan"
+)
+
+// Client calls the Snowpipe REST endpoints of a pipe with the key pair authentication of a gosnowflake.Config. The
+// requests are retried on network errors, 5xx errors and throttling until Config.RequestTimeout as the driver's
+// requests are.
+type Client struct {
+	rest  *sf.RESTClient
+	pipe  string
+	token func() (string, error) // generates the JWT of an attempt
+}
+
+// File is a file to ingest, relative to the stage of the pipe.
+type File struct {
+	Path string `json:"path"`
+	Size int64  `json:"size,omitempty"` // optional
+}
+
+// InsertResponse is the response of insertFiles.
+type InsertResponse struct {
+	RequestID    string `json:"requestId"`
+	ResponseCode string `json:"responseCode"` // SUCCESS once the files are queued
+}
+
+// FileStatus is the load status of a file of insertReport or loadHistoryScan.
+type FileStatus struct {
+	Path                   string    `json:"path"`
+	StageLocation          string    `json:"stageLocation"`
+	FileSize               int64     `json:"fileSize"`
+	TimeReceived           time.Time `json:"timeReceived"`
+	LastInsertTime         time.Time `json:"lastInsertTime"`
+	RowsInserted           int64     `json:"rowsInserted"`
+	RowsParsed             int64     `json:"rowsParsed"`
+	ErrorsSeen             int64     `json:"errorsSeen"`
+	ErrorLimit             int64     `json:"errorLimit"`
+	FirstError             string    `json:"firstError"`
+	FirstErrorLineNum      int64     `json:"firstErrorLineNum"`
+	FirstErrorCharacterPos int64     `json:"firstErrorCharacterPos"`
+	FirstErrorColumnName   string    `json:"firstErrorColumnName"`
+	SystemError            string    `json:"systemError"`
+	Complete               bool      `json:"complete"`
+	Status                 string    `json:"status"` // LOADED, LOAD_IN_PROGRESS, LOAD_FAILED or PARTIALLY_LOADED
+}
+
+// InsertReport is the response of insertReport, the files ingested recently. Pass NextBeginMark to the next call to
+// get the events from there.
+type InsertReport struct {
+	Pipe           string       `json:"pipe"`
+	CompleteResult bool         `json:"completeResult"`
+	NextBeginMark  string       `json:"nextBeginMark"`
+	Files          []FileStatus `json:"files"`
+	Statistics     struct {
+		ActiveFilesCount int64 `json:"activeFilesCount"`
+	} `json:"statistics"`
+}
+
+// LoadHistory is the load history of the files of loadHistoryScan.
+type LoadHistory struct {
+	Pipe               string       `json:"pipe"`
+	StartTimeInclusive string       `json:"startTimeInclusive"`
+	EndTimeExclusive   string       `json:"endTimeExclusive"`
+	RangeStartTime     string       `json:"rangeStartTime"`
+	RangeEndTime       string       `json:"rangeEndTime"`
+	CompleteResult     bool         `json:"completeResult"`
+	Files              []FileStatus `json:"files"`
+}
+
+// errorResponse is the body of an error of Snowpipe.
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Success bool   `json:"success"`
+}
+
+// NewClient returns the client of the pipe, a fully qualified name, e.g., mydb.public.mypipe. cfg must have Account,
+// User and PrivateKey. Host, Port and Protocol are derived from Account unless given.
+func NewClient(cfg *sf.Config, pipe string) (*Client, error) {
+	rest, err := sf.NewRESTClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{rest: rest, pipe: pipe, token: rest.NewJWTToken}, nil
+}
+
+// InsertFiles queues the files to be ingested by the pipe. Up to 5000 files can be sent in a call.
+func (c *Client) InsertFiles(ctx context.Context, files []File) (*InsertResponse, error) {
+	body, err := json.Marshal(struct {
+		Files []File `json:"files"`
+	}{files})
+	if err != nil {
+		return nil, err
+	}
+	var resp InsertResponse
+	if err = c.call(ctx, insertFiles, url.Values{}, body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// InsertReport returns the files ingested since beginMark, or in the last 10 minutes if beginMark is empty.
+func (c *Client) InsertReport(ctx context.Context, beginMark string) (*InsertReport, error) {
+	params := url.Values{}
+	if beginMark != "" {
+		params.Set("beginMark", beginMark)
+	}
+	var report InsertReport
+	if err := c.call(ctx, insertReport, params, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// LoadHistoryScan returns the load history of the files ingested between start, inclusive, and end, exclusive. The
+// endpoint returns a limited number of files per call, so it is called again from the end of the range of the last
+// call until the history is complete. The files at the boundary of two calls are returned once. end may be zero for
+// now. The error is a *gosnowflake.SnowflakeError with ErrSnowpipeHistoryIncomplete if the history isn't complete
+// after 1000 calls, or if a call doesn't advance the range, in which case a narrower range may be scanned.
+func (c *Client) LoadHistoryScan(ctx context.Context, start time.Time, end time.Time) (*LoadHistory, error) {
+	type fileKey struct {
+		path           string
+		lastInsertTime int64
+	}
+	history := &LoadHistory{}
+	seen := make(map[fileKey]bool)
+	from := start.UTC().Format(timeFormat)
+	for i := 1; ; i++ {
+		params := url.Values{"startTimeInclusive": {from}}
+		if !end.IsZero() {
+			params.Set("endTimeExclusive", end.UTC().Format(timeFormat))
+		}
+		var page LoadHistory
+		if err := c.call(ctx, loadHistoryScan, params, nil, &page); err != nil {
+			return nil, err
+		}
+		if i == 1 {
+			history.Pipe = page.Pipe
+			history.StartTimeInclusive = page.StartTimeInclusive
+			history.RangeStartTime = page.RangeStartTime
+		}
+		history.EndTimeExclusive = page.EndTimeExclusive
+		history.RangeEndTime = page.RangeEndTime
+		history.CompleteResult = page.CompleteResult
+		for _, f := range page.Files {
+			// the next call starts at the end of the range, which is inclusive, so the files there are returned again
+			key := fileKey{f.Path, f.LastInsertTime.UnixNano()}
+			if !seen[key] {
+				seen[key] = true
+				history.Files = append(history.Files, f)
+			}
+		}
+		if page.CompleteResult || page.RangeEndTime == "" {
+			return history, nil
+		}
+		if i == maxHistoryScanned || page.RangeEndTime == from {
+			return nil, &sf.SnowflakeError{
+				Number:      sf.ErrSnowpipeHistoryIncomplete,
+				Message:     errMsgHistoryIncomplete,
+				MessageArgs: []interface{}{from, i},
+			}
+		}
+		from = page.RangeEndTime
+	}
+}
+
+// call calls the endpoint of the pipe with the params and body, POST if body isn't nil or GET otherwise, and decodes
+// the response into v. Every attempt is signed with a new JWT.
+func (c *Client) call(ctx context.Context, endpoint string, params url.Values, body []byte, v interface{}) error {
+	req := &sf.RESTRequest{
+		Path:    fmt.Sprintf(pathFormat, c.pipe, endpoint),
+		Query:   params,
+		Headers: map[string]string{"X-Snowflake-Authorization-Token-Type": "KEYPAIR_JWT"},
+		Prepare: func(r *http.Request) error {
+			token, err := c.token()
+			if err != nil {
+				return err
+			}
+			r.Header.Set("Authorization", "Bearer "+token)
+			return nil
+		},
+	}
+	if body != nil {
+		req.Method = http.MethodPost
+		req.Headers["Content-Type"] = "application/json"
+		req.Body = body
+	}
+	res, err := c.rest.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		json.Unmarshal(b, &errResp)
+		if errResp.Message == "" {
+			errResp.Message = string(b)
+		}
+		return &sf.SnowflakeError{
+			Number:      sf.ErrFailedToCallSnowpipe,
+			Message:     errMsgFailedToCall,
+			MessageArgs: []interface{}{endpoint, res.StatusCode, errResp.Code, errResp.Message},
+		}
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		return &sf.SnowflakeError{
+			Number:      sf.ErrFailedToParseResponse,
+			Message:     errMsgFailedToParseResponse,
+			MessageArgs: []interface{}{string(b)},
+		}
+	}
+	if resp, ok := v.(*InsertResponse); ok && resp.ResponseCode != responseSuccess {
+		return &sf.SnowflakeError{
+			Number:      sf.ErrFailedToCallSnowpipe,
+			Message:     errMsgFailedToCall,
+			MessageArgs: []interface{}{endpoint, res.StatusCode, resp.ResponseCode, string(b)},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package snowpipe
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	sf "github.com/snowflakedb/gosnowflake"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client of mydb.public.mypipe calling the handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, func()) {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(&sf.Config{
+		Account:    "testaccount",
+		User:       "testuser",
+		PrivateKey: key,
+		Protocol:   "http",
+		Host:       u.Hostname(),
+		Port:       port,
+	}, "mydb.public.mypipe")
+	if err != nil {
+		t.Fatalf("failed to create a client. err: %v", err)
+	}
+	return client, ts.Close
+}
+
+func TestInsertFiles(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+	var tokens []string
+	client, closeServer := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		n := calls
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		mu.Unlock()
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/data/pipes/mydb.public.mypipe/insertFiles" ||
+			r.URL.Query().Get("requestId") == "" || !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") ||
+			r.Header.Get("X-Snowflake-Authorization-Token-Type") != "KEYPAIR_JWT" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if n == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		var body struct {
+			Files []File `json:"files"`
+		}
+		if err := json.Unmarshal(b, &body); err != nil || len(body.Files) != 2 || body.Files[1].Size != 10 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"requestId":"` + r.URL.Query().Get("requestId") + `","responseCode":"SUCCESS"}`))
+	})
+	defer closeServer()
+	var issued int
+	client.token = func() (string, error) {
+		issued++
+		return "token" + strconv.Itoa(issued), nil
+	}
+
+	resp, err := client.InsertFiles(context.Background(), []File{{Path: "a.csv"}, {Path: "b.csv", Size: 10}})
+	if err != nil {
+		t.Fatalf("failed to insert files. err: %v", err)
+	}
+	if resp.ResponseCode != "SUCCESS" || resp.RequestID == "" || calls != 2 {
+		t.Fatalf("unexpected response: %+v, calls: %v", resp, calls)
+	}
+	if strings.Join(tokens, ",") != "Bearer token1,Bearer token2" {
+		t.Fatalf("should sign a new JWT for the retry: %v", tokens)
+	}
+}
+
+func TestError(t *testing.T) {
+	client, closeServer := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":"390404","message":"Specified object does not exist or not authorized.","success":false}`))
+	})
+	defer closeServer()
+
+	_, err := client.InsertFiles(context.Background(), []File{{Path: "a.csv"}})
+	driverErr, ok := err.(*sf.SnowflakeError)
+	if !ok || driverErr.Number != sf.ErrFailedToCallSnowpipe || driverErr.MessageArgs[1] != http.StatusNotFound ||
+		driverErr.MessageArgs[2] != "390404" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = NewClient(&sf.Config{Account: "testaccount", User: "testuser"}, "mypipe")
+	driverErr, ok = err.(*sf.SnowflakeError)
+	if !ok || driverErr.Number != sf.ErrNoPrivateKey {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestInsertReport(t *testing.T) {
+	client, closeServer := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Query().Get("beginMark") != "1_16" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"pipe":"MYDB.PUBLIC.MYPIPE","completeResult":true,"nextBeginMark":"1_17","files":[` +
+			`{"path":"a.csv","fileSize":100,"timeReceived":"2020-06-21T04:47:41.453Z","rowsInserted":5,` +
+			`"rowsParsed":6,"errorsSeen":1,"errorLimit":6,"firstError":"bad row","firstErrorLineNum":3,` +
+			`"complete":true,"status":"PARTIALLY_LOADED"}],"statistics":{"activeFilesCount":0}}`))
+	})
+	defer closeServer()
+
+	report, err := client.InsertReport(context.Background(), "1_16")
+	if err != nil {
+		t.Fatalf("failed to get the report. err: %v", err)
+	}
+	if report.NextBeginMark != "1_17" || len(report.Files) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	f := report.Files[0]
+	if f.Path != "a.csv" || f.RowsInserted != 5 || f.FirstErrorLineNum != 3 || f.Status != "PARTIALLY_LOADED" ||
+		!f.TimeReceived.Equal(time.Date(2020, 6, 21, 4, 47, 41, 453000000, time.UTC)) {
+		t.Fatalf("unexpected file: %+v", f)
+	}
+}
+
+func TestLoadHistoryScan(t *testing.T) {
+	var starts []string
+	client, closeServer := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		start := r.URL.Query().Get("startTimeInclusive")
+		starts = append(starts, start)
+		if start == "2020-06-01T00:00:00.000Z" {
+			w.Write([]byte(`{"pipe":"MYDB.PUBLIC.MYPIPE","startTimeInclusive":"2020-06-01T00:00:00.000Z",` +
+				`"rangeStartTime":"2020-06-01T01:00:00.000Z","rangeEndTime":"2020-06-01T02:00:00.000Z",` +
+				`"completeResult":false,"files":[{"path":"a.csv","lastInsertTime":"2020-06-01T02:00:00.000Z",` +
+				`"status":"LOADED"}]}`))
+			return
+		}
+		// a.csv is at the boundary of the ranges, so it is returned again
+		w.Write([]byte(`{"pipe":"MYDB.PUBLIC.MYPIPE","startTimeInclusive":"2020-06-01T02:00:00.000Z",` +
+			`"rangeStartTime":"2020-06-01T02:00:00.000Z","rangeEndTime":"2020-06-01T03:00:00.000Z",` +
+			`"completeResult":true,"files":[{"path":"a.csv","lastInsertTime":"2020-06-01T02:00:00.000Z",` +
+			`"status":"LOADED"},{"path":"b.csv","status":"LOAD_FAILED"}]}`))
+	})
+	defer closeServer()
+
+	history, err := client.LoadHistoryScan(context.Background(), time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC),
+		time.Time{})
+	if err != nil {
+		t.Fatalf("failed to scan the history. err: %v", err)
+	}
+	if len(starts) != 2 || starts[1] != "2020-06-01T02:00:00.000Z" {
+		t.Fatalf("unexpected calls: %v", starts)
+	}
+	if !history.CompleteResult || len(history.Files) != 2 || history.Files[1].Path != "b.csv" ||
+		history.RangeStartTime != "2020-06-01T01:00:00.000Z" || history.RangeEndTime != "2020-06-01T03:00:00.000Z" {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+}
+
+func TestLoadHistoryScanIncomplete(t *testing.T) {
+	var calls int
+	client, closeServer := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		start, _ := time.Parse(timeFormat, r.URL.Query().Get("startTimeInclusive"))
+		end := start.Add(time.Minute)
+		if start.Equal(time.Date(2020, 6, 2, 12, 0, 0, 0, time.UTC)) {
+			end = start // stuck
+		}
+		w.Write([]byte(`{"completeResult":false,"rangeEndTime":"` + end.Format(timeFormat) + `","files":[]}`))
+	})
+	defer closeServer()
+
+	for _, test := range []struct {
+		start time.Time
+		calls int
+	}{
+		{time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC), maxHistoryScanned},
+		{time.Date(2020, 6, 2, 11, 58, 0, 0, time.UTC), 3},
+	} {
+		calls = 0
+		_, err := client.LoadHistoryScan(context.Background(), test.start, time.Time{})
+		if driverErr, ok := err.(*sf.SnowflakeError); !ok || driverErr.Number != sf.ErrSnowpipeHistoryIncomplete {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if calls != test.calls {
+			t.Fatalf("unexpected calls: %v, expected: %v", calls, test.calls)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stageLastModifiedFormat is the format of last_modified of LIST, e.g., Mon, 1 Jun 2020 10:00:00 GMT.
+const stageLastModifiedFormat = "Mon, 2 Jan 2006 15:04:05 MST"
+
+// StageFile is a file of a stage listed by LIST.
+type StageFile struct {
+	Name         string // including the path of the stage, e.g., mystage/dir/a.csv.gz
+	Size         int64
+	MD5          string // of the file in the storage, which differs from the MD5 of the data if encrypted by the client
+	LastModified time.Time
+}
+
+// ListStage lists the files of a stage location, e.g., @mystage/dir/ or @%mytable, matching the regular expression
+// pattern, or all files if the pattern is empty.
+func ListStage(ctx context.Context, db SQLQueryer, location string, pattern string) ([]StageFile, error) {
+	rows, err := db.QueryContext(ctx, stageQuery("LIST", location, pattern))
+	if err != nil {
+		return nil, err
+	}
+	return scanStageFiles(rows)
+}
+
+// RemoveStageFiles removes the files of a stage location matching the regular expression pattern, or all files if
+// the pattern is empty, and returns the names of the removed files.
+func RemoveStageFiles(ctx context.Context, db SQLQueryer, location string, pattern string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, stageQuery("REMOVE", location, pattern))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name, result sql.NullString
+		if err = rows.Scan(&name, &result); err != nil {
+			return nil, err
+		}
+		if result.String == "removed" {
+			names = append(names, name.String)
+		}
+	}
+	return names, rows.Err()
+}
+
+// stageQuery returns the LIST or REMOVE statement of the location and pattern.
+func stageQuery(command string, location string, pattern string) string {
+	if !strings.HasPrefix(location, "@") {
+		location = "@" + location
+	}
+	query := command + " " + location
+	if pattern != "" {
+		query += " PATTERN = " + quoteStringLiteral(pattern)
+	}
+	return query
+}
+
+// quoteStringLiteral returns s enclosed by single quotes, escaping backslashes and single quotes.
+func quoteStringLiteral(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// scanStageFiles returns the files of the rows of LIST, closing the rows.
+func scanStageFiles(rows *sql.Rows) ([]StageFile, error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	var files []StageFile
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		var f StageFile
+		for i, column := range columns {
+			v := values[i].String
+			switch strings.ToLower(column) {
+			case "name":
+				f.Name = v
+			case "size":
+				f.Size, err = strconv.ParseInt(v, 10, 64)
+			case "md5":
+				f.MD5 = v
+			case "last_modified":
+				f.LastModified, err = time.Parse(stageLastModifiedFormat, v)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid %v of LIST: %v", column, err)
+			}
+		}
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestListStage(t *testing.T) {
+	str := func(s string) *string {
+		return &s
+	}
+	db := sql.OpenDB(newFakeRowsConnector([]string{"name", "size", "md5", "last_modified"},
+		[][]*string{
+			{str("mystage/a.csv.gz"), str("1024"), str("0f343b0931126a20f133d67c2b018a3b"),
+				str("Mon, 1 Jun 2020 10:00:00 GMT")},
+			{str("mystage/b.csv.gz"), str("64"), str("d41d8cd98f00b204e9800998ecf8427e"),
+				str("Tue, 2 Jun 2020 23:59:59 GMT")},
+		}))
+	defer db.Close()
+	files, err := ListStage(context.Background(), db, "@mystage", ".*[.]csv[.]gz")
+	if err != nil {
+		t.Fatalf("failed to list. err: %v", err)
+	}
+	if len(files) != 2 || files[0].Name != "mystage/a.csv.gz" || files[0].Size != 1024 ||
+		files[0].MD5 != "0f343b0931126a20f133d67c2b018a3b" ||
+		!files[1].LastModified.Equal(time.Date(2020, 6, 2, 23, 59, 59, 0, time.UTC)) {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+}
+
+func TestRemoveStageFiles(t *testing.T) {
+	str := func(s string) *string {
+		return &s
+	}
+	db := sql.OpenDB(newFakeRowsConnector([]string{"name", "result"},
+		[][]*string{{str("mystage/a.csv.gz"), str("removed")}, {str("mystage/b.csv.gz"), str("removed")}}))
+	defer db.Close()
+	names, err := RemoveStageFiles(context.Background(), db, "@mystage", "")
+	if err != nil {
+		t.Fatalf("failed to remove. err: %v", err)
+	}
+	if len(names) != 2 || names[1] != "mystage/b.csv.gz" {
+		t.Fatalf("unexpected names: %v", names)
+	}
+}
+
+func TestStageQuery(t *testing.T) {
+	testcases := []struct {
+		command  string
+		location string
+		pattern  string
+		query    string
+	}{
+		{"LIST", "@mystage/dir/", "", "LIST @mystage/dir/"},
+		{"LIST", "%mytable", "", "LIST @%mytable"},
+		{"REMOVE", "@mystage", `.*\.csv`, `REMOVE @mystage PATTERN = '.*\\.csv'`},
+		{"REMOVE", "@mystage", `it's`, `REMOVE @mystage PATTERN = 'it\'s'`},
+	}
+	for _, test := range testcases {
+		if query := stageQuery(test.command, test.location, test.pattern); query != test.query {
+			t.Errorf("unexpected query: %v, expected: %v", query, test.query)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	azureMetaPrefix         = "x-ms-meta-"
+	azureMetaEncryptionData = azureMetaPrefix + "encryptiondata"
+	azureMetaMatdesc        = azureMetaPrefix + "matdesc"
+	azureMetaDigest         = azureMetaPrefix + "sfcdigest"
+	azureDefaultEndPoint    = "blob.core.windows.net"
+)
+
+// azureClient transfers files to and from Azure Blob Storage with the SAS token of the stage.
+type azureClient struct {
+	info    *execResponseStageInfo
+	client  *http.Client
+	timeout time.Duration
+}
+
+func (c *azureClient) blobURL(fileName string) (*url.URL, error) {
+	return c.blobQueryURL(fileName, "")
+}
+
+// blobQueryURL returns the URL of the file with the query of a block request added to the SAS token.
+func (c *azureClient) blobQueryURL(fileName string, query string) (*url.URL, error) {
+	container, prefix := splitStageLocation(c.info.Location)
+	endPoint := c.info.EndPoint
+	if endPoint == "" {
+		endPoint = azureDefaultEndPoint
+	}
+	host := c.info.StorageAccount + "." + endPoint
+	sasToken := strings.TrimPrefix(c.info.Creds.AzureSasToken, "?")
+	if sasToken != "" && query != "" {
+		query = sasToken + "&" + query
+	} else if query == "" {
+		query = sasToken
+	}
+	return storageURL(c.info.EndPoint, host, container, prefix+fileName, query)
+}
+
+func (c *azureClient) getFileHeader(ctx context.Context, fileName string) (*fileHeader, error) {
+	u, err := c.blobURL(fileName)
+	if err != nil {
+		return nil, err
+	}
+	res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, map[string]string{}, c.timeout).doHead().
+		doRaise4XX(true).execute()
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, storageError(res, "HEAD", u)
+	}
+	res.Body.Close()
+	return azureFileHeader(res)
+}
+
+func azureFileHeader(res *http.Response) (*fileHeader, error) {
+	header := &fileHeader{digest: res.Header.Get(azureMetaDigest), contentLength: res.ContentLength}
+	if data := res.Header.Get(azureMetaEncryptionData); data != "" {
+		var err error
+		if header.encryptionMetadata, err = parseEncryptionData(data, res.Header.Get(azureMetaMatdesc)); err != nil {
+			return nil, err
+		}
+	}
+	return header, nil
+}
+
+func (c *azureClient) uploadFile(ctx context.Context, fileName string, data []byte, header *fileHeader) error {
+	u, err := c.blobURL(fileName)
+	if err != nil {
+		return err
+	}
+	headers, err := azureMetaHeaders(header)
+	if err != nil {
+		return err
+	}
+	headers["x-ms-blob-type"] = "BlockBlob"
+	headers["Content-Type"] = "application/octet-stream"
+	res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, headers, c.timeout).doPut().setBody(data).
+		doRaise4XX(true).execute()
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		return storageError(res, "PUT", u)
+	}
+	res.Body.Close()
+	return nil
+}
+
+func azureMetaHeaders(header *fileHeader) (map[string]string, error) {
+	headers := make(map[string]string)
+	if header.digest != "" {
+		headers[azureMetaDigest] = header.digest
+	}
+	if em := header.encryptionMetadata; em != nil {
+		data, err := newEncryptionData(em)
+		if err != nil {
+			return nil, err
+		}
+		headers[azureMetaEncryptionData] = data
+		headers[azureMetaMatdesc] = em.matdesc
+	}
+	return headers, nil
+}
+
+type azureBlockList struct {
+	XMLName xml.Name `xml:"BlockList"`
+	Latest  []string `xml:"Latest"`
+}
+
+// uploadStream uploads r as the blocks of the blob, committed with the metadata once all are uploaded. Blocks that
+// aren't committed are removed by the storage.
+func (c *azureClient) uploadStream(ctx context.Context, fileName string, r io.Reader, header *fileHeader) error {
+	var blockList azureBlockList
+	err := forEachPart(r, fileStreamPartSize, func(number int, part []byte) error {
+		// block IDs of a blob must have the same length
+		blockID := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%010d", number)))
+		u, err := c.blobQueryURL(fileName, url.Values{"comp": {"block"}, "blockid": {blockID}}.Encode())
+		if err != nil {
+			return err
+		}
+		res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, map[string]string{}, c.timeout).doPut().
+			setBody(part).doRaise4XX(true).execute()
+		if err != nil {
+			return err
+		}
+		if res.StatusCode != http.StatusCreated {
+			return storageError(res, "PUT", u)
+		}
+		res.Body.Close()
+		blockList.Latest = append(blockList.Latest, blockID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	u, err := c.blobQueryURL(fileName, "comp=blocklist")
+	if err != nil {
+		return err
+	}
+	body, err := xml.Marshal(&blockList)
+	if err != nil {
+		return err
+	}
+	headers, err := azureMetaHeaders(header)
+	if err != nil {
+		return err
+	}
+	headers["x-ms-blob-content-type"] = "application/octet-stream"
+	res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, headers, c.timeout).doPut().
+		setBody(append([]byte(xml.Header), body...)).doRaise4XX(true).execute()
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusCreated {
+		return storageError(res, "PUT", u)
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (c *azureClient) downloadStream(ctx context.Context, fileName string) (io.ReadCloser, *fileHeader, error) {
+	u, err := c.blobURL(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, map[string]string{}, c.timeout).doRaise4XX(true).
+		execute()
+	if err != nil {
+		return nil, nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, storageError(res, "GET", u)
+	}
+	header, err := azureFileHeader(res)
+	if err != nil {
+		res.Body.Close()
+		return nil, nil, err
+	}
+	return res.Body, header, nil
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	locationTypeS3      = "S3"
+	locationTypeAzure   = "AZURE"
+	locationTypeGCS     = "GCS"
+	locationTypeLocalFS = "LOCAL_FS"
+)
+
+// execResponseStageInfo is the stage of a file transfer.
+type execResponseStageInfo struct {
+	LocationType          string                  `json:"locationType,omitempty"`
+	Location              string                  `json:"location,omitempty"` // bucket or container, and path
+	Path                  string                  `json:"path,omitempty"`
+	Region                string                  `json:"region,omitempty"`
+	StorageAccount        string                  `json:"storageAccount,omitempty"`
+	IsClientSideEncrypted bool                    `json:"isClientSideEncrypted,omitempty"`
+	Creds                 execResponseCredentials `json:"creds,omitempty"`
+	PresignedURL          string                  `json:"presignedUrl,omitempty"`
+	EndPoint              string                  `json:"endPoint,omitempty"`
+}
+
+// execResponseCredentials is the temporary credentials of the storage of a stage.
+type execResponseCredentials struct {
+	AwsKeyID       string `json:"AWS_KEY_ID,omitempty"`
+	AwsSecretKey   string `json:"AWS_SECRET_KEY,omitempty"`
+	AwsToken       string `json:"AWS_TOKEN,omitempty"`
+	AzureSasToken  string `json:"AZURE_SAS_TOKEN,omitempty"`
+	GcsAccessToken string `json:"GCS_ACCESS_TOKEN,omitempty"`
+}
+
+// fileHeader is the metadata of a file on a stage.
+type fileHeader struct {
+	digest             string // base64 SHA-256 of the file content before encryption
+	contentLength      int64
+	encryptionMetadata *encryptMetadata // nil unless encrypted on the client side
+}
+
+// storageClient transfers files to and from the storage of a stage. The file names are relative to the stage
+// location.
+type storageClient interface {
+	// getFileHeader returns the header of the file, or nil if the file doesn't exist.
+	getFileHeader(ctx context.Context, fileName string) (*fileHeader, error)
+	// uploadFile writes data to the file with the metadata of header.
+	uploadFile(ctx context.Context, fileName string, data []byte, header *fileHeader) error
+	// downloadStream returns the content of the file to read, which the caller closes, and the header of the file.
+	downloadStream(ctx context.Context, fileName string) (io.ReadCloser, *fileHeader, error)
+	// uploadStream writes what r reads to the file with the metadata of header, holding at most a part of
+	// fileStreamPartSize bytes in memory.
+	uploadStream(ctx context.Context, fileName string, r io.Reader, header *fileHeader) error
+}
+
+// fileStreamPartSize is the size of the parts of a stream that uploadStream uploads one by one.
+var fileStreamPartSize = 8 << 20
+
+// forEachPart calls fn with the parts of partSize bytes that r reads, numbered from 1. The last part may be shorter,
+// and is empty only if r reads nothing. The part is reused once fn returns.
+func forEachPart(r io.Reader, partSize int, fn func(number int, part []byte) error) error {
+	buf := make([]byte, partSize)
+	for number := 1; ; number++ {
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF && number > 1 {
+			return nil
+		}
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		if err = fn(number, buf[:n]); err != nil {
+			return err
+		}
+		if n < partSize {
+			return nil
+		}
+	}
+}
+
+// newStorageClient returns the client of the storage of the stage.
+func newStorageClient(sc *snowflakeConn, info *execResponseStageInfo) (storageClient, error) {
+	var client *http.Client
+	var timeout time.Duration
+	if sc != nil && sc.rest != nil {
+		client, timeout = sc.rest.Client, sc.rest.RequestTimeout
+	}
+	if client == nil {
+		client = &http.Client{Transport: SnowflakeTransport}
+	}
+	switch strings.ToUpper(info.LocationType) {
+	case locationTypeS3:
+		return &s3Client{info: info, client: client, timeout: timeout}, nil
+	case locationTypeAzure:
+		return &azureClient{info: info, client: client, timeout: timeout}, nil
+	case locationTypeGCS:
+		return &gcsClient{info: info, client: client, timeout: timeout}, nil
+	case locationTypeLocalFS:
+		return &localClient{info: info}, nil
+	}
+	return nil, &SnowflakeError{
+		Number:      ErrInvalidStageLocation,
+		Message:     errMsgInvalidStageLocation,
+		MessageArgs: []interface{}{info.LocationType},
+	}
+}
+
+// splitStageLocation splits the location of a stage into the bucket or container and the path prefix, which ends
+// with a slash unless empty.
+func splitStageLocation(location string) (string, string) {
+	i := strings.Index(location, "/")
+	if i < 0 {
+		return location, ""
+	}
+	prefix := location[i+1:]
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return location[:i], prefix
+}
+
+// storageURL returns the URL of the object key in the bucket. host is the virtual host of the bucket, unless
+// endPoint has a scheme, e.g., an S3 compatible server, in which case the bucket is in the path. query may be empty.
+func storageURL(endPoint string, host string, bucket string, key string, query string) (*url.URL, error) {
+	base := "https://" + host
+	if strings.Contains(endPoint, "://") {
+		base = strings.TrimSuffix(endPoint, "/") + "/" + bucket
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	u.RawPath = uriEncodePath(u.Path)
+	u.RawQuery = strings.TrimPrefix(query, "?")
+	return u, nil
+}
+
+// uriEncodePath percent-encodes every byte of path but the unreserved characters and slashes, as AWS signature
+// version 4 requires.
+func uriEncodePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '.' || c == '_' || c == '~' || c == '/' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// storageError returns an error of a failed storage request, closing the response.
+func storageError(res *http.Response, method string, u *url.URL) error {
+	defer res.Body.Close()
+	b, _ := ioutil.ReadAll(res.Body)
+	return fmt.Errorf("failed to %v %v. HTTP: %v, body: %v", method, u.Path, res.StatusCode, string(b))
+}
+
+// readStorageResponse returns the body of a successful storage response, closing the response.
+func readStorageResponse(res *http.Response, method string, u *url.URL) ([]byte, error) {
+	if res.StatusCode != http.StatusOK {
+		return nil, storageError(res, method, u)
+	}
+	defer res.Body.Close()
+	return ioutil.ReadAll(res.Body)
+}
+
+// encryptionData is the metadata of client-side encryption of Azure and GCS, in the format of the Azure SDK.
+type encryptionData struct {
+	EncryptionMode      string              `json:"EncryptionMode"`
+	WrappedContentKey   wrappedContentKey   `json:"WrappedContentKey"`
+	EncryptionAgent     encryptionAgent     `json:"EncryptionAgent"`
+	ContentEncryptionIV string              `json:"ContentEncryptionIV"`
+	KeyWrappingMetadata keyWrappingMetadata `json:"KeyWrappingMetadata"`
+}
+
+type wrappedContentKey struct {
+	KeyID        string `json:"KeyId"`
+	EncryptedKey string `json:"EncryptedKey"`
+	Algorithm    string `json:"Algorithm"`
+}
+
+type encryptionAgent struct {
+	Protocol            string `json:"Protocol"`
+	EncryptionAlgorithm string `json:"EncryptionAlgorithm"`
+}
+
+type keyWrappingMetadata struct {
+	EncryptionLibrary string `json:"EncryptionLibrary"`
+}
+
+// newEncryptionData returns the JSON encryption data of meta.
+func newEncryptionData(meta *encryptMetadata) (string, error) {
+	b, err := json.Marshal(encryptionData{
+		EncryptionMode: "FullBlob",
+		WrappedContentKey: wrappedContentKey{
+			KeyID:        "symmKey1",
+			EncryptedKey: meta.key,
+			Algorithm:    "AES_CBC_256",
+		},
+		EncryptionAgent: encryptionAgent{
+			Protocol:            "1.0",
+			EncryptionAlgorithm: "AES_CBC_256",
+		},
+		ContentEncryptionIV: meta.iv,
+		KeyWrappingMetadata: keyWrappingMetadata{EncryptionLibrary: "Java 5.3.0"},
+	})
+	return string(b), err
+}
+
+// parseEncryptionData returns the encryption metadata of the JSON encryption data and material descriptor.
+func parseEncryptionData(data string, matdesc string) (*encryptMetadata, error) {
+	var ed encryptionData
+	if err := json.Unmarshal([]byte(data), &ed); err != nil {
+		return nil, err
+	}
+	return &encryptMetadata{key: ed.WrappedContentKey.EncryptedKey, iv: ed.ContentEncryptionIV, matdesc: matdesc}, nil
+}
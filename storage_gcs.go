@@ -0,0 +1,174 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	gcsMetaPrefix         = "x-goog-meta-"
+	gcsMetaEncryptionData = gcsMetaPrefix + "encryptiondata"
+	gcsMetaMatdesc        = gcsMetaPrefix + "matdesc"
+	gcsMetaDigest         = gcsMetaPrefix + "sfc-digest"
+	gcsDefaultHost        = "storage.googleapis.com"
+)
+
+// gcsClient transfers files to and from Google Cloud Storage with the access token of the stage, or the presigned URL
+// of the file if the server gives one instead.
+type gcsClient struct {
+	info          *execResponseStageInfo
+	client        *http.Client
+	timeout       time.Duration
+	presignedURLs map[string]string // presigned URL of each file to download
+}
+
+func (c *gcsClient) objectURL(fileName string) (*url.URL, error) {
+	if presignedURL, ok := c.presignedURLs[fileName]; ok && presignedURL != "" {
+		return url.Parse(presignedURL)
+	}
+	if c.info.PresignedURL != "" {
+		return url.Parse(c.info.PresignedURL)
+	}
+	bucket, prefix := splitStageLocation(c.info.Location)
+	host := gcsDefaultHost + "/" + bucket
+	if c.info.EndPoint != "" {
+		host = c.info.EndPoint + "/" + bucket
+	}
+	return storageURL(c.info.EndPoint, host, bucket, prefix+fileName, "")
+}
+
+func (c *gcsClient) authHeaders(fileName string) map[string]string {
+	headers := make(map[string]string)
+	if c.info.PresignedURL == "" && c.presignedURLs[fileName] == "" && c.info.Creds.GcsAccessToken != "" {
+		headers["Authorization"] = "Bearer " + c.info.Creds.GcsAccessToken
+	}
+	return headers
+}
+
+func (c *gcsClient) getFileHeader(ctx context.Context, fileName string) (*fileHeader, error) {
+	if c.info.PresignedURL != "" {
+		return nil, nil // the presigned URL is only for the transfer
+	}
+	u, err := c.objectURL(fileName)
+	if err != nil {
+		return nil, err
+	}
+	res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, c.authHeaders(fileName), c.timeout).doHead().
+		doRaise4XX(true).execute()
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, storageError(res, "HEAD", u)
+	}
+	res.Body.Close()
+	return gcsFileHeader(res)
+}
+
+func gcsFileHeader(res *http.Response) (*fileHeader, error) {
+	header := &fileHeader{digest: res.Header.Get(gcsMetaDigest), contentLength: res.ContentLength}
+	if data := res.Header.Get(gcsMetaEncryptionData); data != "" {
+		var err error
+		if header.encryptionMetadata, err = parseEncryptionData(data, res.Header.Get(gcsMetaMatdesc)); err != nil {
+			return nil, err
+		}
+	}
+	return header, nil
+}
+
+func (c *gcsClient) uploadFile(ctx context.Context, fileName string, data []byte, header *fileHeader) error {
+	u, err := c.objectURL(fileName)
+	if err != nil {
+		return err
+	}
+	headers, err := c.uploadHeaders(fileName, header)
+	if err != nil {
+		return err
+	}
+	res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, headers, c.timeout).doPut().setBody(data).
+		doRaise4XX(true).execute()
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return storageError(res, "PUT", u)
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (c *gcsClient) uploadHeaders(fileName string, header *fileHeader) (map[string]string, error) {
+	headers := c.authHeaders(fileName)
+	headers["Content-Type"] = "application/octet-stream"
+	if header.digest != "" {
+		headers[gcsMetaDigest] = header.digest
+	}
+	if em := header.encryptionMetadata; em != nil {
+		data, err := newEncryptionData(em)
+		if err != nil {
+			return nil, err
+		}
+		headers[gcsMetaEncryptionData] = data
+		headers[gcsMetaMatdesc] = em.matdesc
+	}
+	return headers, nil
+}
+
+// uploadStream uploads r in a single request with chunked transfer encoding, which works with presigned URLs too.
+// The request isn't retried, as the stream can't be read again.
+func (c *gcsClient) uploadStream(ctx context.Context, fileName string, r io.Reader, header *fileHeader) error {
+	u, err := c.objectURL(fileName)
+	if err != nil {
+		return err
+	}
+	headers, err := c.uploadHeaders(fileName, header)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", u.String(), r)
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return storageError(res, "PUT", u)
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (c *gcsClient) downloadStream(ctx context.Context, fileName string) (io.ReadCloser, *fileHeader, error) {
+	u, err := c.objectURL(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, c.authHeaders(fileName), c.timeout).
+		doRaise4XX(true).execute()
+	if err != nil {
+		return nil, nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, storageError(res, "GET", u)
+	}
+	header, err := gcsFileHeader(res)
+	if err != nil {
+		res.Body.Close()
+		return nil, nil, err
+	}
+	return res.Body, header, nil
+}
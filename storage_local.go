@@ -0,0 +1,79 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// localClient transfers files to and from a directory of the local file system, the LOCAL_FS stages of test
+// deployments.
+type localClient struct {
+	info *execResponseStageInfo
+}
+
+func (c *localClient) path(fileName string) (string, error) {
+	dir, err := expandUser(c.info.Location)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.FromSlash(fileName)), nil
+}
+
+func (c *localClient) getFileHeader(_ context.Context, fileName string) (*fileHeader, error) {
+	path, err := c.path(fileName)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &fileHeader{contentLength: fi.Size()}, nil
+}
+
+func (c *localClient) uploadFile(_ context.Context, fileName string, data []byte, _ *fileHeader) error {
+	path, err := c.path(fileName)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+func (c *localClient) downloadStream(_ context.Context, fileName string) (io.ReadCloser, *fileHeader, error) {
+	path, err := c.path(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, &fileHeader{contentLength: fi.Size()}, nil
+}
+
+func (c *localClient) uploadStream(_ context.Context, fileName string, r io.Reader, _ *fileHeader) error {
+	path, err := c.path(fileName)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return writeStreamAtomically(path, r)
+}
@@ -0,0 +1,338 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3MetaPrefix     = "x-amz-meta-"
+	s3MetaKey        = s3MetaPrefix + "x-amz-key"
+	s3MetaIV         = s3MetaPrefix + "x-amz-iv"
+	s3MetaMatdesc    = s3MetaPrefix + "x-amz-matdesc"
+	s3MetaDigest     = s3MetaPrefix + "sfc-digest"
+	s3DefaultRegion  = "us-east-1"
+	s3SigningAlgo    = "AWS4-HMAC-SHA256"
+	s3AmzDateFormat  = "20060102T150405Z"
+	s3AmzScopeFormat = "20060102"
+)
+
+// s3Client transfers files to and from S3 with the temporary credentials of the stage, signing the requests with AWS
+// signature version 4.
+type s3Client struct {
+	info    *execResponseStageInfo
+	client  *http.Client
+	timeout time.Duration
+	now     func() time.Time // time of the signatures. time.Now if nil
+}
+
+func (c *s3Client) region() string {
+	if c.info.Region != "" {
+		return c.info.Region
+	}
+	return s3DefaultRegion
+}
+
+func (c *s3Client) objectURL(fileName string) (*url.URL, error) {
+	return c.objectQueryURL(fileName, "")
+}
+
+// objectQueryURL returns the URL of the file with the query of a multipart upload request.
+func (c *s3Client) objectQueryURL(fileName string, query string) (*url.URL, error) {
+	bucket, prefix := splitStageLocation(c.info.Location)
+	host := fmt.Sprintf("%v.s3.%v.amazonaws.com", bucket, c.region())
+	if c.info.EndPoint != "" {
+		host = bucket + "." + c.info.EndPoint
+	}
+	return storageURL(c.info.EndPoint, host, bucket, prefix+fileName, query)
+}
+
+func (c *s3Client) getFileHeader(ctx context.Context, fileName string) (*fileHeader, error) {
+	u, err := c.objectURL(fileName)
+	if err != nil {
+		return nil, err
+	}
+	headers := c.sign("HEAD", u, nil, nil)
+	res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, headers, c.timeout).doHead().doRaise4XX(true).execute()
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, storageError(res, "HEAD", u)
+	}
+	res.Body.Close()
+	return s3FileHeader(res), nil
+}
+
+func s3FileHeader(res *http.Response) *fileHeader {
+	header := &fileHeader{digest: res.Header.Get(s3MetaDigest), contentLength: res.ContentLength}
+	if key := res.Header.Get(s3MetaKey); key != "" {
+		header.encryptionMetadata = &encryptMetadata{
+			key:     key,
+			iv:      res.Header.Get(s3MetaIV),
+			matdesc: res.Header.Get(s3MetaMatdesc),
+		}
+	}
+	return header
+}
+
+func (c *s3Client) uploadFile(ctx context.Context, fileName string, data []byte, header *fileHeader) error {
+	u, err := c.objectURL(fileName)
+	if err != nil {
+		return err
+	}
+	headers := c.sign("PUT", u, s3MetaHeaders(header), data)
+	headers["Content-Type"] = "application/octet-stream"
+	res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, headers, c.timeout).doPut().setBody(data).
+		doRaise4XX(true).execute()
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return storageError(res, "PUT", u)
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (c *s3Client) downloadStream(ctx context.Context, fileName string) (io.ReadCloser, *fileHeader, error) {
+	u, err := c.objectURL(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	headers := c.sign("GET", u, nil, nil)
+	res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, headers, c.timeout).doRaise4XX(true).execute()
+	if err != nil {
+		return nil, nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, storageError(res, "GET", u)
+	}
+	return res.Body, s3FileHeader(res), nil
+}
+
+func s3MetaHeaders(header *fileHeader) map[string]string {
+	meta := make(map[string]string)
+	if header.digest != "" {
+		meta[s3MetaDigest] = header.digest
+	}
+	if em := header.encryptionMetadata; em != nil {
+		meta[s3MetaKey] = em.key
+		meta[s3MetaIV] = em.iv
+		meta[s3MetaMatdesc] = em.matdesc
+	}
+	return meta
+}
+
+type s3InitiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type s3CompleteMultipartUpload struct {
+	XMLName xml.Name               `xml:"CompleteMultipartUpload"`
+	Parts   []s3CompleteUploadPart `xml:"Part"`
+}
+
+type s3CompleteUploadPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// uploadStream uploads r by a multipart upload, which is aborted if any part fails.
+func (c *s3Client) uploadStream(ctx context.Context, fileName string, r io.Reader, header *fileHeader) error {
+	uploadID, err := c.initiateMultipartUpload(ctx, fileName, header)
+	if err != nil {
+		return err
+	}
+	complete := s3CompleteMultipartUpload{}
+	err = forEachPart(r, fileStreamPartSize, func(number int, part []byte) error {
+		u, err := c.objectQueryURL(fileName, url.Values{
+			"partNumber": {strconv.Itoa(number)},
+			"uploadId":   {uploadID},
+		}.Encode())
+		if err != nil {
+			return err
+		}
+		res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, c.sign("PUT", u, nil, part), c.timeout).doPut().
+			setBody(part).doRaise4XX(true).execute()
+		if err != nil {
+			return err
+		}
+		if res.StatusCode != http.StatusOK {
+			return storageError(res, "PUT", u)
+		}
+		res.Body.Close()
+		complete.Parts = append(complete.Parts, s3CompleteUploadPart{PartNumber: number, ETag: res.Header.Get("ETag")})
+		return nil
+	})
+	if err == nil {
+		err = c.completeMultipartUpload(ctx, fileName, uploadID, &complete)
+	}
+	if err != nil {
+		c.abortMultipartUpload(ctx, fileName, uploadID)
+		return err
+	}
+	return nil
+}
+
+func (c *s3Client) initiateMultipartUpload(ctx context.Context, fileName string, header *fileHeader) (string, error) {
+	u, err := c.objectQueryURL(fileName, "uploads")
+	if err != nil {
+		return "", err
+	}
+	headers := c.sign("POST", u, s3MetaHeaders(header), nil)
+	headers["Content-Type"] = "application/octet-stream"
+	res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, headers, c.timeout).doPost().doRaise4XX(true).execute()
+	if err != nil {
+		return "", err
+	}
+	body, err := readStorageResponse(res, "POST", u)
+	if err != nil {
+		return "", err
+	}
+	var result s3InitiateMultipartUploadResult
+	if err = xml.Unmarshal(body, &result); err != nil {
+		return "", err
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("failed to POST %v. no upload ID", u.Path)
+	}
+	return result.UploadID, nil
+}
+
+func (c *s3Client) completeMultipartUpload(ctx context.Context, fileName string, uploadID string,
+	complete *s3CompleteMultipartUpload) error {
+	u, err := c.objectQueryURL(fileName, url.Values{"uploadId": {uploadID}}.Encode())
+	if err != nil {
+		return err
+	}
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		return err
+	}
+	res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, c.sign("POST", u, nil, body), c.timeout).doPost().
+		setBody(body).doRaise4XX(true).execute()
+	if err != nil {
+		return err
+	}
+	// S3 reports a failure to complete in the body of a 200 response
+	body, err = readStorageResponse(res, "POST", u)
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(body, []byte("<Error>")) {
+		return fmt.Errorf("failed to POST %v. body: %v", u.Path, string(body))
+	}
+	return nil
+}
+
+// abortMultipartUpload removes the parts of a failed upload, which otherwise remain on the storage.
+func (c *s3Client) abortMultipartUpload(ctx context.Context, fileName string, uploadID string) {
+	u, err := c.objectQueryURL(fileName, url.Values{"uploadId": {uploadID}}.Encode())
+	if err != nil {
+		return
+	}
+	res, err := newRetryHTTP(ctx, c.client, http.NewRequest, u, c.sign("DELETE", u, nil, nil), c.timeout).
+		doDelete().doRaise4XX(true).execute()
+	if err != nil {
+		logger.WithContext(ctx).Warningf("failed to abort the multipart upload of %v. err: %v", fileName, err)
+		return
+	}
+	res.Body.Close()
+}
+
+// sign returns the headers of a request of the method to u with amzHeaders, x-amz-* headers, and body, including the
+// signature.
+func (c *s3Client) sign(method string, u *url.URL, amzHeaders map[string]string, body []byte) map[string]string {
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	t := now().UTC()
+	payloadHash := sha256.Sum256(body)
+	headers := map[string]string{
+		"host":                 u.Host,
+		"x-amz-date":           t.Format(s3AmzDateFormat),
+		"x-amz-content-sha256": hex.EncodeToString(payloadHash[:]),
+	}
+	if c.info.Creds.AwsToken != "" {
+		headers["x-amz-security-token"] = c.info.Creds.AwsToken
+	}
+	for k, v := range amzHeaders {
+		headers[strings.ToLower(k)] = v
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + strings.TrimSpace(headers[k]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		canonicalQueryString(u.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		headers["x-amz-content-sha256"],
+	}, "\n")
+	scope := strings.Join([]string{t.Format(s3AmzScopeFormat), c.region(), "s3", "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3SigningAlgo, headers["x-amz-date"], scope, hex.EncodeToString(requestHash[:]),
+	}, "\n")
+	key := hmacSHA256([]byte("AWS4"+c.info.Creds.AwsSecretKey), t.Format(s3AmzScopeFormat))
+	for _, s := range []string{c.region(), "s3", "aws4_request"} {
+		key = hmacSHA256(key, s)
+	}
+	headers["Authorization"] = fmt.Sprintf("%v Credential=%v/%v, SignedHeaders=%v, Signature=%v",
+		s3SigningAlgo, c.info.Creds.AwsKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign)))
+	delete(headers, "host") // set by the request
+	return headers
+}
+
+// canonicalQueryString returns the query parameters sorted by name and encoded as signature version 4 requires.
+func canonicalQueryString(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			params = append(params, strings.ReplaceAll(uriEncodePath(k), "/", "%2F")+"="+
+				strings.ReplaceAll(uriEncodePath(v), "/", "%2F"))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// defaultInsertBatchSize is the number of rows InsertStructs binds in one request by default.
+const defaultInsertBatchSize = 10000
+
+// InsertStructsOptions configures InsertStructs.
+type InsertStructsOptions struct {
+	// BatchSize is the maximum number of rows bound in one request. The default is 10000.
+	BatchSize int
+	// TimestampType is the binding parameter flag time.Time fields bind as, i.e., DataTypeTimestampNtz,
+	// DataTypeTimestampLtz or DataTypeTimestampTz. The default is TIMESTAMP_NTZ as other bindings.
+	TimestampType []byte
+}
+
+// SQLExecer executes a statement, e.g., *sql.DB, *sql.Conn or *sql.Tx.
+type SQLExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// columnArray is the values of a column bound as an array. nil values bind NULL.
+type columnArray struct {
+	typ    string
+	values []*string
+}
+
+// InsertStructs inserts rows, a slice of structs or of pointers to structs, into table and returns the number of rows
+// inserted. The fields map to the columns as StructScanner maps them, and each field is bound as an array to a
+// parameter of INSERT INTO table (columns) VALUES (?, ...). Nil pointers insert NULL. Slices larger than the batch size
+// are inserted by several requests, so run it in a transaction to insert all rows or none.
+//
+// The table is written into the statement as is, so it is a name as written in SQL, qualified and quoted as needed,
+// e.g., mydb.public."My Table"; QuoteIdentifier quotes the parts of exact names. The column names of the fields are
+// identifiers as written in SQL too, which are quoted once normalized, e.g., amount and Amount for AMOUNT, and
+// "Amount" for Amount.
+func InsertStructs(ctx context.Context, db SQLExecer, table string, rows interface{}, opts *InsertStructsOptions) (
+	int64, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("rows must be a slice: %T", rows)
+	}
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return 0, fmt.Errorf("rows must be a slice of structs or of pointers to structs: %T", rows)
+	}
+	if table == "" {
+		return 0, fmt.Errorf("table must not be empty")
+	}
+	batchSize := defaultInsertBatchSize
+	tsmode := "TIMESTAMP_NTZ"
+	if opts != nil {
+		if opts.BatchSize > 0 {
+			batchSize = opts.BatchSize
+		}
+		if opts.TimestampType != nil {
+			var err error
+			if tsmode, err = dataTypeMode(opts.TimestampType); err != nil {
+				return 0, err
+			}
+			if !strings.HasPrefix(tsmode, "TIMESTAMP_") {
+				return 0, fmt.Errorf("not a timestamp type: %v", tsmode)
+			}
+		}
+	}
+	names, fields := structInsertColumns(elemType)
+	if len(names) == 0 {
+		return 0, fmt.Errorf("%v has no field to insert", elemType)
+	}
+	query := structInsertQuery(table, names)
+
+	var total int64
+	for start := 0; start < v.Len(); start += batchSize {
+		end := start + batchSize
+		if end > v.Len() {
+			end = v.Len()
+		}
+		args, err := structColumnArrays(v.Slice(start, end), names, fields, tsmode)
+		if err != nil {
+			return total, err
+		}
+		result, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return total, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// structInsertColumns returns the column names of the fields of t in the order of declaration, and the index of each
+// field.
+func structInsertColumns(t reflect.Type) ([]string, [][]int) {
+	byName := make(map[string][]int)
+	collectStructFields(t, nil, byName)
+	fields := make([][]int, 0, len(byName))
+	for _, index := range byName {
+		fields = append(fields, index)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	names := make([]string, len(fields))
+	for i, index := range fields {
+		f := t.FieldByIndex(index)
+		if names[i] = f.Tag.Get(structScanTag); names[i] == "" {
+			names[i] = f.Name
+		}
+	}
+	return names, fields
+}
+
+// structInsertQuery returns the INSERT statement binding a parameter to each column. The table is a name as written
+// in SQL, and the columns are quoted as quoteColumnName does.
+func structInsertQuery(table string, names []string) string {
+	columns := make([]string, len(names))
+	for i, name := range names {
+		columns[i] = quoteColumnName(name)
+	}
+	return fmt.Sprintf("INSERT INTO %v (%v) VALUES (%v)",
+		table, strings.Join(columns, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", "))
+}
+
+// structColumnArrays returns the column arrays of the fields of the structs in rows.
+func structColumnArrays(rows reflect.Value, names []string, fields [][]int, tsmode string) ([]interface{}, error) {
+	args := make([]interface{}, len(fields))
+	for i, index := range fields {
+		arr := &columnArray{values: make([]*string, rows.Len())}
+		for j := 0; j < rows.Len(); j++ {
+			row := rows.Index(j)
+			if row.Kind() == reflect.Ptr {
+				if row.IsNil() {
+					return nil, fmt.Errorf("row %v is nil", j)
+				}
+				row = row.Elem()
+			}
+			value, err := structInsertValue(row, index)
+			if err != nil {
+				return nil, fmt.Errorf("failed to bind column %v: %v", names[i], err)
+			}
+			if value == nil {
+				continue
+			}
+			t := goTypeToSnowflake(value, tsmode)
+			if _, ok := value.([]byte); ok {
+				t = "BINARY"
+			}
+			if arr.typ == "" {
+				arr.typ = t
+			} else if arr.typ != t {
+				return nil, fmt.Errorf("failed to bind column %v: %v in a column of %v", names[i], t, arr.typ)
+			}
+			if f, ok := value.(float64); ok {
+				s := fmt.Sprintf("%g", f) // as arrayToString, in full precision
+				arr.values[j] = &s
+			} else if arr.values[j], err = valueToString(value, t); err != nil {
+				return nil, fmt.Errorf("failed to bind column %v: %v", names[i], err)
+			}
+		}
+		if arr.typ == "" {
+			arr.typ = "TEXT" // all NULL
+		}
+		args[i] = arr
+	}
+	return args, nil
+}
+
+// structInsertValue returns the value to bind of the field of v at index. Nil pointers, including embedded ones on the
+// way, are NULL.
+func structInsertValue(v reflect.Value, index []int) (driver.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil, nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		if _, ok := v.Interface().(driver.Valuer); !ok {
+			v = v.Elem()
+		}
+	}
+	switch fv := v.Interface().(type) {
+	case Date, TimeOfDay:
+		return fv, nil
+	case big.Int:
+		return fv.String(), nil
+	case big.Float:
+		return fv.Text('f', -1), nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v.Interface())
+}
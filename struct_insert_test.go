@@ -0,0 +1,121 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordingExecer records the statements and arguments it executes.
+type recordingExecer struct {
+	queries []string
+	args    [][]interface{}
+}
+
+func (e *recordingExecer) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	e.queries = append(e.queries, query)
+	e.args = append(e.args, args)
+	return driver.RowsAffected(len(args[0].(*columnArray).values)), nil
+}
+
+type structInsertBase struct {
+	ID int64 `snowflake:"ORDER_ID"`
+}
+
+type structInsertRow struct {
+	structInsertBase
+	Amount  *big.Float
+	Ratio   float64
+	Label   *string
+	Day     Date
+	TS      time.Time
+	Data    []byte
+	Ignored string `snowflake:"-"`
+}
+
+func TestInsertStructs(t *testing.T) {
+	label := "a"
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	rows := []*structInsertRow{
+		{structInsertBase{1}, big.NewFloat(1.5), 0.1, &label, Date{2020, time.January, 1}, ts, []byte{0xca, 0xfe}, "x"},
+		{structInsertBase{2}, nil, 2, nil, Date{2020, time.January, 2}, ts, nil, "y"},
+		{structInsertBase{3}, nil, 3, nil, Date{2020, time.January, 3}, ts, nil, "z"},
+	}
+	e := &recordingExecer{}
+	n, err := InsertStructs(context.Background(), e, "ORDERS", rows, &InsertStructsOptions{
+		BatchSize:     2,
+		TimestampType: DataTypeTimestampTz,
+	})
+	if err != nil {
+		t.Fatalf("failed to insert. err: %v", err)
+	}
+	if n != 3 || len(e.queries) != 2 {
+		t.Fatalf("unexpected number of rows or requests: %v, %v", n, len(e.queries))
+	}
+	query := `INSERT INTO ORDERS ("ORDER_ID", "AMOUNT", "RATIO", "LABEL", "DAY", "TS", "DATA") ` +
+		"VALUES (?, ?, ?, ?, ?, ?, ?)"
+	if e.queries[0] != query {
+		t.Fatalf("unexpected query: %v", e.queries[0])
+	}
+	str := func(s string) *string {
+		return &s
+	}
+	expected := []*columnArray{
+		{"FIXED", []*string{str("1"), str("2")}},
+		{"TEXT", []*string{str("1.5"), nil}},
+		{"REAL", []*string{str("0.1"), str("2")}},
+		{"TEXT", []*string{str("a"), nil}},
+		{"DATE", []*string{str("1577836800000"), str("1577923200000")}},
+		{"TIMESTAMP_TZ", []*string{str("1577934245123456789 1440"), str("1577934245123456789 1440")}},
+		{"BINARY", []*string{str("cafe"), nil}},
+	}
+	for i, arg := range e.args[0] {
+		if !reflect.DeepEqual(arg, expected[i]) {
+			t.Errorf("unexpected column %v. expected: %v, got: %v", i, expected[i], arg)
+		}
+	}
+	if a := e.args[1][1].(*columnArray); a.typ != "TEXT" || len(a.values) != 1 || a.values[0] != nil {
+		t.Errorf("unexpected column of NULL: %+v", a)
+	}
+	if typ, arr := arrayToString(e.args[1][2]); typ != "REAL" || *arr[0] != "3" {
+		t.Errorf("unexpected array binding: %v, %v", typ, arr)
+	}
+}
+
+func TestInsertStructsInvalid(t *testing.T) {
+	e := &recordingExecer{}
+	if _, err := InsertStructs(context.Background(), e, "T", []int{1}, nil); err == nil {
+		t.Error("should fail to insert a slice of non-structs")
+	}
+	if _, err := InsertStructs(context.Background(), e, "", []structInsertRow{}, nil); err == nil {
+		t.Error("should fail to insert into no table")
+	}
+	if _, err := InsertStructs(context.Background(), e, "T", []structInsertRow{}, &InsertStructsOptions{
+		TimestampType: DataTypeBinary}); err == nil {
+		t.Error("should fail to bind time.Time as BINARY")
+	}
+	type mixed struct {
+		V interface{}
+	}
+	if _, err := InsertStructs(context.Background(), e, "T", []mixed{{int64(1)}, {"a"}}, nil); err == nil {
+		t.Error("should fail to bind values of different types to a column")
+	}
+	if n, err := InsertStructs(context.Background(), e, "T", []structInsertRow{}, nil); err != nil || n != 0 ||
+		len(e.queries) != 0 {
+		t.Errorf("should insert nothing. n: %v, err: %v", n, err)
+	}
+}
+
+func TestStructInsertQuery(t *testing.T) {
+	query := structInsertQuery(`mydb.public."My Table"`, []string{"id", `"Order Note"`, `"say ""hi"""`, "select"})
+	expected := `INSERT INTO mydb.public."My Table" ("ID", "Order Note", "say ""hi""", "SELECT") VALUES (?, ?, ?, ?)`
+	if query != expected {
+		t.Fatalf("unexpected query: %v, expected: %v", query, expected)
+	}
+}
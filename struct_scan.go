@@ -0,0 +1,311 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// structScanTag is the struct tag naming the column of a field. "-" skips the field.
+const structScanTag = "snowflake"
+
+var (
+	timeStructType     = reflect.TypeOf(time.Time{})
+	bigIntStructType   = reflect.TypeOf(big.Int{})
+	bigFloatStructType = reflect.TypeOf(big.Float{})
+)
+
+// StructScanOptions configures StructScanner.
+type StructScanOptions struct {
+	// Strict makes scanning fail if a column doesn't map to any field of the struct.
+	Strict bool
+}
+
+// StructScanner scans the rows of a result set into structs. A column maps to the field tagged with its name, e.g.,
+// `snowflake:"ORDER_ID"`, or else to the field of the same name, both case-insensitively. Fields of embedded structs
+// are promoted. The values are converted with the column metadata of Snowflake, e.g., NUMBER(38, 2) to float64 or
+// *big.Float, or VARIANT, OBJECT and ARRAY to structs, maps and slices by encoding/json. Fields implementing
+// sql.Scanner, e.g., Date or GeoValue, scan the values themselves.
+type StructScanner struct {
+	rows    *sql.Rows
+	strict  bool
+	columns []string
+	metas   []execResponseRowType
+	values  []interface{}
+	dest    []interface{}
+	typ     reflect.Type // struct type fields is for
+	fields  [][]int      // index of the field of each column. nil if unmapped
+}
+
+// NewStructScanner returns a StructScanner for rows. opts may be nil.
+func NewStructScanner(rows *sql.Rows, opts *StructScanOptions) (*StructScanner, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	s := &StructScanner{
+		rows:    rows,
+		strict:  opts != nil && opts.Strict,
+		columns: make([]string, len(columnTypes)),
+		metas:   make([]execResponseRowType, len(columnTypes)),
+		values:  make([]interface{}, len(columnTypes)),
+		dest:    make([]interface{}, len(columnTypes)),
+	}
+	for i, ct := range columnTypes {
+		s.columns[i] = ct.Name()
+		s.metas[i] = execResponseRowType{Name: ct.Name(), Type: strings.ToLower(ct.DatabaseTypeName())}
+		if s.metas[i].Type == "fixed" {
+			if _, scale, ok := ct.DecimalSize(); ok {
+				s.metas[i].Scale = scale
+			}
+		}
+		s.dest[i] = &s.values[i]
+	}
+	return s, nil
+}
+
+// Scan scans the current row into dest, a pointer to a struct.
+func (s *StructScanner) Scan(dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dest must be a non-nil pointer to a struct: %T", dest)
+	}
+	if err := s.mapFields(v.Elem().Type()); err != nil {
+		return err
+	}
+	if err := s.rows.Scan(s.dest...); err != nil {
+		return err
+	}
+	for i, index := range s.fields {
+		if index == nil {
+			continue
+		}
+		if err := convertColumnValue(&s.metas[i], s.values[i], structField(v.Elem(), index)); err != nil {
+			return fmt.Errorf("failed to scan column %v: %v", s.columns[i], err)
+		}
+	}
+	return nil
+}
+
+// ScanStructs scans all remaining rows into dest, a pointer to a slice of structs or of pointers to structs. opts may
+// be nil.
+func ScanStructs(rows *sql.Rows, dest interface{}, opts *StructScanOptions) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("dest must be a non-nil pointer to a slice: %T", dest)
+	}
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if elemType.Kind() == reflect.Ptr {
+		structType = elemType.Elem()
+	}
+	s, err := NewStructScanner(rows, opts)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		p := reflect.New(structType)
+		if err = s.Scan(p.Interface()); err != nil {
+			return err
+		}
+		if elemType.Kind() == reflect.Ptr {
+			slice.Set(reflect.Append(slice, p))
+		} else {
+			slice.Set(reflect.Append(slice, p.Elem()))
+		}
+	}
+	return rows.Err()
+}
+
+// mapFields maps the columns to the fields of t unless they are already.
+func (s *StructScanner) mapFields(t reflect.Type) error {
+	if s.typ == t {
+		return nil
+	}
+	byName := make(map[string][]int)
+	collectStructFields(t, nil, byName)
+	fields := make([][]int, len(s.columns))
+	for i, name := range s.columns {
+		index, ok := byName[strings.ToLower(name)]
+		if !ok && s.strict {
+			return fmt.Errorf("column %v doesn't map to any field of %v", name, t)
+		}
+		fields[i] = index
+	}
+	s.typ, s.fields = t, fields
+	return nil
+}
+
+// collectStructFields adds the fields of t by lower case column name to byName. The fields of embedded structs come
+// after those of t, so the shallower field of a name wins.
+func collectStructFields(t reflect.Type, parent []int, byName map[string][]int) {
+	var embedded []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get(structScanTag)
+		if tag == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		if tag == "" && f.Anonymous {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				if f.PkgPath != "" {
+					continue // can't be allocated
+				}
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, f)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = f.Name
+		}
+		name = strings.ToLower(name)
+		if _, ok := byName[name]; !ok {
+			byName[name] = append(append([]int(nil), parent...), i)
+		}
+	}
+	for _, f := range embedded {
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		collectStructFields(ft, append(append([]int(nil), parent...), f.Index...), byName)
+	}
+}
+
+// structField returns the field of v at index, allocating nil embedded struct pointers on the way.
+func structField(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// convertColumnValue sets dst to the value src of a column of meta.
+func convertColumnValue(meta *execResponseRowType, src interface{}, dst reflect.Value) error {
+	if scanner, ok := dst.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+	if src == nil {
+		switch dst.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		return fmt.Errorf("cannot convert NULL to %v", dst.Type())
+	}
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return convertColumnValue(meta, src, dst.Elem())
+	}
+
+	switch dst.Type() {
+	case timeStructType:
+		switch v := src.(type) {
+		case time.Time:
+			dst.Set(reflect.ValueOf(v))
+		case Date:
+			dst.Set(reflect.ValueOf(v.In(time.UTC)))
+		case TimeOfDay:
+			dst.Set(reflect.ValueOf(v.On(Date{Year: 1, Month: time.January, Day: 1}, time.UTC)))
+		default:
+			return fmt.Errorf("cannot convert %v to %v", meta.Type, dst.Type())
+		}
+		return nil
+	case bigIntStructType:
+		if _, ok := dst.Addr().Interface().(*big.Int).SetString(exportText(meta, src), 10); !ok {
+			return fmt.Errorf("cannot convert %v to %v", src, dst.Type())
+		}
+		return nil
+	case bigFloatStructType:
+		if f, ok := src.(*big.Float); ok {
+			dst.Addr().Interface().(*big.Float).Set(f)
+			return nil
+		}
+		if _, ok := dst.Addr().Interface().(*big.Float).SetString(exportText(meta, src)); !ok {
+			return fmt.Errorf("cannot convert %v to %v", src, dst.Type())
+		}
+		return nil
+	}
+
+	switch strings.ToUpper(meta.Type) {
+	case "VARIANT", "OBJECT", "ARRAY":
+		switch dst.Kind() {
+		case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Interface:
+			if dst.Type() != reflect.TypeOf([]byte(nil)) && dst.Type() != reflect.TypeOf(json.RawMessage(nil)) {
+				return json.Unmarshal([]byte(exportText(meta, src)), dst.Addr().Interface())
+			}
+		}
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(exportText(meta, src))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(exportText(meta, src))
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(exportText(meta, src), 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(exportText(meta, src), 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(exportText(meta, src), dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(f)
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("cannot convert %v to %v", meta.Type, dst.Type())
+		}
+		switch v := src.(type) {
+		case []byte:
+			dst.SetBytes(append([]byte(nil), v...))
+		case string:
+			dst.SetBytes([]byte(v))
+		default:
+			return fmt.Errorf("cannot convert %v to %v", meta.Type, dst.Type())
+		}
+	default:
+		sv := reflect.ValueOf(src)
+		if !sv.Type().ConvertibleTo(dst.Type()) {
+			return fmt.Errorf("cannot convert %v to %v", meta.Type, dst.Type())
+		}
+		dst.Set(sv.Convert(dst.Type()))
+	}
+	return nil
+}
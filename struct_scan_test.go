@@ -0,0 +1,162 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"database/sql"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newStructScanTestConnector returns a connector answering every query with rows of the types of ScanStructs.
+func newStructScanTestConnector() *fakeConnector {
+	str := func(s string) *string {
+		return &s
+	}
+	rt := []execResponseRowType{
+		{Name: "ID", Type: "fixed", Precision: 38, Scale: 0},
+		{Name: "AMOUNT", Type: "fixed", Precision: 38, Scale: 2, Nullable: true},
+		{Name: "RATIO", Type: "real", Nullable: true},
+		{Name: "NAME", Type: "text", Nullable: true},
+		{Name: "FLAG", Type: "boolean", Nullable: true},
+		{Name: "DAY", Type: "date", Nullable: true},
+		{Name: "TS", Type: "timestamp_ntz", Nullable: true},
+		{Name: "DATA", Type: "binary", Nullable: true},
+		{Name: "DOC", Type: "variant", Nullable: true},
+	}
+	rowSet := [][]*string{
+		{str("1"), str("123456789.12"), str("0.5"), str("a"), str("1"), str("18262"),
+			str("1577934245.123456789"), str("CAFE"), str(`{"k":[1,2]}`)},
+		{str("2"), nil, nil, nil, nil, nil, nil, nil, nil},
+	}
+	return &fakeConnector{handler: func(string, map[string]execBindParameter) (*execResponseData, error) {
+		return newFakeResult(rt, rowSet), nil
+	}}
+}
+
+type structScanBase struct {
+	ID int64
+}
+
+type structScanRow struct {
+	structScanBase
+	Amount *big.Float
+	Ratio  *float64
+	Label  *string `snowflake:"name"`
+	Flag   *bool
+	Day    *Date
+	TS     *time.Time
+	Data   []byte
+	Doc    *struct {
+		K []int `json:"k"`
+	}
+	Ignored string `snowflake:"-"`
+}
+
+func TestScanStructs(t *testing.T) {
+	db := sql.OpenDB(newStructScanTestConnector())
+	defer db.Close()
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatalf("failed to query. err: %v", err)
+	}
+	defer rows.Close()
+	var result []structScanRow
+	if err = ScanStructs(rows, &result, &StructScanOptions{Strict: true}); err != nil {
+		t.Fatalf("failed to scan. err: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("unexpected number of rows: %v", len(result))
+	}
+	r := result[0]
+	if r.ID != 1 || r.Amount.Text('f', 2) != "123456789.12" || *r.Ratio != 0.5 || *r.Label != "a" ||
+		!*r.Flag || *r.Day != (Date{2020, time.January, 1}) ||
+		!r.TS.Equal(time.Date(2020, 1, 2, 3, 4, 5, 123456789, time.UTC)) ||
+		!reflect.DeepEqual(r.Data, []byte{0xca, 0xfe}) || !reflect.DeepEqual(r.Doc.K, []int{1, 2}) {
+		t.Fatalf("unexpected row: %+v", r)
+	}
+	r = result[1]
+	if r.ID != 2 || r.Amount != nil || r.Ratio != nil || r.Label != nil || r.Flag != nil || r.Day != nil ||
+		r.TS != nil || r.Data != nil || r.Doc != nil {
+		t.Fatalf("unexpected row: %+v", r)
+	}
+}
+
+func TestStructScannerStrict(t *testing.T) {
+	type partial struct {
+		ID     int
+		Amount float64
+	}
+	db := sql.OpenDB(newStructScanTestConnector())
+	defer db.Close()
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatalf("failed to query. err: %v", err)
+	}
+	var result []partial
+	if err = ScanStructs(rows, &result, &StructScanOptions{Strict: true}); err == nil {
+		t.Fatal("should fail to scan unmapped columns")
+	}
+	rows.Close()
+
+	rows, err = db.Query("SELECT")
+	if err != nil {
+		t.Fatalf("failed to query. err: %v", err)
+	}
+	defer rows.Close()
+	s, err := NewStructScanner(rows, nil)
+	if err != nil {
+		t.Fatalf("failed to create scanner. err: %v", err)
+	}
+	if !rows.Next() {
+		t.Fatal("no row")
+	}
+	var p partial
+	if err = s.Scan(&p); err != nil {
+		t.Fatalf("failed to scan. err: %v", err)
+	}
+	if p.ID != 1 || p.Amount != 123456789.12 {
+		t.Fatalf("unexpected row: %+v", p)
+	}
+	if !rows.Next() {
+		t.Fatal("no row")
+	}
+	if err = s.Scan(&p); err == nil {
+		t.Fatal("should fail to scan NULL into float64")
+	}
+}
+
+func TestConvertColumnValue(t *testing.T) {
+	var i int8
+	if err := convertColumnValue(&execResponseRowType{Type: "fixed"}, int64(300), reflect.ValueOf(&i).Elem()); err == nil {
+		t.Error("should fail to convert an out of range value")
+	}
+	var n int
+	meta := &execResponseRowType{Type: "fixed", Scale: 2}
+	if err := convertColumnValue(meta, big.NewFloat(1.5), reflect.ValueOf(&n).Elem()); err == nil {
+		t.Error("should fail to convert a fraction to int")
+	}
+	var b big.Int
+	if err := convertColumnValue(&execResponseRowType{Type: "fixed"}, "123456789012345678901234567890",
+		reflect.ValueOf(&b).Elem()); err != nil || b.String() != "123456789012345678901234567890" {
+		t.Errorf("unexpected big.Int: %v, err: %v", b.String(), err)
+	}
+	var v interface{}
+	if err := convertColumnValue(&execResponseRowType{Type: "object"}, `{"a":1}`, reflect.ValueOf(&v).Elem()); err != nil ||
+		!reflect.DeepEqual(v, map[string]interface{}{"a": float64(1)}) {
+		t.Errorf("unexpected object: %v, err: %v", v, err)
+	}
+	var s string
+	if err := convertColumnValue(&execResponseRowType{Type: "object"}, `{"a":1}`, reflect.ValueOf(&s).Elem()); err != nil ||
+		s != `{"a":1}` {
+		t.Errorf("unexpected string: %v, err: %v", s, err)
+	}
+	var tm time.Time
+	if err := convertColumnValue(&execResponseRowType{Type: "date"}, Date{2020, time.May, 1},
+		reflect.ValueOf(&tm).Elem()); err != nil || !tm.Equal(time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected time: %v, err: %v", tm, err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"database/sql/driver"
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
@@ -19,6 +20,34 @@ func WithRequestID(ctx context.Context, requestID string) context.Context {
 	return context.WithValue(ctx, SnowflakeRequestIDKey, requestID)
 }
 
+// SnowflakeGetDecompressKey is optional context key to decompress the gzip files that GET downloads
+const SnowflakeGetDecompressKey contextKey = "SNOWFLAKE_GET_DECOMPRESS"
+
+// WithGetDecompress returns a new context with which GET decompresses the downloaded gzip files, removing the .gz
+// extension from the names
+func WithGetDecompress(ctx context.Context) context.Context {
+	return context.WithValue(ctx, SnowflakeGetDecompressKey, true)
+}
+
+// SnowflakeFileStreamKey is optional context key to upload a stream with PUT
+const SnowflakeFileStreamKey contextKey = "SNOWFLAKE_FILE_STREAM"
+
+// WithFileStream returns a new context with which PUT uploads what reader reads instead of the local files, as the
+// file of the name in the PUT command
+func WithFileStream(ctx context.Context, reader io.Reader) context.Context {
+	return context.WithValue(ctx, SnowflakeFileStreamKey, reader)
+}
+
+// SnowflakeDeferChunkDownloadsKey is optional context key to defer the chunk downloads of a query
+const SnowflakeDeferChunkDownloadsKey contextKey = "SNOWFLAKE_DEFER_CHUNK_DOWNLOADS"
+
+// WithDeferredChunkDownloads returns a new context with which the rows of a query start downloading the chunks at the
+// first call to Next, SeekRow or SkipRows rather than as the query returns, so that the rows seeking first don't
+// download the chunks they skip
+func WithDeferredChunkDownloads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, SnowflakeDeferChunkDownloadsKey, true)
+}
+
 // Get the request ID from the context if specified, otherwise generate one
 func getOrGenerateRequestIDFromContext(ctx context.Context) string {
 	requestID, ok := ctx.Value(SnowflakeRequestIDKey).(string)
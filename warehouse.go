@@ -0,0 +1,172 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// States of a warehouse of SHOW WAREHOUSES.
+const (
+	WarehouseStateStarted   = "STARTED"
+	WarehouseStateSuspended = "SUSPENDED"
+	WarehouseStateResizing  = "RESIZING"
+)
+
+// Sizes of a warehouse.
+const (
+	WarehouseSizeXSmall  = "X-Small"
+	WarehouseSizeSmall   = "Small"
+	WarehouseSizeMedium  = "Medium"
+	WarehouseSizeLarge   = "Large"
+	WarehouseSizeXLarge  = "X-Large"
+	WarehouseSize2XLarge = "2X-Large"
+	WarehouseSize3XLarge = "3X-Large"
+	WarehouseSize4XLarge = "4X-Large"
+)
+
+// warehousePollInterval is the interval of SHOW WAREHOUSES of WaitWarehouse.
+var warehousePollInterval = time.Second
+
+// WarehouseInfo is a warehouse of SHOW WAREHOUSES.
+type WarehouseInfo struct {
+	Name            string
+	State           string // one of the WarehouseState constants
+	Type            string // STANDARD
+	Size            string // one of the WarehouseSize constants
+	MinClusterCount int64
+	MaxClusterCount int64
+	StartedClusters int64
+	Running         int64 // SQL statements running
+	Queued          int64 // SQL statements queued
+	IsDefault       bool
+	IsCurrent       bool
+	AutoSuspend     int64 // seconds of inactivity, or 0 if never suspended automatically
+	AutoResume      bool
+	CreatedOn       time.Time
+	ResumedOn       time.Time
+	UpdatedOn       time.Time
+	Owner           string
+	Comment         string
+	ResourceMonitor string
+	ScalingPolicy   string
+}
+
+// ListWarehouses lists the warehouses the role has privileges on whose names match the case-insensitive LIKE
+// pattern, or all if the pattern is empty.
+func ListWarehouses(ctx context.Context, db SQLQueryer, like string) ([]WarehouseInfo, error) {
+	var warehouses []WarehouseInfo
+	err := queryShow(ctx, db, showQuery("WAREHOUSES", "", &ShowOptions{Like: like}), func(r *showRow) {
+		warehouses = append(warehouses, WarehouseInfo{
+			Name:            r.text("name"),
+			State:           r.text("state"),
+			Type:            r.text("type"),
+			Size:            r.text("size"),
+			MinClusterCount: r.int("min_cluster_count"),
+			MaxClusterCount: r.int("max_cluster_count"),
+			StartedClusters: r.int("started_clusters"),
+			Running:         r.int("running"),
+			Queued:          r.int("queued"),
+			IsDefault:       r.bool("is_default"),
+			IsCurrent:       r.bool("is_current"),
+			AutoSuspend:     r.int("auto_suspend"),
+			AutoResume:      r.bool("auto_resume"),
+			CreatedOn:       r.time("created_on"),
+			ResumedOn:       r.time("resumed_on"),
+			UpdatedOn:       r.time("updated_on"),
+			Owner:           r.text("owner"),
+			Comment:         r.text("comment"),
+			ResourceMonitor: r.text("resource_monitor"),
+			ScalingPolicy:   r.text("scaling_policy"),
+		})
+	})
+	return warehouses, err
+}
+
+// GetWarehouse returns the warehouse of the exact name. The error is a *SnowflakeError with ErrWarehouseNotFound if
+// the warehouse doesn't exist or the role has no privilege on it.
+func GetWarehouse(ctx context.Context, db SQLQueryer, name string) (*WarehouseInfo, error) {
+	warehouses, err := ListWarehouses(ctx, db, name)
+	if err != nil {
+		return nil, err
+	}
+	for i := range warehouses {
+		if warehouses[i].Name == name {
+			return &warehouses[i], nil
+		}
+	}
+	return nil, &SnowflakeError{
+		Number:      ErrWarehouseNotFound,
+		Message:     errMsgWarehouseNotFound,
+		MessageArgs: []interface{}{name},
+	}
+}
+
+// ResumeWarehouse resumes the warehouse of the exact name unless it is started. The warehouse may not be ready
+// until it reaches WarehouseStateStarted, which WaitWarehouse waits for. The error is a *SnowflakeError with
+// ErrInsufficientPrivileges if the role has no OPERATE privilege on the warehouse, or with
+// ErrObjectNotExistOrNotAuthorized if it doesn't exist or the role has no privilege on it.
+func ResumeWarehouse(ctx context.Context, db SQLQueryer, name string) error {
+	return alterWarehouse(ctx, db, name, "RESUME IF SUSPENDED")
+}
+
+// SuspendWarehouse suspends the warehouse of the exact name unless it is suspended. The errors are those of
+// ResumeWarehouse.
+func SuspendWarehouse(ctx context.Context, db SQLQueryer, name string) error {
+	err := alterWarehouse(ctx, db, name, "SUSPEND")
+	if err == nil {
+		return nil
+	}
+	// SUSPEND fails if the warehouse is suspended already
+	wh, whErr := GetWarehouse(ctx, db, name)
+	if whErr == nil && strings.EqualFold(wh.State, WarehouseStateSuspended) {
+		return nil
+	}
+	return err
+}
+
+// ResizeWarehouse sets the size of the warehouse of the exact name, e.g., WarehouseSizeLarge. A started warehouse
+// is in WarehouseStateResizing until the new clusters are provisioned. The errors are those of ResumeWarehouse,
+// with ErrInsufficientPrivileges if the role has no MODIFY privilege on the warehouse.
+func ResizeWarehouse(ctx context.Context, db SQLQueryer, name string, size string) error {
+	return alterWarehouse(ctx, db, name, "SET WAREHOUSE_SIZE = "+quoteStringLiteral(size))
+}
+
+// WaitWarehouse waits until the warehouse of the exact name reaches the state, e.g., WarehouseStateStarted, polling
+// SHOW WAREHOUSES, and returns the warehouse. onChange, if not nil, is called with the warehouse whenever its state
+// changes, starting with the state first seen. The error is ctx.Err() if the context is done first.
+func WaitWarehouse(ctx context.Context, db SQLQueryer, name string, state string, onChange func(*WarehouseInfo)) (
+	*WarehouseInfo, error) {
+	var lastState string
+	for {
+		wh, err := GetWarehouse(ctx, db, name)
+		if err != nil {
+			return nil, err
+		}
+		if onChange != nil && wh.State != lastState {
+			onChange(wh)
+		}
+		lastState = wh.State
+		if strings.EqualFold(wh.State, state) {
+			return wh, nil
+		}
+		timer := time.NewTimer(warehousePollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// alterWarehouse runs ALTER WAREHOUSE on the warehouse of the exact name with the action.
+func alterWarehouse(ctx context.Context, db SQLQueryer, name string, action string) error {
+	rows, err := db.QueryContext(ctx, "ALTER WAREHOUSE "+QuoteIdentifier(name)+" "+action)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2020 Snowflake Computing Inc. All right reserved.
+
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// warehouseTestServer responds to SHOW WAREHOUSES and ALTER WAREHOUSE of a warehouse, which takes a SHOW to start
+// after RESUME.
+type warehouseTestServer struct {
+	mu      sync.Mutex
+	name    string
+	states  []string // states of the next SHOW WAREHOUSES
+	state   string
+	size    string
+	queries []string
+	err     error // of ALTER WAREHOUSE
+}
+
+// respond answers a query of a fakeConnector.
+func (s *warehouseTestServer) respond(query string, _ map[string]execBindParameter) (*execResponseData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries = append(s.queries, query)
+	str := func(s string) *string {
+		return &s
+	}
+	if strings.HasPrefix(query, "ALTER WAREHOUSE ") {
+		if s.err != nil {
+			return nil, s.err
+		}
+		switch {
+		case strings.HasSuffix(query, " RESUME IF SUSPENDED"):
+			s.states = []string{"SUSPENDED", WarehouseStateStarted}
+		case strings.HasSuffix(query, " SUSPEND"):
+			s.state = WarehouseStateSuspended
+		}
+		return newFakeTextResult([]string{"status"}, [][]*string{{str("Statement executed successfully.")}}), nil
+	}
+	if len(s.states) > 0 {
+		s.state, s.states = s.states[0], s.states[1:]
+	}
+	columns := []string{"name", "state", "type", "size", "min_cluster_count", "max_cluster_count",
+		"started_clusters", "running", "queued", "is_default", "is_current", "auto_suspend", "auto_resume",
+		"created_on", "owner", "comment", "resource_monitor", "scaling_policy"}
+	return newFakeTextResult(columns, [][]*string{
+		{str(s.name + "_2"), str("STARTED"), str("STANDARD"), str("Small"), str("1"), str("1"), str("1"), str("0"),
+			str("0"), str("N"), str("N"), nil, str("true"), str("2020-06-01T10:00:00-07:00"), str("SYSADMIN"),
+			str(""), str("null"), str("STANDARD")},
+		{str(s.name), str(s.state), str("STANDARD"), str(s.size), str("1"), str("2"), str("0"), str("0"), str("3"),
+			str("Y"), str("Y"), str("600"), str("false"), str("2020-06-01T10:00:00-07:00"), str("SYSADMIN"),
+			str("batch"), str("MONITOR1"), str("ECONOMY")},
+	}), nil
+}
+
+func TestWarehouseLifecycle(t *testing.T) {
+	origInterval := warehousePollInterval
+	warehousePollInterval = time.Millisecond
+	defer func() {
+		warehousePollInterval = origInterval
+	}()
+	server := &warehouseTestServer{name: "BATCH_WH", state: WarehouseStateSuspended, size: WarehouseSizeXSmall}
+	db := sql.OpenDB(&fakeConnector{handler: server.respond})
+	defer db.Close()
+	ctx := context.Background()
+
+	wh, err := GetWarehouse(ctx, db, "BATCH_WH")
+	if err != nil {
+		t.Fatalf("failed to get the warehouse. err: %v", err)
+	}
+	if wh.Name != "BATCH_WH" || wh.State != WarehouseStateSuspended || wh.Size != WarehouseSizeXSmall ||
+		wh.MaxClusterCount != 2 || wh.Queued != 3 || !wh.IsDefault || wh.AutoSuspend != 600 || wh.AutoResume ||
+		wh.Comment != "batch" || wh.ResourceMonitor != "MONITOR1" || wh.ScalingPolicy != "ECONOMY" ||
+		!wh.CreatedOn.Equal(time.Date(2020, 6, 1, 17, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected warehouse: %+v", wh)
+	}
+
+	if err = ResumeWarehouse(ctx, db, "BATCH_WH"); err != nil {
+		t.Fatalf("failed to resume. err: %v", err)
+	}
+	var states []string
+	wh, err = WaitWarehouse(ctx, db, "BATCH_WH", WarehouseStateStarted, func(wh *WarehouseInfo) {
+		states = append(states, wh.State)
+	})
+	if err != nil {
+		t.Fatalf("failed to wait. err: %v", err)
+	}
+	if wh.State != WarehouseStateStarted || strings.Join(states, ",") != "SUSPENDED,STARTED" {
+		t.Fatalf("unexpected state: %v, states: %v", wh.State, states)
+	}
+
+	if err = ResizeWarehouse(ctx, db, "BATCH_WH", WarehouseSizeLarge); err != nil {
+		t.Fatalf("failed to resize. err: %v", err)
+	}
+	if err = SuspendWarehouse(ctx, db, "BATCH_WH"); err != nil {
+		t.Fatalf("failed to suspend. err: %v", err)
+	}
+	expected := []string{
+		`ALTER WAREHOUSE "BATCH_WH" RESUME IF SUSPENDED`,
+		`ALTER WAREHOUSE "BATCH_WH" SET WAREHOUSE_SIZE = 'Large'`,
+		`ALTER WAREHOUSE "BATCH_WH" SUSPEND`,
+	}
+	var alters []string
+	for _, query := range server.queries {
+		if strings.HasPrefix(query, "ALTER ") {
+			alters = append(alters, query)
+		} else if query != "SHOW WAREHOUSES LIKE 'BATCH_WH'" {
+			t.Fatalf("unexpected query: %v", query)
+		}
+	}
+	if strings.Join(alters, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected queries: %q", alters)
+	}
+
+	// suspended already
+	server.err = &SnowflakeError{Number: 90064, Message: "Invalid state. Warehouse 'BATCH_WH' cannot be suspended."}
+	if err = SuspendWarehouse(ctx, db, "BATCH_WH"); err != nil {
+		t.Fatalf("failed to suspend. err: %v", err)
+	}
+}
+
+func TestWarehouseErrors(t *testing.T) {
+	server := &warehouseTestServer{name: "BATCH_WH", state: WarehouseStateSuspended, size: WarehouseSizeXSmall}
+	db := sql.OpenDB(&fakeConnector{handler: server.respond})
+	defer db.Close()
+	ctx := context.Background()
+
+	_, err := GetWarehouse(ctx, db, "OTHER_WH")
+	if driverErr, ok := err.(*SnowflakeError); !ok || driverErr.Number != ErrWarehouseNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	server.err = &SnowflakeError{Number: ErrInsufficientPrivileges, SQLState: "42501",
+		Message: "SQL access control error: Insufficient privileges to operate on warehouse 'BATCH_WH'"}
+	err = ResumeWarehouse(ctx, db, "BATCH_WH")
+	if driverErr, ok := err.(*SnowflakeError); !ok || driverErr.Number != ErrInsufficientPrivileges {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server.state = WarehouseStateStarted
+	err = SuspendWarehouse(ctx, db, "BATCH_WH")
+	if driverErr, ok := err.(*SnowflakeError); !ok || driverErr.Number != ErrInsufficientPrivileges {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err = WaitWarehouse(ctx, db, "BATCH_WH", WarehouseStateSuspended, nil); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}